@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// negative number argument (such as `-3d` date or `-5` quantity). no flag has digit shorthand.
+var negativeArgPattern = regexp.MustCompile(`^-[0-9]`)
+
+// negativeArgs moves positional arguments after `--` when some of them are negative numbers,
+// so cobra does not read them as shorthand flags. arguments are returned as they are
+// when there is no negative number argument or `--` is already given.
+func negativeArgs(root *cobra.Command, args []string) []string {
+	cmd, rest, err := root.Find(args)
+
+	if err != nil || cmd == root {
+		return args
+	}
+
+	var flags, positionals []string
+	negative := false
+
+	for i := 0; i < len(rest); i++ {
+		arg := rest[i]
+
+		switch {
+		case arg == "--":
+			return args
+		case negativeArgPattern.MatchString(arg):
+			positionals = append(positionals, arg)
+			negative = true
+		case len(arg) > 1 && arg[0] == '-':
+			flags = append(flags, arg)
+
+			// value of flag is next argument (even if it is negative number)
+			if flagTakesNextArg(cmd, arg) && i+1 < len(rest) {
+				i++
+				flags = append(flags, rest[i])
+			}
+		default:
+			positionals = append(positionals, arg)
+		}
+	}
+
+	if !negative {
+		return args
+	}
+
+	path := strings.Fields(cmd.CommandPath())[1:]
+
+	return append(append(append(path, flags...), "--"), positionals...)
+}
+
+// flag (`--name` or `-abc` shorthands) requires value and it is not given in the same argument
+func flagTakesNextArg(cmd *cobra.Command, arg string) bool {
+	takesValue := func(flag *pflag.Flag) bool {
+		return flag != nil && flag.NoOptDefVal == ""
+	}
+
+	if strings.HasPrefix(arg, "--") {
+		if strings.Contains(arg, "=") {
+			return false
+		}
+
+		name := arg[2:]
+		flag := cmd.Flags().Lookup(name)
+
+		if flag == nil {
+			flag = cmd.InheritedFlags().Lookup(name)
+		}
+
+		return takesValue(flag)
+	}
+
+	shorthands := arg[1:]
+
+	for i := 0; i < len(shorthands); i++ {
+		flag := cmd.Flags().ShorthandLookup(shorthands[i : i+1])
+
+		if flag == nil {
+			flag = cmd.InheritedFlags().ShorthandLookup(shorthands[i : i+1])
+		}
+
+		if takesValue(flag) {
+			// value follows shorthand in the same argument (such as `-ufoo`)
+			return i == len(shorthands)-1
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/goark/gocli/rwi"
+)
+
+func TestNegativeArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"no negative", []string{"pixel", "get", "graphid", "today"}, []string{"pixel", "get", "graphid", "today"}},
+		{"negative date", []string{"pixel", "get", "graphid", "-3d", "--tz", "UTC"}, []string{"pixel", "get", "--tz", "UTC", "--", "graphid", "-3d"}},
+		{"negative quantity", []string{"pixel", "create", "-n", "graphid", "today", "-5"}, []string{"pixel", "create", "-n", "--", "graphid", "today", "-5"}},
+		{"flag value", []string{"graph", "export", "graphid", "--from", "-3d"}, []string{"graph", "export", "graphid", "--from", "-3d"}},
+		{"flag value and argument", []string{"pixel", "create", "-u", "foo", "graphid", "-1d", "-5"}, []string{"pixel", "create", "-u", "foo", "--", "graphid", "-1d", "-5"}},
+		{"already terminated", []string{"exec", "build", "--", "sh", "-1"}, []string{"exec", "build", "--", "sh", "-1"}},
+		{"unknown command", []string{"foo", "-1"}, []string{"foo", "-1"}},
+	}
+
+	app := NewApp(rwi.New())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := negativeArgs(app.Command(), tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("want %v, but %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+// key of profile name in backup profile
+const backupProfileName = "name"
+
+func (a *App) newBackupCmd() *cobra.Command {
+	backupCmd := &cobra.Command{
+		Use:   "backup",
+		Short: "backup all graphs, pixels and webhooks",
+		Long: `backup all graph definitions, pixels (with optionalData), webhooks and profile (without token) into archive file. Usage:
+
+$ pixela backup <file> [--gzip]
+
+archive is written to stdout when file is "-". archive is compressed by gzip when --gzip is given or file name ends with .gz.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 1 {
+				return usageErrorf("argument error: `backup` requires 1 argument give %d arguments", len(args))
+			}
+
+			compress, _ := cmd.Flags().GetBool("gzip")
+			compress = compress || strings.HasSuffix(args[0], ".gz")
+
+			// do request
+			client, err := a.newClient()
+
+			if err != nil {
+				return err
+			}
+
+			backup, err := client.CreateBackup()
+
+			if err != nil {
+				return errors.Wrap(err, "request error")
+			}
+
+			if backup.Profile, err = a.backupProfile(); err != nil {
+				return err
+			}
+
+			if args[0] == "-" {
+				return pixela.WriteBackup(a.ui.Writer(), backup, compress)
+			}
+
+			if err := writeFileAtomically(args[0], func(w io.Writer) error {
+				return pixela.WriteBackup(w, backup, compress)
+			}); err != nil {
+				return err
+			}
+
+			pixels := 0
+
+			for _, graph := range backup.Graphs {
+				pixels += len(graph.Pixels)
+			}
+
+			a.printStatus(fmt.Sprintf("%d graphs, %d pixels and %d webhooks are saved to %s", len(backup.Graphs), pixels, len(backup.Webhooks), args[0]))
+
+			return nil
+		},
+	}
+
+	backupCmd.Flags().Bool("gzip", false, "compress archive by gzip")
+
+	return backupCmd
+}
+
+func (a *App) newRestoreCmd() *cobra.Command {
+	restoreCmd := &cobra.Command{
+		Use:   "restore",
+		Short: "restore graphs, pixels and webhooks from backup",
+		Long: `restore graphs, pixels and webhooks from archive written by "backup". Usage:
+
+$ pixela restore <file> [--graph-id-map old=new,...] [--mode skip-existing/overwrite/add] [--restore-profile]
+
+archive is read from stdin when file is "-".
+they are restored into user of current settings, which may differ from user in backup.
+--create-user creates the user before restore (requires --agree-terms-of-service and --not-minor).
+--restore-profile saves profile in backup with current username and token into config file.
+
+existing graphs are kept and pixels in backup are merged by --mode. webhooks are created with new hashes.
+what could not be restored as it is (such as webhook hashes) is reported.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 1 {
+				return usageErrorf("argument error: `restore` requires 1 argument give %d arguments", len(args))
+			}
+
+			modeStr, _ := cmd.Flags().GetString("mode")
+			mode, err := pixela.ParseImportMode(modeStr)
+
+			if err != nil {
+				return wrapUsageError(err, "argument error")
+			}
+
+			graphIDMap, _ := cmd.Flags().GetStringToString("graph-id-map")
+			createUser, _ := cmd.Flags().GetBool("create-user")
+			agree, _ := cmd.Flags().GetBool("agree-terms-of-service")
+			notMinor, _ := cmd.Flags().GetBool("not-minor")
+			restoreProfile, _ := cmd.Flags().GetBool("restore-profile")
+
+			if createUser && (!agree || !notMinor) {
+				return usageErrorf("argument error: --create-user requires --agree-terms-of-service and --not-minor (see %s)", termsOfServiceURL)
+			}
+
+			backup, err := a.readBackup(args[0])
+
+			if err != nil {
+				return err
+			}
+
+			if restoreProfile && backup.Profile[backupProfileName] == "" {
+				return usageErrorf("argument error: backup has no profile")
+			}
+
+			// do request
+			client, err := a.newClient()
+
+			if err != nil {
+				return err
+			}
+
+			if createUser {
+				if _, err := client.CreateUserWithRequest(pixela.CreateUserRequest{AgreeTermsOfService: true, NotMinor: true}); err != nil {
+					return errors.Wrap(err, "request error")
+				}
+			}
+
+			report, err := client.Restore(backup, pixela.RestoreOptions{GraphIDMap: graphIDMap, Mode: mode})
+
+			if err != nil {
+				return errors.Wrap(err, "request error")
+			}
+
+			a.clearCompletionCache()
+
+			if restoreProfile {
+				if err := a.restoreProfile(backup.Profile, client.Username, client.Token); err != nil {
+					return err
+				}
+			}
+
+			if err := a.printOutput(report); err != nil {
+				return err
+			}
+
+			if failures := report.Failures(); failures != 0 {
+				return fmt.Errorf("restore error: %d items are not restored", failures)
+			}
+
+			return nil
+		},
+	}
+
+	restoreCmd.Flags().StringToString("graph-id-map", nil, "graph ID mapping (old=new)")
+	restoreCmd.Flags().String("mode", pixela.ImportSkipExisting.String(), "behavior for existing pixels (skip-existing/overwrite/add)")
+	restoreCmd.Flags().Bool("create-user", false, "create user before restore")
+	restoreCmd.Flags().Bool("agree-terms-of-service", false, "agree terms of service (with --create-user)")
+	restoreCmd.Flags().Bool("not-minor", false, "usage is not minor (with --create-user)")
+	restoreCmd.Flags().Bool("restore-profile", false, "save profile in backup into config file")
+
+	restoreCmd.RegisterFlagCompletionFunc("mode", completeValues(stringValues(pixela.ImportModes())...))
+
+	return restoreCmd
+}
+
+// active profile settings without token
+func (a *App) backupProfile() (map[string]string, error) {
+	name := a.activeProfile()
+
+	if name == "" {
+		return nil, nil
+	}
+
+	config, err := a.loadConfigFile()
+
+	if err != nil {
+		return nil, err
+	}
+
+	settings := config.settings(name, false)
+
+	if settings == nil {
+		return nil, nil
+	}
+
+	profile := map[string]string{backupProfileName: name}
+
+	for i := 0; i+1 < len(settings.Content); i += 2 {
+		key, value := settings.Content[i], settings.Content[i+1]
+
+		if key.Value == "token" || value.Kind != yaml.ScalarNode {
+			continue
+		}
+
+		profile[key.Value] = value.Value
+	}
+
+	return profile, nil
+}
+
+// save profile in backup with username and token of restored user
+func (a *App) restoreProfile(profile map[string]string, username, token string) error {
+	name := profile[backupProfileName]
+	config, err := a.loadConfigFile()
+
+	if err != nil {
+		return err
+	}
+
+	for key, value := range profile {
+		if key != backupProfileName {
+			config.set(name, key, value)
+		}
+	}
+
+	config.set(name, "username", username)
+	config.set(name, "token", token)
+
+	if err := config.save(); err != nil {
+		return err
+	}
+
+	a.ui.OutputErrln(fmt.Sprintf("profile `%s` is saved to %s", name, config.path))
+
+	return nil
+}
+
+func (a *App) readBackup(file string) (*pixela.Backup, error) {
+	var r io.Reader = a.ui.Reader()
+
+	if file != "-" {
+		f, err := os.Open(file)
+
+		if err != nil {
+			return nil, wrapUsageError(err, "input error")
+		}
+
+		defer f.Close()
+
+		r = f
+	}
+
+	backup, err := pixela.ReadBackup(r)
+
+	if err != nil {
+		return nil, wrapUsageError(err, "input error")
+	}
+
+	return backup, nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+func TestApp_Backup(t *testing.T) {
+	app, _, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/graphs"):
+			return newTestResponse(http.StatusOK, `{"graphs":[{"id":"graphid","name":"graph","unit":"commits","type":"int","color":"shibafu"}]}`), nil
+		case strings.HasSuffix(req.URL.Path, "/stats"):
+			return newTestResponse(http.StatusOK, `{"totalPixelsCount":1}`), nil
+		case strings.HasSuffix(req.URL.Path, "/pixels"):
+			if from := req.URL.Query().Get("from"); from > "20190101" {
+				return newTestResponse(http.StatusOK, `{"pixels":[]}`), nil
+			}
+
+			return newTestResponse(http.StatusOK, `{"pixels":[{"date":"20190101","quantity":"1"}]}`), nil
+		case strings.HasSuffix(req.URL.Path, "/webhooks"):
+			return newTestResponse(http.StatusOK, `{"webhooks":[]}`), nil
+		}
+
+		t.Fatalf("unexpected request: %s %s", req.Method, req.URL)
+
+		return nil, nil
+	})
+
+	config := "current-profile: personal\nprofiles:\n  personal:\n    username: testuser\n    token: testtoken\n    tz: Asia/Tokyo\n"
+
+	// home directory is cached by homedir, so config file is given by flag
+	configFile := filepath.Join(t.TempDir(), ".pixela.yaml")
+
+	if err := os.WriteFile(configFile, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "backup.json.gz")
+
+	if got := app.Execute([]string{"backup", file, "--config", configFile}); got != ExitNormal {
+		t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+	}
+
+	f, err := os.Open(file)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer f.Close()
+
+	backup, err := pixela.ReadBackup(f)
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	// token is not saved
+	wantProfile := map[string]string{"name": "personal", "username": "testuser", "tz": "Asia/Tokyo"}
+
+	if !reflect.DeepEqual(backup.Profile, wantProfile) {
+		t.Fatalf("want %v, but %v", wantProfile, backup.Profile)
+	}
+
+	if len(backup.Graphs) != 1 || len(backup.Graphs[0].Pixels) != 1 {
+		t.Fatalf("unexpected backup: %#v", backup)
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+// create pixe.la client with username and token in settings
+func (a *App) newClient() (*pixela.Pixela, error) {
+	for _, key := range []string{"username", "token"} {
+		if a.viper.GetString(key) == "" {
+			return nil, usageErrorf("config error: %s is not set (use --%s, PIXELA_%s, profile or config file)", key, key, strings.ToUpper(key))
+		}
+	}
+
+	return a.newClientWithAuth(a.viper.GetString("username"), a.viper.GetString("token"))
+}
+
+// create pixe.la client with given username and token
+func (a *App) newClientWithAuth(username, token string) (*pixela.Pixela, error) {
+	opts, err := a.clientOptions()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return a.clientFactory(username, token, a.viper.GetBool("verbose"), opts...)
+}
+
+// client options from settings
+func (a *App) clientOptions() ([]pixela.Option, error) {
+	var opts []pixela.Option
+
+	// validation rules
+	rules, err := a.validationRules()
+
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append(opts, pixela.OptionValidationRules(rules))
+
+	// validation mode
+	mode, err := a.validationMode()
+
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append(opts, pixela.OptionValidationMode(mode))
+	// optionalData schemas (graph id to JSON Schema file path)
+	for graphID, schemaFile := range a.viper.GetStringMapString("schemas") {
+		schema, err := pixela.LoadOptionalDataSchema(schemaFile)
+
+		if err != nil {
+			return nil, wrapUsageError(err, "config error")
+		}
+
+		opts = append(opts, pixela.OptionOptionalDataSchema(graphID, schema))
+	}
+
+	opts = append(opts, pixela.OptionLocale(a.viper.GetString("locale")))
+
+	// quantity type is checked in strict mode unless it is set
+	if a.viper.IsSet("check-quantity-type") {
+		opts = append(opts, pixela.OptionQuantityTypeCheck(a.viper.GetBool("check-quantity-type")))
+	}
+
+	return opts, nil
+}
+
+// validation rules from settings (built-in rules by default)
+func (a *App) validationRules() (pixela.ValidationRules, error) {
+	rulesFile := a.viper.GetString("validation-rules")
+
+	if rulesFile == "" {
+		return pixela.DefaultValidationRules(), nil
+	}
+
+	rules, err := pixela.LoadValidationRules(rulesFile)
+
+	if err != nil {
+		return rules, wrapUsageError(err, "config error")
+	}
+
+	return rules, nil
+}
+
+// validation mode from settings
+func (a *App) validationMode() (pixela.ValidationMode, error) {
+	if a.viper.GetBool("skip-validation") {
+		return pixela.ValidationOff, nil
+	}
+
+	mode, err := pixela.ParseValidationMode(a.viper.GetString("validation-mode"))
+
+	if err != nil {
+		return mode, wrapUsageError(err, "config error")
+	}
+
+	return mode, nil
+}
+
+// create pixe.la client with username and token of the profile in config file
+func (a *App) newProfileClient(profile string) (*pixela.Pixela, error) {
+	config, err := a.loadConfigFile()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !config.hasProfile(profile) {
+		return nil, usageErrorf("config error: profile `%s` is not found in %s", profile, config.path)
+	}
+
+	username, token := config.get(profile, "username"), config.get(profile, "token")
+
+	if username == "" || token == "" {
+		return nil, usageErrorf("config error: profile `%s` has no username or token", profile)
+	}
+
+	return a.newClientWithAuth(username, token)
+}
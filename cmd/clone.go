@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+func (a *App) newGraphCloneCmd() *cobra.Command {
+	graphCloneCmd := &cobra.Command{
+		Use:   "clone",
+		Short: "clone graph with all pixels",
+		Long: `clone graph definition (name, unit, type, color, timezone and selfSufficient) and all pixels. Usage:
+
+$ pixela graph clone <source graph id> <destination graph id> [--to-profile profile]
+    [--name name] [--unit unit] [--type int/float] [--color color] [--timezone timezone]
+    [--scale factor] [--concurrency n] [--keep-on-error]
+
+--to-profile clones graph into user of the profile in config file.
+--scale multiplies quantities (such as 60 or 1/60). quantities are rounded for int graph.
+destination graph is deleted when copy fails midway unless --keep-on-error is given.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 2 {
+				return usageErrorf("argument error: `graph clone` requires 2 arguments give %d arguments", len(args))
+			}
+
+			var opts pixela.CloneOptions
+			var err error
+
+			opts.Definition.Name, _ = cmd.Flags().GetString("name")
+			opts.Definition.Unit, _ = cmd.Flags().GetString("unit")
+			numType, _ := cmd.Flags().GetString("type")
+			color, _ := cmd.Flags().GetString("color")
+			opts.Definition.Type, opts.Definition.Color = pixela.NumType(numType), pixela.Color(color)
+			opts.Definition.Timezone, _ = cmd.Flags().GetString("timezone")
+			opts.Concurrency, _ = cmd.Flags().GetInt("concurrency")
+			opts.KeepOnError, _ = cmd.Flags().GetBool("keep-on-error")
+
+			if opts.Concurrency < 1 {
+				return usageErrorf("argument error: --concurrency must be positive")
+			}
+
+			if err := a.checkTimezone(opts.Definition.Timezone); err != nil {
+				return err
+			}
+
+			// do request
+			client, err := a.newClient()
+
+			if err != nil {
+				return err
+			}
+
+			if profile, _ := cmd.Flags().GetString("to-profile"); profile != "" {
+				if opts.Target, err = a.newProfileClient(profile); err != nil {
+					return err
+				}
+			}
+
+			if scale, _ := cmd.Flags().GetString("scale"); scale != "" {
+				// converted quantity is formatted for destination graph type
+				if opts.Definition.Type == "" {
+					src, err := client.LookupGraph(args[0])
+
+					if err != nil {
+						return errors.Wrap(err, "request error")
+					}
+
+					numType = src.Type
+				}
+
+				if opts.Convert, err = pixela.ScaleQuantity(scale, pixela.NumType(numType)); err != nil {
+					return wrapUsageError(err, "argument error")
+				}
+			}
+
+			result, err := client.CloneGraph(args[0], args[1], opts)
+
+			if err != nil {
+				return errors.Wrap(err, "request error")
+			}
+
+			a.clearCompletionCache()
+
+			target := client
+
+			if opts.Target != nil {
+				target = opts.Target
+			}
+
+			a.printStatus(fmt.Sprintf("graph `%s` is cloned into `%s` with %d pixels: %s", args[0], args[1], result.Pixels, target.GetGraphDetailURL(args[1])))
+
+			return a.printResult(result)
+		},
+	}
+
+	graphCloneCmd.Flags().String("to-profile", "", "clone into user of the profile")
+	graphCloneCmd.Flags().String("name", "", "destination graph name")
+	graphCloneCmd.Flags().String("unit", "", "destination graph unit")
+	graphCloneCmd.Flags().String("type", "", "destination graph type (int/float)")
+	graphCloneCmd.Flags().String("color", "", "destination graph color (shibafu/momiji/sora/ichou/ajisai/kuro)")
+	graphCloneCmd.Flags().String("timezone", "", "destination graph timezone")
+	graphCloneCmd.Flags().String("scale", "", "multiply quantities by factor (such as 60 or 1/60)")
+	graphCloneCmd.Flags().Int("concurrency", 1, "number of parallel requests")
+	graphCloneCmd.Flags().Bool("keep-on-error", false, "keep destination graph when copy fails")
+
+	graphCloneCmd.RegisterFlagCompletionFunc("to-profile", a.completeProfiles)
+	graphCloneCmd.RegisterFlagCompletionFunc("type", completeValues(stringValues(pixela.NumTypes())...))
+	graphCloneCmd.RegisterFlagCompletionFunc("color", completeValues(stringValues(pixela.Colors())...))
+	graphCloneCmd.RegisterFlagCompletionFunc("timezone", completeTimezones)
+
+	return graphCloneCmd
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+// completionCacheTTL is lifetime of graph and webhook definitions cached for shell completion
+const completionCacheTTL = 5 * time.Minute
+
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+func (a *App) newCompletionCmd() *cobra.Command {
+	completionCmd := &cobra.Command{
+		Use:   "completion",
+		Short: "generate shell completion script (bash, zsh and fish)",
+		Long: `generate shell completion script. Usage:
+
+$ pixela completion <bash|zsh|fish>
+
+bash:
+  $ source <(pixela completion bash)
+zsh:
+  $ pixela completion zsh > "${fpath[1]}/_pixela"
+fish:
+  $ pixela completion fish > ~/.config/fish/completions/pixela.fish
+
+graph IDs and webhook hashes are completed from pixe.la and cached for a few minutes.`,
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 1 {
+				return usageErrorf("argument error: `completion` requires 1 argument give %d arguments", len(args))
+			}
+
+			root := cmd.Root()
+			w := a.ui.Writer()
+
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(w, true)
+			case "zsh":
+				return root.GenZshCompletion(w)
+			case "fish":
+				return root.GenFishCompletion(w, true)
+			}
+
+			return usageErrorf("argument error: unknown shell `%s`: allows bash, zsh and fish", args[0])
+		},
+	}
+
+	return completionCmd
+}
+
+// complete each positional argument by position. nil completes nothing.
+func completePositional(funcs ...completionFunc) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= len(funcs) || funcs[len(args)] == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return funcs[len(args)](cmd, args, toComplete)
+	}
+}
+
+func completeValues(values ...string) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterCompletions(values, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func completeTimezones(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names, err := pixela.TimezoneNames()
+
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// graph IDs annotated with graph name
+func (a *App) completeGraphIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cache, err := a.loadCompletionCache(true, false)
+
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	completions := make([]string, 0, len(cache.Graphs))
+
+	for _, graph := range cache.Graphs {
+		completions = append(completions, fmt.Sprintf("%s\t%s", graph.ID, graph.Name))
+	}
+
+	return filterCompletions(completions, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// webhook hashes annotated with graph ID and type
+func (a *App) completeWebhookHashes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cache, err := a.loadCompletionCache(false, true)
+
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	completions := make([]string, 0, len(cache.Webhooks))
+
+	for _, webhook := range cache.Webhooks {
+		completions = append(completions, fmt.Sprintf("%s\t%s (%s)", webhook.WebhookHash, webhook.GraphID, webhook.Type))
+	}
+
+	return filterCompletions(completions, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func filterCompletions(completions []string, toComplete string) []string {
+	var filtered []string
+
+	for _, c := range completions {
+		if strings.HasPrefix(c, toComplete) {
+			filtered = append(filtered, c)
+		}
+	}
+
+	return filtered
+}
+
+func stringValues[T fmt.Stringer](values []T) []string {
+	s := make([]string, 0, len(values))
+
+	for _, v := range values {
+		s = append(s, v.String())
+	}
+
+	return s
+}
+
+// completionCache is graph and webhook definitions cached for shell completion
+type completionCache struct {
+	Graphs            []pixela.Graph   `json:"graphs"`
+	GraphsFetchedAt   time.Time        `json:"graphsFetchedAt"`
+	Webhooks          []pixela.Webhook `json:"webhooks"`
+	WebhooksFetchedAt time.Time        `json:"webhooksFetchedAt"`
+}
+
+// cache file is per user (under user cache directory).
+// username is hashed, so any username (such as containing path separator) is safe file name.
+func (a *App) completionCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "pixela", a.userFileName("completion")), nil
+}
+
+// load cache and refresh expired graphs and/or webhooks
+func (a *App) loadCompletionCache(graphs, webhooks bool) (completionCache, error) {
+	var cache completionCache
+
+	// completion does not run PersistentPreRunE
+	if err := a.initConfig(); err != nil {
+		return cache, err
+	}
+
+	path, err := a.completionCachePath()
+
+	if err != nil {
+		return cache, err
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		// broken cache is refreshed
+		_ = json.Unmarshal(data, &cache)
+	}
+
+	refreshGraphs := graphs && time.Since(cache.GraphsFetchedAt) > completionCacheTTL
+	refreshWebhooks := webhooks && time.Since(cache.WebhooksFetchedAt) > completionCacheTTL
+
+	if !refreshGraphs && !refreshWebhooks {
+		return cache, nil
+	}
+
+	client, err := a.newClient()
+
+	if err != nil {
+		return cache, err
+	}
+
+	if refreshGraphs {
+		definitions, err := client.GetGraphDefinition()
+
+		if err != nil {
+			return cache, err
+		}
+
+		cache.Graphs, cache.GraphsFetchedAt = definitions.Graphs, time.Now()
+	}
+
+	if refreshWebhooks {
+		definitions, err := client.GetWebhookDefinitions()
+
+		if err != nil {
+			return cache, err
+		}
+
+		cache.Webhooks, cache.WebhooksFetchedAt = definitions.Webhooks, time.Now()
+	}
+
+	// completion works without cache
+	_ = saveState(path, cache)
+
+	return cache, nil
+}
+
+// clear cache after graphs or webhooks are changed
+func (a *App) clearCompletionCache() {
+	if path, err := a.completionCachePath(); err == nil {
+		os.Remove(path)
+	}
+}
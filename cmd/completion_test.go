@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApp_CompletionCache(t *testing.T) {
+	auth := []string{"--username", "testuser", "--token", "testtoken"}
+	requests := map[string]int{}
+
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		requests[req.URL.Path]++
+
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/graphs"):
+			return newTestResponse(http.StatusOK, `{"graphs":[{"id":"graphid","name":"graph name","type":"int"}]}`), nil
+		case strings.HasSuffix(req.URL.Path, "/webhooks"):
+			return newTestResponse(http.StatusOK, `{"webhooks":[{"webhookHash":"hash","graphID":"graphid","type":"increment"}]}`), nil
+		}
+
+		t.Fatalf("unexpected request: %s %s", req.Method, req.URL)
+		return nil, nil
+	})
+
+	complete := func(args ...string) string {
+		t.Helper()
+		out.Reset()
+		errOut.Reset()
+
+		// last argument is completed
+		if got := app.Execute(append(append([]string{"__complete"}, auth...), args...)); got != ExitNormal {
+			t.Fatalf("%v: want %d, but %d: %s", args, ExitNormal, got, errOut.String())
+		}
+
+		return out.String()
+	}
+
+	// graphs are fetched once and completed from cache
+	for i := 0; i < 2; i++ {
+		if got := complete("graph", "update", ""); !strings.Contains(got, "graphid\tgraph name\n") {
+			t.Fatalf("unexpected completions: %q", got)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if got := complete("webhook", "invoke", ""); !strings.Contains(got, "hash\tgraphid (increment)\n") {
+			t.Fatalf("unexpected completions: %q", got)
+		}
+	}
+
+	if requests["/v1/users/testuser/graphs"] != 1 || requests["/v1/users/testuser/webhooks"] != 1 {
+		t.Fatalf("want 1 request each, but %v", requests)
+	}
+
+	// username is not used as file name as it is
+	files, err := filepath.Glob(filepath.Join(os.Getenv("XDG_CACHE_HOME"), "pixela", "completion-*.json"))
+
+	if err != nil || len(files) != 1 || strings.Contains(filepath.Base(files[0]), "testuser") {
+		t.Fatalf("unexpected cache files: %v (%v)", files, err)
+	}
+
+	// cache is cleared after graph is changed
+	app.clearCompletionCache()
+
+	complete("graph", "update", "")
+
+	if requests["/v1/users/testuser/graphs"] != 2 {
+		t.Fatalf("want 2 requests, but %v", requests)
+	}
+}
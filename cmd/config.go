@@ -0,0 +1,548 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// annotation of commands which run without selected profile
+const annotationSkipProfileCheck = "skipProfileCheck"
+
+// config file keys of profiles
+const (
+	configCurrentProfile = "current-profile"
+	configProfiles       = "profiles"
+)
+
+// configProfile is element of `config list` output
+type configProfile struct {
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Current  bool   `json:"current"`
+}
+
+// configProfileList is response for `config list` subcommand
+type configProfileList struct {
+	Profiles []configProfile `json:"profiles"`
+}
+
+func (a *App) newConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:         "config",
+		Short:       "handle config subcommands (list, get, set, use and delete-profile)",
+		Annotations: map[string]string{annotationSkipProfileCheck: "true"},
+		Long: `list, get, set, use and delete-profile of named profiles in config file.
+
+config file has profiles of username and token (and other settings):
+
+current-profile: personal
+profiles:
+  personal:
+    username: foo
+    token: xxxxxxxx
+  team:
+    username: bar
+    token: yyyyyyyy
+
+profile is selected by --profile flag, PIXELA_PROFILE environment variable or current-profile in this order.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.Help()
+			return nil
+		},
+	}
+
+	configCmd.AddCommand(a.newConfigListCmd())
+	configCmd.AddCommand(a.newConfigGetCmd())
+	configCmd.AddCommand(a.newConfigSetCmd())
+	configCmd.AddCommand(a.newConfigUseCmd())
+	configCmd.AddCommand(a.newConfigDeleteProfileCmd())
+
+	return configCmd
+}
+
+func (a *App) newConfigListCmd() *cobra.Command {
+	configListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "list profiles",
+		Long: `list profiles in config file. Usage:
+
+$ pixela config list`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 0 {
+				return usageErrorf("argument error: `config list` requires 0 arguments give %d arguments", len(args))
+			}
+
+			config, err := a.loadConfigFile()
+
+			if err != nil {
+				return err
+			}
+
+			current := config.currentProfile()
+			list := configProfileList{Profiles: []configProfile{}}
+
+			for _, name := range config.profileNames() {
+				list.Profiles = append(list.Profiles, configProfile{
+					Name:     name,
+					Username: config.get(name, "username"),
+					Current:  name == current,
+				})
+			}
+
+			return a.printOutput(list)
+		},
+	}
+
+	return configListCmd
+}
+
+func (a *App) newConfigGetCmd() *cobra.Command {
+	configGetCmd := &cobra.Command{
+		Use:   "get",
+		Short: "get setting value",
+		Long: `get setting value of the profile (or top level when no profile is selected). Usage:
+
+$ pixela config get <key> [--profile name]`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 1 {
+				return usageErrorf("argument error: `config get` requires 1 argument give %d arguments", len(args))
+			}
+
+			config, err := a.loadConfigFile()
+
+			if err != nil {
+				return err
+			}
+
+			profile := a.selectedProfile(config)
+
+			if profile != "" && !config.hasProfile(profile) {
+				return usageErrorf("config error: profile `%s` is not found", profile)
+			}
+
+			return a.printOutput(config.get(profile, args[0]))
+		},
+	}
+
+	return configGetCmd
+}
+
+func (a *App) newConfigSetCmd() *cobra.Command {
+	configSetCmd := &cobra.Command{
+		Use:   "set",
+		Short: "set setting value",
+		Long: `set setting value of the profile (or top level when no profile is selected). Usage:
+
+$ pixela config set <key> <value> [--profile name]
+
+the profile is created when it does not exist.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 2 {
+				return usageErrorf("argument error: `config set` requires 2 arguments give %d arguments", len(args))
+			}
+
+			if args[0] == configCurrentProfile || args[0] == configProfiles {
+				return usageErrorf("argument error: `%s` can not be set (use `config use`)", args[0])
+			}
+
+			config, err := a.loadConfigFile()
+
+			if err != nil {
+				return err
+			}
+
+			config.set(a.selectedProfile(config), args[0], args[1])
+
+			return config.save()
+		},
+	}
+
+	return configSetCmd
+}
+
+func (a *App) newConfigUseCmd() *cobra.Command {
+	configUseCmd := &cobra.Command{
+		Use:   "use",
+		Short: "select current profile",
+		Long: `select current profile. Usage:
+
+$ pixela config use <profile>`,
+		ValidArgsFunction: completePositional(a.completeProfiles),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 1 {
+				return usageErrorf("argument error: `config use` requires 1 argument give %d arguments", len(args))
+			}
+
+			config, err := a.loadConfigFile()
+
+			if err != nil {
+				return err
+			}
+
+			if !config.hasProfile(args[0]) {
+				return usageErrorf("config error: profile `%s` is not found", args[0])
+			}
+
+			config.set("", configCurrentProfile, args[0])
+
+			return config.save()
+		},
+	}
+
+	return configUseCmd
+}
+
+func (a *App) newConfigDeleteProfileCmd() *cobra.Command {
+	configDeleteProfileCmd := &cobra.Command{
+		Use:   "delete-profile",
+		Short: "delete profile",
+		Long: `delete profile (current-profile is cleared when it is deleted). Usage:
+
+$ pixela config delete-profile <profile>`,
+		ValidArgsFunction: completePositional(a.completeProfiles),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 1 {
+				return usageErrorf("argument error: `config delete-profile` requires 1 argument give %d arguments", len(args))
+			}
+
+			config, err := a.loadConfigFile()
+
+			if err != nil {
+				return err
+			}
+
+			if !config.deleteProfile(args[0]) {
+				return usageErrorf("config error: profile `%s` is not found", args[0])
+			}
+
+			return config.save()
+		},
+	}
+
+	return configDeleteProfileCmd
+}
+
+func (a *App) completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	config, err := a.loadConfigFile()
+
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return filterCompletions(config.profileNames(), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// profile selected by `--profile` flag, PIXELA_PROFILE or current-profile
+func (a *App) selectedProfile(config *configFile) string {
+	if profile := a.viper.GetString("profile"); profile != "" {
+		return profile
+	}
+
+	return config.currentProfile()
+}
+
+// profile selected by `--profile` flag, PIXELA_PROFILE or current-profile in loaded settings
+func (a *App) activeProfile() string {
+	if profile := a.viper.GetString("profile"); profile != "" {
+		return profile
+	}
+
+	return a.viper.GetString(configCurrentProfile)
+}
+
+// apply settings of selected profile over top level settings of config file
+func (a *App) applyProfile() error {
+	profile := a.activeProfile()
+
+	if profile == "" || !a.viper.IsSet(configProfiles+"."+profile) {
+		return nil
+	}
+
+	return a.viper.MergeConfigMap(a.viper.GetStringMap(configProfiles + "." + profile))
+}
+
+// selected profile must exist (except for `config` subcommands creating it)
+func (a *App) checkProfile(cmd *cobra.Command) error {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[annotationSkipProfileCheck] == "true" {
+			return nil
+		}
+	}
+
+	if profile := a.activeProfile(); profile != "" && !a.viper.IsSet(configProfiles+"."+profile) {
+		return usageErrorf("config error: profile `%s` is not found", profile)
+	}
+
+	return nil
+}
+
+// configFile is YAML config file edited by `config` subcommands.
+// YAML node is kept as it is to preserve comments and key order.
+type configFile struct {
+	path string
+	root *yaml.Node
+}
+
+// path of config file (`--config` or $HOME/.pixela.yaml)
+func (a *App) configFilePath() (string, error) {
+	if a.cfgFile != "" {
+		return a.cfgFile, nil
+	}
+
+	home, err := homedir.Dir()
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".pixela.yaml"), nil
+}
+
+// load config file. missing file is treated as empty.
+func (a *App) loadConfigFile() (*configFile, error) {
+	path, err := a.configFilePath()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+		return nil, usageErrorf("config error: `config` subcommands support only YAML config file: %s", path)
+	}
+
+	config := &configFile{path: path, root: &yaml.Node{Kind: yaml.MappingNode}}
+	data, err := os.ReadFile(path)
+
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "config error")
+	}
+
+	var doc yaml.Node
+
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, wrapUsageError(err, "config error")
+	}
+
+	if len(doc.Content) != 0 {
+		if doc.Content[0].Kind != yaml.MappingNode {
+			return nil, usageErrorf("config error: config file is not mapping: %s", path)
+		}
+
+		config.root = doc.Content[0]
+	}
+
+	return config, nil
+}
+
+// save config file atomically (temporary file and rename) with permission 0600
+func (c *configFile) save() error {
+	buf := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(c.root); err != nil {
+		return errors.Wrap(err, "config error")
+	}
+
+	dir := filepath.Dir(c.path)
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrap(err, "config error")
+	}
+
+	err := writeFileAtomically(c.path, func(w io.Writer) error {
+		_, err := w.Write(buf.Bytes())
+		return err
+	})
+
+	return errors.Wrap(err, "config error")
+}
+
+// write file by temporary file and rename not to leave broken file
+func writeFileAtomically(path string, write func(io.Writer) error) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(f.Name())
+
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+func (c *configFile) currentProfile() string {
+	if node := mappingValue(c.root, configCurrentProfile); node != nil {
+		return node.Value
+	}
+
+	return ""
+}
+
+func (c *configFile) profiles() *yaml.Node {
+	node := mappingValue(c.root, configProfiles)
+
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	return node
+}
+
+func (c *configFile) profileNames() []string {
+	var names []string
+
+	if profiles := c.profiles(); profiles != nil {
+		for i := 0; i < len(profiles.Content); i += 2 {
+			names = append(names, profiles.Content[i].Value)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (c *configFile) hasProfile(name string) bool {
+	profiles := c.profiles()
+
+	return profiles != nil && mappingValue(profiles, name) != nil
+}
+
+// settings of the profile (or top level settings when profile is empty)
+func (c *configFile) settings(profile string, create bool) *yaml.Node {
+	if profile == "" {
+		return c.root
+	}
+
+	profiles := c.profiles()
+
+	if profiles == nil {
+		if !create {
+			return nil
+		}
+
+		profiles = &yaml.Node{Kind: yaml.MappingNode}
+		setMappingValue(c.root, configProfiles, profiles)
+	}
+
+	settings := mappingValue(profiles, profile)
+
+	if settings == nil || settings.Kind != yaml.MappingNode {
+		if !create {
+			return nil
+		}
+
+		settings = &yaml.Node{Kind: yaml.MappingNode}
+		setMappingValue(profiles, profile, settings)
+	}
+
+	return settings
+}
+
+func (c *configFile) get(profile, key string) string {
+	settings := c.settings(profile, false)
+
+	if settings == nil {
+		return ""
+	}
+
+	if node := mappingValue(settings, key); node != nil {
+		return node.Value
+	}
+
+	return ""
+}
+
+func (c *configFile) set(profile, key, value string) {
+	setMappingValue(c.settings(profile, true), key, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value})
+}
+
+func (c *configFile) deleteProfile(name string) bool {
+	profiles := c.profiles()
+
+	if profiles == nil || !deleteMappingKey(profiles, name) {
+		return false
+	}
+
+	if c.currentProfile() == name {
+		deleteMappingKey(c.root, configCurrentProfile)
+	}
+
+	return true
+}
+
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if strings.EqualFold(mapping.Content[i].Value, key) {
+			return mapping.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if strings.EqualFold(mapping.Content[i].Value, key) {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
+
+// delete key and its value. head comment of the key (such as comment at the top of file) is moved to next key.
+func deleteMappingKey(mapping *yaml.Node, key string) bool {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if strings.EqualFold(mapping.Content[i].Value, key) {
+			if comment := mapping.Content[i].HeadComment; comment != "" && i+2 < len(mapping.Content) {
+				next := mapping.Content[i+2]
+				next.HeadComment = strings.TrimSpace(comment + "\n" + next.HeadComment)
+			}
+
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return true
+		}
+	}
+
+	return false
+}
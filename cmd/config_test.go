@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goark/gocli/exitcode"
+)
+
+func TestApp_Config(t *testing.T) {
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		t.Fatalf("unexpected request: %s %s", req.Method, req.URL)
+		return nil, nil
+	})
+
+	configFile := filepath.Join(t.TempDir(), ".pixela.yaml")
+	config := "# my settings\ncurrent-profile: personal\nprofiles:\n  personal:\n    username: foo\n    token: xxxxxxxx\n"
+
+	if err := os.WriteFile(configFile, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(want exitcode.ExitCode, args ...string) string {
+		t.Helper()
+		out.Reset()
+		errOut.Reset()
+
+		if got := app.Execute(append(args, "--config", configFile)); got != want {
+			t.Fatalf("%v: want %d, but %d: %s", args, want, got, errOut.String())
+		}
+
+		return out.String()
+	}
+
+	if got, want := run(ExitNormal, "config", "list", "-o", "json"), `{"profiles":[{"name":"personal","username":"foo","current":true}]}`+"\n"; got != want {
+		t.Fatalf("want %q, but %q", want, got)
+	}
+
+	// profile is created by set
+	run(ExitNormal, "config", "set", "username", "bar", "--profile", "team")
+	run(ExitNormal, "config", "set", "tz", "Asia/Tokyo", "--profile", "team")
+
+	if got := run(ExitNormal, "config", "get", "tz", "--profile", "team"); got != "Asia/Tokyo\n" {
+		t.Fatalf("want Asia/Tokyo, but %q", got)
+	}
+
+	// current profile is used without --profile
+	if got := run(ExitNormal, "config", "get", "username"); got != "foo\n" {
+		t.Fatalf("want foo, but %q", got)
+	}
+
+	run(ExitNormal, "config", "use", "team")
+
+	if got := run(ExitNormal, "config", "get", "username"); got != "bar\n" {
+		t.Fatalf("want bar, but %q", got)
+	}
+
+	if got, want := run(ExitNormal, "config", "list", "-o", "csv"), "name,username,current\npersonal,foo,false\nteam,bar,true\n"; got != want {
+		t.Fatalf("want %q, but %q", want, got)
+	}
+
+	// current-profile is cleared when it is deleted
+	run(ExitNormal, "config", "delete-profile", "team")
+
+	if got, want := run(ExitNormal, "config", "list", "-o", "csv"), "name,username,current\npersonal,foo,false\n"; got != want {
+		t.Fatalf("want %q, but %q", want, got)
+	}
+
+	data, err := os.ReadFile(configFile)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// comment and other profile are kept
+	for _, want := range []string{"# my settings", "token: xxxxxxxx"} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("config file does not contain %q:\n%s", want, data)
+		}
+	}
+
+	if info, err := os.Stat(configFile); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("want permission 0600, but %v (%v)", info.Mode().Perm(), err)
+	}
+
+	// errors
+	run(ExitUsage, "config", "get", "username", "--profile", "missing")
+	run(ExitUsage, "config", "use", "missing")
+	run(ExitUsage, "config", "delete-profile", "missing")
+	run(ExitUsage, "config", "set", "current-profile", "personal")
+	run(ExitUsage, "config", "set", "username")
+}
+
+func TestApp_ConfigNotYAML(t *testing.T) {
+	app, _, _ := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		t.Fatalf("unexpected request: %s %s", req.Method, req.URL)
+		return nil, nil
+	})
+
+	configFile := filepath.Join(t.TempDir(), "pixela.json")
+
+	if err := os.WriteFile(configFile, []byte(`{"username":"foo"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := app.Execute([]string{"config", "list", "--config", configFile}); got != ExitUsage {
+		t.Fatalf("want %d, but %d", ExitUsage, got)
+	}
+}
+
+func TestApp_ProfileNotFound(t *testing.T) {
+	app, _, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		t.Fatalf("unexpected request: %s %s", req.Method, req.URL)
+		return nil, nil
+	})
+
+	configFile := filepath.Join(t.TempDir(), ".pixela.yaml")
+
+	if err := os.WriteFile(configFile, []byte("profiles:\n  personal:\n    username: foo\n    token: xxxxxxxx\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := app.Execute([]string{"graph", "get", "--config", configFile, "--profile", "missing"}); got != ExitUsage {
+		t.Fatalf("want %d, but %d", ExitUsage, got)
+	}
+
+	if !strings.Contains(errOut.String(), "profile `missing` is not found") {
+		t.Fatalf("unexpected error: %s", errOut.String())
+	}
+}
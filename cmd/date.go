@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+// resolve date argument (such as `today`, `-3d` or `last friday`) into `yyyyMMdd`.
+// relative date is resolved in `--tz` timezone or graph timezone (UTC when graph has no timezone).
+func (a *App) resolveDate(client *pixela.Pixela, graphID, expr string) (string, error) {
+	now := a.now()
+
+	// check syntax before looking up graph timezone
+	date, err := pixela.ResolveDate(expr, now, nil)
+
+	if err != nil {
+		return "", wrapUsageError(err, "argument error")
+	}
+
+	if pixela.IsAbsoluteDate(expr) {
+		return date, nil
+	}
+
+	loc, err := a.dateLocation(client, graphID)
+
+	if err != nil {
+		return "", err
+	}
+
+	return pixela.ResolveDate(expr, now, loc)
+}
+
+// resolve optional date flag. empty flag is left empty.
+func (a *App) resolveOptionalDate(client *pixela.Pixela, graphID, expr string) (string, error) {
+	if expr == "" {
+		return "", nil
+	}
+
+	return a.resolveDate(client, graphID, expr)
+}
+
+func (a *App) dateLocation(client *pixela.Pixela, graphID string) (*time.Location, error) {
+	timezone := a.viper.GetString("tz")
+
+	if timezone == "" {
+		graph, err := client.LookupGraph(graphID)
+
+		if err != nil {
+			return nil, errors.Wrap(err, "can not resolve graph timezone (use `--tz`)")
+		}
+
+		timezone = graph.Timezone
+	}
+
+	if timezone == "" {
+		return time.UTC, nil
+	}
+
+	canonical, err := pixela.NormalizeTimezone(timezone)
+
+	if err != nil {
+		return nil, wrapUsageError(err, "argument error")
+	}
+
+	return time.LoadLocation(canonical)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestApp_ResolveDate(t *testing.T) {
+	auth := []string{"--username", "testuser", "--token", "testtoken"}
+	var requested string
+
+	app, _, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/v1/users/testuser/graphs" {
+			return newTestResponse(http.StatusOK, `{"graphs":[{"id":"graphid","type":"int","timezone":"Asia/Tokyo"}]}`), nil
+		}
+
+		requested = req.URL.Path
+
+		return newTestResponse(http.StatusOK, `{"quantity":"1"}`), nil
+	})
+
+	// 2019-01-02 01:00 in Asia/Tokyo
+	app.now = func() time.Time { return time.Date(2019, 1, 1, 16, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"graph timezone", []string{"pixel", "get", "graphid", "yesterday"}, "/v1/users/testuser/graphs/graphid/20190101"},
+		{"tz flag", []string{"pixel", "get", "graphid", "yesterday", "--tz", "UTC"}, "/v1/users/testuser/graphs/graphid/20181231"},
+		{"default today", []string{"pixel", "get", "graphid"}, "/v1/users/testuser/graphs/graphid/20190102"},
+		{"absolute date", []string{"pixel", "get", "graphid", "2018-12-25"}, "/v1/users/testuser/graphs/graphid/20181225"},
+		{"negative relative date", []string{"pixel", "get", "graphid", "-3d"}, "/v1/users/testuser/graphs/graphid/20181230"},
+		{"negative relative date before flag", []string{"pixel", "get", "graphid", "-3d", "--tz", "UTC"}, "/v1/users/testuser/graphs/graphid/20181229"},
+		{"negative relative date after flag", []string{"pixel", "get", "--tz", "UTC", "graphid", "-3d"}, "/v1/users/testuser/graphs/graphid/20181229"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requested = ""
+
+			if got := app.Execute(append(tt.args, auth...)); got != ExitNormal {
+				t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+			}
+
+			if requested != tt.want {
+				t.Fatalf("want %s, but %s", tt.want, requested)
+			}
+		})
+	}
+}
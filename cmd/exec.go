@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"syscall"
+	"time"
+
+	"github.com/goark/gocli/exitcode"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+// record modes of `exec`
+const (
+	execRecordDuration = "duration"
+	execRecordSuccess  = "success"
+	execRecordOutput   = "output"
+)
+
+// execResult is outcome of child process
+type execResult struct {
+	start    time.Time
+	duration time.Duration
+	exitCode int
+	stdout   string
+	stderr   string
+}
+
+func (a *App) newExecCmd() *cobra.Command {
+	execCmd := &cobra.Command{
+		Use:   "exec",
+		Short: "run command and record its outcome into graph",
+		Long: `run command and record its outcome into pixel of the day when it is started. Usage:
+
+$ pixela exec <graph id> [--record duration/success/output] [--pattern regex] [--mode skip-existing/overwrite/add] -- <command> [args...]
+
+--record duration records run duration (minutes for int graph and hours for float graph) and adds it to pixel by default.
+--record success records 1 (exit code 0) or 0. --record output records number in output matched by --pattern
+(first group of last match in stdout, or in stderr when stdout does not match). both overwrite pixel by default.
+output of command passes through and exit code of command is exit code of pixela.
+exit status, duration (seconds) and host are recorded into optionalData.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if cmd.ArgsLenAtDash() != 1 || len(args) < 2 {
+				return usageErrorf("argument error: `exec` requires graph id and command after `--`")
+			}
+
+			record, _ := cmd.Flags().GetString("record")
+			pattern, _ := cmd.Flags().GetString("pattern")
+			modeStr, _ := cmd.Flags().GetString("mode")
+
+			var re *regexp.Regexp
+			mode := pixela.ImportOverwrite
+
+			switch record {
+			case execRecordDuration:
+				mode = pixela.ImportAdd
+			case execRecordSuccess:
+			case execRecordOutput:
+				if pattern == "" {
+					return usageErrorf("argument error: --record output requires --pattern")
+				}
+
+				var err error
+
+				if re, err = regexp.Compile(pattern); err != nil {
+					return wrapUsageError(err, "argument error")
+				}
+			default:
+				return usageErrorf("argument error: unknown record mode `%s`: allows duration, success and output", record)
+			}
+
+			if pattern != "" && record != execRecordOutput {
+				return usageErrorf("argument error: --pattern requires --record output")
+			}
+
+			if modeStr != "" {
+				var err error
+
+				if mode, err = pixela.ParseImportMode(modeStr); err != nil {
+					return wrapUsageError(err, "argument error")
+				}
+			}
+
+			// run command
+			result, err := a.runChild(args[1:], re != nil)
+
+			if err != nil {
+				return err
+			}
+
+			// record outcome (exit code of failed command is kept when recording fails too)
+			err = a.recordExec(args[0], record, re, mode, result)
+
+			if result.exitCode != 0 {
+				if err != nil {
+					a.ui.OutputErrln(err.Error())
+				}
+
+				cmd.SilenceErrors, cmd.SilenceUsage = true, true
+
+				return &childExitError{exitcode.ExitCode(result.exitCode)}
+			}
+
+			return err
+		},
+	}
+
+	execCmd.Flags().String("record", execRecordDuration, "recorded outcome (duration/success/output)")
+	execCmd.Flags().String("pattern", "", "regex of number in output (--record output)")
+	execCmd.Flags().String("mode", "", "mode for existing pixel (skip-existing/overwrite/add)")
+
+	execCmd.RegisterFlagCompletionFunc("record", completeValues(execRecordDuration, execRecordSuccess, execRecordOutput))
+	execCmd.RegisterFlagCompletionFunc("mode", completeValues(stringValues(pixela.ImportModes())...))
+
+	return execCmd
+}
+
+// run child process with passing through I/O and signals
+func (a *App) runChild(command []string, capture bool) (execResult, error) {
+	child := exec.Command(command[0], command[1:]...)
+	child.Stdin = a.ui.Reader()
+	child.Stdout = a.ui.Writer()
+	child.Stderr = a.ui.ErrorWriter()
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+	// stdout and stderr are captured separately (they are copied concurrently)
+	if capture {
+		child.Stdout = io.MultiWriter(child.Stdout, stdout)
+		child.Stderr = io.MultiWriter(child.Stderr, stderr)
+	}
+
+	result := execResult{start: a.now()}
+
+	if err := child.Start(); err != nil {
+		return execResult{}, wrapUsageError(err, "command error")
+	}
+
+	// interrupt is handled by command and its outcome is still recorded
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				child.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	if err := child.Wait(); err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return execResult{}, errors.Wrap(err, "command error")
+		}
+	}
+
+	result.duration = a.now().Sub(result.start)
+	result.exitCode = child.ProcessState.ExitCode()
+	result.stdout, result.stderr = stdout.String(), stderr.String()
+
+	// killed by signal
+	if result.exitCode < 0 {
+		result.exitCode = int(ExitAbnormal)
+	}
+
+	return result, nil
+}
+
+// record outcome of child process into graph
+func (a *App) recordExec(graphID, record string, re *regexp.Regexp, mode pixela.ImportMode, result execResult) error {
+	client, err := a.newClient()
+
+	if err != nil {
+		return err
+	}
+
+	graph, err := client.LookupGraph(graphID)
+
+	if err != nil {
+		return errors.Wrap(err, "request error")
+	}
+
+	loc, err := a.dateLocation(client, graphID)
+
+	if err != nil {
+		return err
+	}
+
+	numType := pixela.NumType(graph.Type)
+	var quantity string
+
+	switch record {
+	case execRecordDuration:
+		quantity, err = timerQuantity(result.duration, numType)
+	case execRecordSuccess:
+		quantity = "0"
+
+		if result.exitCode == 0 {
+			quantity = "1"
+		}
+	case execRecordOutput:
+		matches := re.FindAllStringSubmatch(result.stdout, -1)
+
+		if len(matches) == 0 {
+			matches = re.FindAllStringSubmatch(result.stderr, -1)
+		}
+
+		if len(matches) == 0 {
+			return fmt.Errorf("output error: output does not match `%s`", re)
+		}
+
+		// first group (or whole match without group)
+		match := matches[len(matches)-1]
+		quantity = match[0]
+
+		if len(match) > 1 {
+			quantity = match[1]
+		}
+
+		quantity, err = pixela.ConvertQuantity(numType)(quantity)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "output error")
+	}
+
+	host, _ := os.Hostname()
+
+	optionalData, err := formatJSON(map[string]interface{}{
+		"exitStatus": result.exitCode,
+		"duration":   result.duration.Round(time.Millisecond).Seconds(),
+		"host":       host,
+	}, false)
+
+	if err != nil {
+		return err
+	}
+
+	pixel := pixela.PixelRecord{
+		Date:         result.start.In(loc).Format(pixela.DateFormat),
+		Quantity:     quantity,
+		OptionalData: optionalData,
+	}
+
+	changes, err := client.RecordPixels(graphID, []pixela.PixelRecord{pixel}, mode, nil)
+
+	if err != nil {
+		return errors.Wrap(err, "request error")
+	}
+
+	// output of command is on stdout
+	for _, change := range changes {
+		switch {
+		case change.Action == pixela.ImportSkip:
+			a.ui.OutputErrln(fmt.Sprintf("%s is skipped (pixel exists)", pixel.Date))
+		case mode == pixela.ImportAdd:
+			a.ui.OutputErrln(fmt.Sprintf("%s +%s", pixel.Date, quantity))
+		default:
+			a.ui.OutputErrln(fmt.Sprintf("%s %s", pixel.Date, quantity))
+		}
+	}
+
+	return nil
+}
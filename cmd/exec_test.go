@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goark/gocli/exitcode"
+)
+
+func TestApp_Exec(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not found")
+	}
+
+	auth := []string{"--username", "testuser", "--token", "testtoken"}
+	host, _ := os.Hostname()
+	var requests []string
+
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/graphs"):
+			return newTestResponse(http.StatusOK, `{"graphs":[{"id":"build","type":"int","timezone":"Asia/Tokyo"}]}`), nil
+		case req.Method == http.MethodGet:
+			return newTestResponse(http.StatusOK, `{"pixels":[{"date":"20190101","quantity":"10"}]}`), nil
+		}
+
+		body, _ := ioutil.ReadAll(req.Body)
+		requests = append(requests, req.Method+" "+req.URL.Path+" "+string(body))
+
+		return newTestResponse(http.StatusOK, `{"message":"Success.","isSuccess":true}`), nil
+	})
+
+	// command runs 30 minutes from 2019-01-01 23:30 in Asia/Tokyo
+	start := time.Date(2019, 1, 1, 14, 30, 0, 0, time.UTC)
+	now := start
+	app.now = func() time.Time {
+		defer func() { now = now.Add(30 * time.Minute) }()
+		return now
+	}
+
+	optionalData := func(exitStatus int) string {
+		data, _ := json.Marshal(fmt.Sprintf(`{"duration":1800,"exitStatus":%d,"host":%q}`, exitStatus, host))
+		return string(data)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    exitcode.ExitCode
+		out     string
+		request string
+	}{
+		{
+			name:    "duration",
+			args:    []string{"exec", "build", "--", "sh", "-c", "echo hello; exit 3"},
+			want:    3,
+			out:     "hello\n",
+			request: `PUT /v1/users/testuser/graphs/build/20190101 {"date":"","quantity":"40","optionalData":` + optionalData(3) + `}`,
+		},
+		{
+			name:    "success",
+			args:    []string{"exec", "build", "--record", "success", "--", "sh", "-c", "exit 0"},
+			want:    ExitNormal,
+			request: `PUT /v1/users/testuser/graphs/build/20190101 {"date":"","quantity":"1","optionalData":` + optionalData(0) + `}`,
+		},
+		{
+			name:    "failure",
+			args:    []string{"exec", "build", "--record", "success", "--", "sh", "-c", "exit 1"},
+			want:    1,
+			request: `PUT /v1/users/testuser/graphs/build/20190101 {"date":"","quantity":"0","optionalData":` + optionalData(1) + `}`,
+		},
+		{
+			name:    "output",
+			args:    []string{"exec", "build", "--record", "output", "--pattern", `coverage: ([0-9.]+)%`, "--", "sh", "-c", "echo coverage: 50.0%; echo coverage: 10.0% >&2; echo coverage: 83.4%"},
+			want:    ExitNormal,
+			out:     "coverage: 50.0%\ncoverage: 83.4%\n",
+			request: `PUT /v1/users/testuser/graphs/build/20190101 {"date":"","quantity":"83","optionalData":` + optionalData(0) + `}`,
+		},
+		{
+			name:    "output in stderr",
+			args:    []string{"exec", "build", "--record", "output", "--pattern", `coverage: ([0-9.]+)%`, "--", "sh", "-c", "echo done; echo coverage: 10.0% >&2"},
+			want:    ExitNormal,
+			out:     "done\n",
+			request: `PUT /v1/users/testuser/graphs/build/20190101 {"date":"","quantity":"10","optionalData":` + optionalData(0) + `}`,
+		},
+		{
+			name:    "output with groups",
+			args:    []string{"exec", "build", "--record", "output", "--pattern", `(\d+) of (\d+) passed`, "--", "sh", "-c", "echo 42 of 50 passed"},
+			want:    ExitNormal,
+			out:     "42 of 50 passed\n",
+			request: `PUT /v1/users/testuser/graphs/build/20190101 {"date":"","quantity":"42","optionalData":` + optionalData(0) + `}`,
+		},
+		{
+			name:    "output without group",
+			args:    []string{"exec", "build", "--record", "output", "--pattern", `[0-9]+`, "--", "sh", "-c", "echo 7 warnings"},
+			want:    ExitNormal,
+			out:     "7 warnings\n",
+			request: `PUT /v1/users/testuser/graphs/build/20190101 {"date":"","quantity":"7","optionalData":` + optionalData(0) + `}`,
+		},
+		{
+			name: "output not matched",
+			args: []string{"exec", "build", "--record", "output", "--pattern", `coverage: ([0-9.]+)%`, "--", "sh", "-c", "echo nothing"},
+			want: ExitAbnormal,
+			out:  "nothing\n",
+		},
+		{
+			name: "no command",
+			args: []string{"exec", "build"},
+			want: ExitUsage,
+		},
+		{
+			name: "unknown record mode",
+			args: []string{"exec", "build", "--record", "count", "--", "true"},
+			want: ExitUsage,
+		},
+		{
+			name: "pattern without output mode",
+			args: []string{"exec", "build", "--pattern", "a", "--", "true"},
+			want: ExitUsage,
+		},
+		{
+			name: "command not found",
+			args: []string{"exec", "build", "--", "pixela-test-command-not-found"},
+			want: ExitUsage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests, now = nil, start
+			out.Reset()
+			errOut.Reset()
+
+			// global flags after `--` are arguments of command
+			args := append(append([]string{tt.args[0]}, auth...), tt.args[1:]...)
+
+			if got := app.Execute(args); got != tt.want {
+				t.Fatalf("want %d, but %d: %s", tt.want, got, errOut.String())
+			}
+
+			if out.String() != tt.out {
+				t.Errorf("want output %q, but %q", tt.out, out.String())
+			}
+
+			if tt.request == "" {
+				if len(requests) != 0 {
+					t.Errorf("unexpected requests: %v", requests)
+				}
+
+				return
+			}
+
+			if len(requests) != 1 || requests[0] != tt.request {
+				t.Errorf("want\n%s\nbut\n%v", tt.request, requests)
+			}
+		})
+	}
+}
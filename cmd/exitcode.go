@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/goark/gocli/exitcode"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+// exit codes of pixela command
+const (
+	// ExitNormal is exit code of success
+	ExitNormal = exitcode.Normal
+	// ExitAbnormal is exit code of other errors (such as conflict)
+	ExitAbnormal = exitcode.Abnormal
+	// ExitUsage is exit code of wrong arguments, flags or config
+	ExitUsage exitcode.ExitCode = 2
+	// ExitValidation is exit code of argument validation error (before request or by pixe.la)
+	ExitValidation exitcode.ExitCode = 3
+	// ExitAuth is exit code of wrong username or token
+	ExitAuth exitcode.ExitCode = 4
+	// ExitNotFound is exit code when user, graph, pixel or webhook is not found
+	ExitNotFound exitcode.ExitCode = 5
+	// ExitRetryable is exit code of server error, rate limit or network error (retry may succeed)
+	ExitRetryable exitcode.ExitCode = 6
+	// ExitPanic is exit code of internal error
+	ExitPanic exitcode.ExitCode = 70
+)
+
+// usageError is error of wrong arguments, flags or config
+type usageError struct {
+	err error
+}
+
+func usageErrorf(format string, a ...interface{}) error {
+	return &usageError{fmt.Errorf(format, a...)}
+}
+
+func wrapUsageError(err error, message string) error {
+	return &usageError{fmt.Errorf("%s: %w", message, err)}
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// childExitError is exit code of child process (`exec`) which is passed through
+type childExitError struct {
+	code exitcode.ExitCode
+}
+
+func (e *childExitError) Error() string {
+	return fmt.Sprintf("child process exited with %d", e.code)
+}
+
+// map error onto exit code
+func exitCodeOf(err error) exitcode.ExitCode {
+	if err == nil {
+		return ExitNormal
+	}
+
+	var childErr *childExitError
+
+	if errors.As(err, &childErr) {
+		return childErr.code
+	}
+
+	var validationErr *pixela.ValidationError
+
+	if errors.As(err, &validationErr) {
+		return ExitValidation
+	}
+
+	var usageErr *usageError
+
+	if errors.As(err, &usageErr) {
+		return ExitUsage
+	}
+
+	var apiErr *pixela.APIError
+
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Unauthorized():
+			return ExitAuth
+		case apiErr.NotFound():
+			return ExitNotFound
+		case apiErr.Retryable():
+			return ExitRetryable
+		case apiErr.StatusCode == http.StatusBadRequest:
+			return ExitValidation
+		}
+
+		return ExitAbnormal
+	}
+
+	// errors of http client (such as connection refused or timeout)
+	var urlErr *url.Error
+
+	if errors.As(err, &urlErr) {
+		return ExitRetryable
+	}
+
+	// errors of cobra (such as unknown subcommand)
+	if strings.HasPrefix(err.Error(), "unknown command") {
+		return ExitUsage
+	}
+
+	return ExitAbnormal
+}
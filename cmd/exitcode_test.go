@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/goark/gocli/exitcode"
+	"github.com/goark/gocli/rwi"
+	pkgerrors "github.com/pkg/errors"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// app whose pixe.la client returns the response
+func newTestApp(t *testing.T, statusCode int, body string, err error) (*App, *bytes.Buffer) {
+	app, _, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		if err != nil {
+			return nil, err
+		}
+
+		return newTestResponse(statusCode, body), nil
+	})
+
+	return app, errOut
+}
+
+// app whose pixe.la client sends requests to the transport
+func newTestAppWithTransport(t *testing.T, transport roundTripFunc) (*App, *bytes.Buffer, *bytes.Buffer) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("PIXELA_STATE_DIR", t.TempDir())
+
+	client := &http.Client{Transport: transport}
+
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	ui := rwi.New(rwi.WithWriter(out), rwi.WithErrorWriter(errOut))
+
+	return NewApp(ui, WithClientFactory(func(username, token string, verbose bool, opts ...pixela.Option) (*pixela.Pixela, error) {
+		return pixela.New(username, token, verbose, append(opts, pixela.OptionHTTPClient(client))...)
+	})), out, errOut
+}
+
+func newTestResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+		Header:     make(http.Header),
+	}
+}
+
+func TestExitCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want exitcode.ExitCode
+	}{
+		{"Success", nil, ExitNormal},
+		{"Usage", usageErrorf("argument error: `pixel get` requires 1 or 2 arguments give %d arguments", 3), ExitUsage},
+		{"Wrapped usage", pkgerrors.Wrap(wrapUsageError(errors.New("bad"), "config error"), "request error"), ExitUsage},
+		{"Unknown command", errors.New(`unknown command "foo" for "pixela"`), ExitUsage},
+		{"Validation", pkgerrors.Wrap(&pixela.ValidationError{}, "request error"), ExitValidation},
+		{"Bad request", &pixela.APIError{Method: "post", StatusCode: http.StatusBadRequest}, ExitValidation},
+		{"Unauthorized", &pixela.APIError{Method: "get", StatusCode: http.StatusUnauthorized}, ExitAuth},
+		{"Forbidden", &pixela.APIError{Method: "get", StatusCode: http.StatusForbidden}, ExitAuth},
+		{"Not found", pkgerrors.Wrap(&pixela.APIError{Method: "get", StatusCode: http.StatusNotFound}, "request error"), ExitNotFound},
+		{"Server error", &pixela.APIError{Method: "put", StatusCode: http.StatusInternalServerError}, ExitRetryable},
+		{"Rate limit", &pixela.APIError{Method: "put", StatusCode: http.StatusServiceUnavailable}, ExitRetryable},
+		{"Network", pkgerrors.Wrap(&url.Error{Op: "Get", URL: "https://pixe.la", Err: errors.New("connection refused")}, "http get request failed"), ExitRetryable},
+		{"Conflict", &pixela.APIError{Method: "post", StatusCode: http.StatusConflict}, ExitAbnormal},
+		{"Child process", &childExitError{42}, 42},
+		{"Other", errors.New("other"), ExitAbnormal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCodeOf(tt.err); got != tt.want {
+				t.Fatalf("want %d, but %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestApp_ExecuteExitCode(t *testing.T) {
+	auth := []string{"--username", "testuser", "--token", "testtoken"}
+
+	tests := []struct {
+		name       string
+		args       []string
+		statusCode int
+		body       string
+		err        error
+		want       exitcode.ExitCode
+	}{
+		{"Success", []string{"pixel", "get", "graphid", "20190101"}, http.StatusOK, `{"quantity":"1"}`, nil, ExitNormal},
+		{"Wrong number of arguments", []string{"pixel", "get", "graphid", "20190101", "1"}, http.StatusOK, "", nil, ExitUsage},
+		{"Unknown flag", []string{"pixel", "get", "--foo"}, http.StatusOK, "", nil, ExitUsage},
+		{"Unknown command", []string{"foo"}, http.StatusOK, "", nil, ExitUsage},
+		{"Missing config file", []string{"--config", "/not/exist/pixela.yaml", "pixel", "get", "graphid"}, http.StatusOK, "", nil, ExitUsage},
+		{"Invalid date", []string{"pixel", "get", "graphid", "someday"}, http.StatusOK, "", nil, ExitUsage},
+		{"Invalid graph id", []string{"pixel", "get", "1graph", "20190101"}, http.StatusOK, "", nil, ExitValidation},
+		{"Unauthorized", []string{"pixel", "get", "graphid", "20190101"}, http.StatusUnauthorized, `{"message":"User token is invalid.","isSuccess":false}`, nil, ExitAuth},
+		{"Not found", []string{"pixel", "get", "graphid", "20190101"}, http.StatusNotFound, `{"message":"Specified pixel not found.","isSuccess":false}`, nil, ExitNotFound},
+		{"Server error", []string{"pixel", "create", "graphid", "20190101", "1"}, http.StatusServiceUnavailable, `<html></html>`, nil, ExitRetryable},
+		{"Network error", []string{"pixel", "delete", "graphid", "20190101"}, 0, "", errors.New("connection refused"), ExitRetryable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, errOut := newTestApp(t, tt.statusCode, tt.body, tt.err)
+
+			if got := app.Execute(append(tt.args, auth...)); got != tt.want {
+				t.Fatalf("want %d, but %d: %s", tt.want, got, errOut.String())
+			}
+		})
+	}
+}
+
+func TestApp_ExecuteRepeatedly(t *testing.T) {
+	app, errOut := newTestApp(t, http.StatusOK, `{"quantity":"1"}`, nil)
+
+	if got := app.Execute([]string{"pixel", "get", "graphid", "20190101", "--username", "testuser", "--token", "testtoken"}); got != ExitNormal {
+		t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+	}
+
+	// settings of previous execution are not kept
+	if got := app.Execute([]string{"pixel", "get", "graphid", "20190101"}); got != ExitUsage {
+		t.Fatalf("want %d, but %d: %s", ExitUsage, got, errOut.String())
+	}
+}
+
+func TestApp_QuantityTypeCheck(t *testing.T) {
+	auth := []string{"--username", "testuser", "--token", "testtoken"}
+
+	tests := []struct {
+		name string
+		args []string
+		want exitcode.ExitCode
+	}{
+		{"strict", nil, ExitValidation},
+		{"strict without check", []string{"--check-quantity-type=false"}, ExitNormal},
+		{"lenient", []string{"--validation-mode", "lenient"}, ExitNormal},
+		{"lenient with check", []string{"--validation-mode", "lenient", "--check-quantity-type"}, ExitValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+				if req.Method == http.MethodGet {
+					return newTestResponse(http.StatusOK, `{"graphs":[{"id":"graphid","type":"int"}]}`), nil
+				}
+
+				return newTestResponse(http.StatusOK, `{"message":"Success.","isSuccess":true}`), nil
+			})
+
+			// float quantity for int graph
+			args := append(append([]string{"pixel", "create", "graphid", "20190101", "1.5"}, tt.args...), auth...)
+
+			if got := app.Execute(args); got != tt.want {
+				t.Fatalf("want %d, but %d: %s", tt.want, got, errOut.String())
+			}
+		})
+	}
+}
+
+func TestApp_NegativeQuantity(t *testing.T) {
+	auth := []string{"--username", "testuser", "--token", "testtoken"}
+	var requests []string
+
+	app, _, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodGet {
+			return newTestResponse(http.StatusOK, `{"graphs":[{"id":"graphid","type":"int"}]}`), nil
+		}
+
+		body, _ := ioutil.ReadAll(req.Body)
+		requests = append(requests, req.Method+" "+req.URL.Path+" "+string(body))
+
+		return newTestResponse(http.StatusOK, `{"message":"Success.","isSuccess":true}`), nil
+	})
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"create", []string{"pixel", "create", "graphid", "20190101", "-5"}, `POST /v1/users/testuser/graphs/graphid {"date":"20190101","quantity":"-5"}`},
+		{"create today", []string{"pixel", "create", "graphid", "-5"}, `POST /v1/users/testuser/graphs/graphid {"date":"20190102","quantity":"-5"}`},
+		{"update", []string{"pixel", "update", "graphid", "-1d", "-3", "--tz", "UTC"}, `PUT /v1/users/testuser/graphs/graphid/20190101 {"date":"","quantity":"-3"}`},
+	}
+
+	app.now = func() time.Time { return time.Date(2019, 1, 2, 12, 0, 0, 0, time.UTC) }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = nil
+
+			if got := app.Execute(append(tt.args, auth...)); got != ExitNormal {
+				t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+			}
+
+			if len(requests) != 1 || requests[0] != tt.want {
+				t.Fatalf("want %s, but %v", tt.want, requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,533 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+// marker of post-commit hook installed by `git install-hook`
+const gitHookMarker = "# installed by `pixela git install-hook`"
+
+// existing post-commit hook is moved to this name and called by installed hook
+const gitChainedHook = "post-commit.pixela-chained"
+
+// gitState is commits recorded into each graph (by graph ID and commit SHA)
+type gitState struct {
+	Graphs map[string]map[string]gitRecordedCommit `json:"graphs"`
+}
+
+// gitRecordedCommit is patch ID of recorded commit (rebased or amended commit keeps patch ID of its changes)
+type gitRecordedCommit struct {
+	PatchID string `json:"patchID,omitempty"`
+	Date    string `json:"date"`
+}
+
+// gitCommit is commit read from `git log` (commit without changes has no patch ID)
+type gitCommit struct {
+	SHA        string
+	Parents    int
+	AuthorTime int64
+	PatchID    string
+}
+
+func (a *App) newGitCmd() *cobra.Command {
+	gitCmd := &cobra.Command{
+		Use:   "git",
+		Short: "record git commits into graph",
+		Long: `record git commits into graph. Usage:
+
+$ pixela git install-hook <graph id> [--repo dir]
+$ pixela git backfill <graph id> [--author pattern] [--since date] [--repo dir] [--dry-run]
+$ pixela git record <graph id> [--repo dir]
+
+commits are counted per day of author date in graph timezone (or --tz).
+recorded commits are saved in local state file and commits already recorded
+(same SHA, or same changes (git patch-id) such as rebased or amended commit) are not counted twice.`,
+	}
+
+	gitCmd.AddCommand(a.newGitInstallHookCmd())
+	gitCmd.AddCommand(a.newGitBackfillCmd())
+	gitCmd.AddCommand(a.newGitRecordCmd())
+
+	return gitCmd
+}
+
+func (a *App) newGitInstallHookCmd() *cobra.Command {
+	gitInstallHookCmd := &cobra.Command{
+		Use:   "install-hook",
+		Short: "install post-commit hook recording commits",
+		Long: `install post-commit hook which records each commit into graph. Usage:
+
+$ pixela git install-hook <graph id> [--repo dir]
+
+existing post-commit hook is moved to post-commit.pixela-chained and it is still called by installed hook.
+installing hook for other graph adds the graph to installed hook.
+hook calls this executable with --profile (when it is given), so settings must be in config file or environment variables.
+hook runs synchronously, so commit waits for the request. failed record does not fail the commit,
+hook prints warning instead (record missed commits by ` + "`git backfill`" + `).`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 1 {
+				return usageErrorf("argument error: `git install-hook` requires 1 argument give %d arguments", len(args))
+			}
+
+			repo, _ := cmd.Flags().GetString("repo")
+
+			// check graph exists
+			client, err := a.newClient()
+
+			if err != nil {
+				return err
+			}
+
+			if _, err := client.LookupGraph(args[0]); err != nil {
+				return errors.Wrap(err, "request error")
+			}
+
+			hooksDir, err := runGit(repo, "rev-parse", "--git-path", "hooks")
+
+			if err != nil {
+				return err
+			}
+
+			if !filepath.IsAbs(hooksDir) {
+				hooksDir = filepath.Join(repo, hooksDir)
+			}
+
+			executable, err := os.Executable()
+
+			if err != nil {
+				return err
+			}
+
+			command := shellQuote(executable)
+
+			if profile := a.viper.GetString("profile"); profile != "" {
+				command += " --profile " + shellQuote(profile)
+			}
+
+			path := filepath.Join(hooksDir, "post-commit")
+			warning := fmt.Sprintf("pixela: commit is not recorded into `%s` (record it by `pixela git backfill`)", args[0])
+			installed, err := installGitHook(path, fmt.Sprintf("%s git record %s || echo %s >&2", command, shellQuote(args[0]), shellQuote(warning)))
+
+			if err != nil {
+				return err
+			}
+
+			if !installed {
+				a.printStatus(fmt.Sprintf("hook for `%s` is already installed in %s", args[0], path))
+				return nil
+			}
+
+			a.printStatus(fmt.Sprintf("hook for `%s` is installed in %s", args[0], path))
+
+			return nil
+		},
+	}
+
+	gitInstallHookCmd.Flags().String("repo", ".", "git repository")
+
+	return gitInstallHookCmd
+}
+
+func (a *App) newGitBackfillCmd() *cobra.Command {
+	gitBackfillCmd := &cobra.Command{
+		Use:   "backfill",
+		Short: "record commits in git log",
+		Long: `record daily commit counts of local git log (merge commits are excluded). Usage:
+
+$ pixela git backfill <graph id> [--author pattern] [--since date] [--repo dir] [--dry-run]
+
+--author and --since are passed to git log. commits already recorded (by hook or last backfill) are skipped,
+so backfill can be run again such as after commits are made while pe.la is not available.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 1 {
+				return usageErrorf("argument error: `git backfill` requires 1 argument give %d arguments", len(args))
+			}
+
+			repo, _ := cmd.Flags().GetString("repo")
+			author, _ := cmd.Flags().GetString("author")
+			since, _ := cmd.Flags().GetString("since")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			gitArgs := []string{"log", "--no-merges"}
+
+			if author != "" {
+				gitArgs = append(gitArgs, "--author="+author)
+			}
+
+			if since != "" {
+				gitArgs = append(gitArgs, "--since="+since)
+			}
+
+			commits, err := gitLog(repo, gitArgs...)
+
+			if err != nil {
+				return err
+			}
+
+			return a.recordCommits(args[0], commits, dryRun)
+		},
+	}
+
+	gitBackfillCmd.Flags().String("repo", ".", "git repository")
+	gitBackfillCmd.Flags().String("author", "", "author pattern (git log --author)")
+	gitBackfillCmd.Flags().String("since", "", "date of oldest commit (git log --since)")
+	gitBackfillCmd.Flags().Bool("dry-run", false, "show daily counts without request")
+
+	return gitBackfillCmd
+}
+
+func (a *App) newGitRecordCmd() *cobra.Command {
+	gitRecordCmd := &cobra.Command{
+		Use:   "record",
+		Short: "record HEAD commit (called by hook)",
+		Long: `record HEAD commit into graph (called by post-commit hook). Usage:
+
+$ pixela git record <graph id> [--repo dir]
+
+merge commit and commit already recorded (such as rebased or amended commit) are skipped.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 1 {
+				return usageErrorf("argument error: `git record` requires 1 argument give %d arguments", len(args))
+			}
+
+			repo, _ := cmd.Flags().GetString("repo")
+
+			commits, err := gitLog(repo, "log", "-1", "HEAD")
+
+			if err != nil {
+				return err
+			}
+
+			if len(commits) == 0 || commits[0].Parents > 1 {
+				return nil
+			}
+
+			return a.recordCommits(args[0], commits, false)
+		},
+	}
+
+	gitRecordCmd.Flags().String("repo", ".", "git repository")
+
+	return gitRecordCmd
+}
+
+// add commits which are not recorded yet to pixels of author dates and save them in state
+func (a *App) recordCommits(graphID string, commits []gitCommit, dryRun bool) error {
+	client, err := a.newClient()
+
+	if err != nil {
+		return err
+	}
+
+	graph, err := client.LookupGraph(graphID)
+
+	if err != nil {
+		return errors.Wrap(err, "request error")
+	}
+
+	loc, err := a.dateLocation(client, graphID)
+
+	if err != nil {
+		return err
+	}
+
+	path, err := a.statePath("git")
+
+	if err != nil {
+		return err
+	}
+
+	state := gitState{}
+
+	if err := loadState(path, &state); err != nil {
+		return err
+	}
+
+	if state.Graphs == nil {
+		state.Graphs = map[string]map[string]gitRecordedCommit{}
+	}
+
+	recorded := state.Graphs[graphID]
+
+	if recorded == nil {
+		recorded = map[string]gitRecordedCommit{}
+		state.Graphs[graphID] = recorded
+	}
+
+	patches := map[string]bool{}
+
+	for _, c := range recorded {
+		if c.PatchID != "" {
+			patches[c.PatchID] = true
+		}
+	}
+
+	counts := map[string]int{}
+	added := map[string]gitRecordedCommit{}
+
+	for _, c := range commits {
+		if _, ok := recorded[c.SHA]; ok || patches[c.PatchID] {
+			continue
+		}
+
+		if c.PatchID != "" {
+			patches[c.PatchID] = true
+		}
+
+		date := time.Unix(c.AuthorTime, 0).In(loc).Format(pixela.DateFormat)
+		counts[date]++
+		added[c.SHA] = gitRecordedCommit{PatchID: c.PatchID, Date: date}
+	}
+
+	dates := make([]string, 0, len(counts))
+
+	for date := range counts {
+		dates = append(dates, date)
+	}
+
+	sort.Strings(dates)
+
+	convert := pixela.ConvertQuantity(pixela.NumType(graph.Type))
+	pixels := make([]pixela.PixelRecord, 0, len(dates))
+
+	for _, date := range dates {
+		quantity, err := convert(strconv.Itoa(counts[date]))
+
+		if err != nil {
+			return err
+		}
+
+		pixels = append(pixels, pixela.PixelRecord{Date: date, Quantity: quantity})
+	}
+
+	if !dryRun {
+		// save commits of each recorded day, so they are not counted twice after failure
+		done := func(change pixela.ImportChange) error {
+			for sha, c := range added {
+				if c.Date == change.New.Date {
+					recorded[sha] = c
+				}
+			}
+
+			return saveState(path, state)
+		}
+
+		if _, err := client.RecordPixels(graphID, pixels, pixela.ImportAdd, done); err != nil {
+			return errors.Wrap(err, "request error")
+		}
+	}
+
+	for _, p := range pixels {
+		a.printStatus(fmt.Sprintf("%s +%s", p.Date, p.Quantity))
+	}
+
+	verb := "are"
+
+	if dryRun {
+		verb = "will be"
+	}
+
+	a.printStatus(fmt.Sprintf("%d commits %s recorded (%d commits are already recorded)", len(added), verb, len(commits)-len(added)))
+
+	return nil
+}
+
+// install post-commit hook including record line. existing hook which is not installed by pixela is chained.
+// record line of the same command (such as installed by older version) is replaced.
+// it returns false when the line is already installed.
+func installGitHook(path, line string) (bool, error) {
+	lines := []string{}
+	content, err := os.ReadFile(path)
+
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return false, err
+	case bytes.Contains(content, []byte(gitHookMarker)):
+		command := strings.SplitN(line, " || ", 2)[0]
+
+		for _, l := range strings.Split(string(content), "\n") {
+			if strings.Contains(l, " git record ") {
+				if l == line {
+					return false, nil
+				}
+
+				if strings.SplitN(l, " || ", 2)[0] != command {
+					lines = append(lines, l)
+				}
+			}
+		}
+	default:
+		chained := filepath.Join(filepath.Dir(path), gitChainedHook)
+
+		if _, err := os.Stat(chained); err == nil {
+			return false, usageErrorf("input error: %s already exists", chained)
+		}
+
+		if err := os.Rename(path, chained); err != nil {
+			return false, err
+		}
+	}
+
+	lines = append(lines, line)
+
+	hook := fmt.Sprintf(`#!/bin/sh
+%s
+status=0
+chained="$(dirname "$0")/%s"
+
+if [ -x "$chained" ]; then
+	"$chained" "$@" || status=$?
+fi
+
+%s
+
+exit $status
+`, gitHookMarker, gitChainedHook, strings.Join(lines, "\n"))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+
+	return true, os.WriteFile(path, []byte(hook), 0755)
+}
+
+// run git command in repository and return trimmed stdout
+func runGit(repo string, args ...string) (string, error) {
+	stderr := &bytes.Buffer{}
+	git := exec.Command("git", append([]string{"-C", repo}, args...)...)
+	git.Stderr = stderr
+
+	out, err := git.Output()
+
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", usageErrorf("git error: %s", message)
+		}
+
+		return "", wrapUsageError(err, "git error")
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// commits of `git log` arguments
+func gitLog(repo string, args ...string) ([]gitCommit, error) {
+	out, err := runGit(repo, append(args, "--format=%H%x09%P%x09%at")...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	commits := []gitCommit{}
+
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("git error: unexpected log `%s`", line)
+		}
+
+		authorTime, err := strconv.ParseInt(fields[2], 10, 64)
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "git error: unexpected author time of %s", fields[0])
+		}
+
+		commits = append(commits, gitCommit{
+			SHA:        fields[0],
+			Parents:    len(strings.Fields(fields[1])),
+			AuthorTime: authorTime,
+		})
+	}
+
+	patchIDs, err := gitPatchIDs(repo, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range commits {
+		commits[i].PatchID = patchIDs[commits[i].SHA]
+	}
+
+	return commits, nil
+}
+
+// patch IDs (git patch-id --stable) of commits of `git log` arguments by SHA
+func gitPatchIDs(repo string, args ...string) (map[string]string, error) {
+	// stderr is not shared because output of each command is copied concurrently
+	logStderr, patchIDStderr := &bytes.Buffer{}, &bytes.Buffer{}
+	stdout := &bytes.Buffer{}
+
+	log := exec.Command("git", append([]string{"-C", repo}, append(args, "-p", "--no-color", "--no-ext-diff", "--format=commit %H")...)...)
+	log.Stderr = logStderr
+
+	patchID := exec.Command("git", "-C", repo, "patch-id", "--stable")
+	patchID.Stdout, patchID.Stderr = stdout, patchIDStderr
+
+	pipe, err := log.StdoutPipe()
+
+	if err != nil {
+		return nil, err
+	}
+
+	patchID.Stdin = pipe
+
+	if err := log.Start(); err != nil {
+		return nil, wrapUsageError(err, "git error")
+	}
+
+	// patch-id reads whole log before log is waited
+	patchIDErr := patchID.Run()
+
+	if err := log.Wait(); err != nil || patchIDErr != nil {
+		if message := strings.TrimSpace(logStderr.String() + patchIDStderr.String()); message != "" {
+			return nil, usageErrorf("git error: %s", message)
+		}
+
+		if err == nil {
+			err = patchIDErr
+		}
+
+		return nil, wrapUsageError(err, "git error")
+	}
+
+	patchIDs := map[string]string{}
+
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 {
+			patchIDs[fields[1]] = fields[0]
+		}
+	}
+
+	return patchIDs, nil
+}
+
+// quote string for POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
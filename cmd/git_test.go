@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// create git repository with commits of author and author date (each commit appends line to file)
+func newTestGitRepo(t *testing.T, commits ...[2]string) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not found")
+	}
+
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	repo := t.TempDir()
+	testGit(t, repo, "", "init", "-q")
+
+	for i, c := range commits {
+		testGitCommit(t, repo, c[0], c[1], fmt.Sprintf("%d %s %s", i, c[0], c[1]))
+	}
+
+	return repo
+}
+
+func testGitCommit(t *testing.T, repo, author, date, message string) {
+	t.Helper()
+
+	f, err := os.OpenFile(filepath.Join(repo, "log.txt"), os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer f.Close()
+
+	if _, err := f.WriteString(message + "\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	testGit(t, repo, "", "add", "log.txt")
+	testGit(t, repo, date, "-c", "user.name="+author, "-c", "user.email="+author+"@example.com", "commit", "-q", "-m", message)
+}
+
+func testGit(t *testing.T, repo, date string, args ...string) {
+	t.Helper()
+
+	git := exec.Command("git", append([]string{"-C", repo}, args...)...)
+	git.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+
+	if out, err := git.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
+
+func TestApp_Git(t *testing.T) {
+	auth := []string{"--username", "testuser", "--token", "testtoken"}
+	var requests []string
+
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/graphs"):
+			return newTestResponse(http.StatusOK, `{"graphs":[{"id":"commits","type":"int","timezone":"Asia/Tokyo"},{"id":"focus","type":"int","timezone":"Asia/Tokyo"}]}`), nil
+		case req.Method == http.MethodGet:
+			return newTestResponse(http.StatusOK, `{"pixels":[{"date":"20190101","quantity":"10"}]}`), nil
+		}
+
+		body, _ := ioutil.ReadAll(req.Body)
+		requests = append(requests, req.Method+" "+req.URL.Path+" "+string(body))
+
+		return newTestResponse(http.StatusOK, `{"message":"Success.","isSuccess":true}`), nil
+	})
+
+	run := func(args ...string) {
+		t.Helper()
+		requests = nil
+		out.Reset()
+
+		if got := app.Execute(append(args, auth...)); got != ExitNormal {
+			t.Fatalf("%v: want %d, but %d: %s", args, ExitNormal, got, errOut.String())
+		}
+	}
+
+	t.Run("record and backfill", func(t *testing.T) {
+		repo := newTestGitRepo(t,
+			[2]string{"alice", "2019-01-01T10:00:00+09:00"},
+			[2]string{"bob", "2019-01-01T20:00:00+09:00"},
+			[2]string{"alice", "2019-01-02T01:00:00+09:00"},
+		)
+
+		tests := []struct {
+			name     string
+			args     []string
+			out      string
+			requests []string
+		}{
+			{
+				name:     "record HEAD",
+				args:     []string{"git", "record", "commits", "--repo", repo},
+				out:      "20190102 +1\n1 commits are recorded (0 commits are already recorded)\n",
+				requests: []string{`POST /v1/users/testuser/graphs/commits {"date":"20190102","quantity":"1"}`},
+			},
+			{
+				name: "record again",
+				args: []string{"git", "record", "commits", "--repo", repo},
+				out:  "0 commits are recorded (1 commits are already recorded)\n",
+			},
+			{
+				name: "backfill dry run",
+				args: []string{"git", "backfill", "commits", "--repo", repo, "--dry-run"},
+				out:  "20190101 +2\n2 commits will be recorded (1 commits are already recorded)\n",
+			},
+			{
+				name:     "backfill author",
+				args:     []string{"git", "backfill", "commits", "--repo", repo, "--author", "alice"},
+				out:      "20190101 +1\n1 commits are recorded (1 commits are already recorded)\n",
+				requests: []string{`PUT /v1/users/testuser/graphs/commits/20190101 {"date":"","quantity":"11"}`},
+			},
+			{
+				name:     "backfill",
+				args:     []string{"git", "backfill", "commits", "--repo", repo},
+				out:      "20190101 +1\n1 commits are recorded (2 commits are already recorded)\n",
+				requests: []string{`PUT /v1/users/testuser/graphs/commits/20190101 {"date":"","quantity":"11"}`},
+			},
+		}
+
+		for _, tt := range tests {
+			run(tt.args...)
+
+			if out.String() != tt.out {
+				t.Errorf("%s: want %q, but %q", tt.name, tt.out, out.String())
+			}
+
+			if !reflect.DeepEqual(requests, tt.requests) {
+				t.Errorf("%s: want %v, but %v", tt.name, tt.requests, requests)
+			}
+		}
+
+		// amended (and rebased) commit keeps changes (patch ID)
+		testGit(t, repo, "2019-01-03T09:00:00+09:00", "-c", "user.name=alice", "-c", "user.email=alice@example.com", "commit", "-q", "--amend", "-m", "amended")
+		run("git", "record", "commits", "--repo", repo)
+		run("git", "backfill", "commits", "--repo", repo)
+
+		if len(requests) != 0 {
+			t.Errorf("amended commit is recorded: %v", requests)
+		}
+
+		// distinct commits of the same author in the same second are counted
+		testGitCommit(t, repo, "alice", "2019-01-03T09:00:00+09:00", "first")
+		testGitCommit(t, repo, "alice", "2019-01-03T09:00:00+09:00", "second")
+		run("git", "backfill", "commits", "--repo", repo)
+
+		if want := "20190103 +2\n2 commits are recorded (3 commits are already recorded)\n"; out.String() != want {
+			t.Errorf("want %q, but %q", want, out.String())
+		}
+	})
+
+	t.Run("install hook", func(t *testing.T) {
+		repo := newTestGitRepo(t)
+		hooks := filepath.Join(repo, ".git", "hooks")
+		existing := "#!/bin/sh\necho existing\n"
+
+		if err := os.MkdirAll(hooks, 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filepath.Join(hooks, "post-commit"), []byte(existing), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		run("git", "install-hook", "commits", "--repo", repo)
+
+		// record line of older version is replaced
+		hookPath := filepath.Join(hooks, "post-commit")
+		old, _ := os.ReadFile(hookPath)
+		replaced := regexp.MustCompile(`(?m)^(.* git record 'commits') .*$`).ReplaceAll(old, []byte("$1 || true"))
+
+		if err := os.WriteFile(hookPath, replaced, 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		run("git", "install-hook", "commits", "--repo", repo)
+		run("git", "install-hook", "commits", "--repo", repo)
+
+		if !strings.Contains(out.String(), "already installed") {
+			t.Errorf("unexpected output: %s", out.String())
+		}
+
+		run("git", "install-hook", "focus", "--repo", repo)
+
+		chained, err := os.ReadFile(filepath.Join(hooks, gitChainedHook))
+
+		if err != nil || string(chained) != existing {
+			t.Fatalf("existing hook is not chained: %v: %q", err, chained)
+		}
+
+		hook, err := os.ReadFile(filepath.Join(hooks, "post-commit"))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, want := range []string{gitHookMarker, gitChainedHook, " git record 'commits' || echo ", " git record 'focus' || echo ", "`pixela git backfill`"} {
+			if !strings.Contains(string(hook), want) {
+				t.Errorf("hook does not contain %q:\n%s", want, hook)
+			}
+		}
+
+		if strings.Count(string(hook), " git record ") != 2 {
+			t.Errorf("unexpected hook:\n%s", hook)
+		}
+
+		if got := app.Execute(append([]string{"git", "install-hook", "commits", "--repo", t.TempDir()}, auth...)); got != ExitUsage {
+			t.Errorf("want %d for directory which is not repository, but %d", ExitUsage, got)
+		}
+	})
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"graph", "'graph'"},
+		{"/path/with space/pixela", "'/path/with space/pixela'"},
+		{"it's", `'it'\''s'`},
+	}
+
+	for _, tt := range tests {
+		if got := shellQuote(tt.in); got != tt.want {
+			t.Errorf("%s: want %s, but %s", tt.in, tt.want, got)
+		}
+	}
+}
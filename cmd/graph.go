@@ -1,17 +1,15 @@
 package cmd
 
 import (
-	"encoding/json"
-	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 
 	"github.com/noissefnoc/pixela-client-go/pixela"
 )
 
-func newGraphCmd() *cobra.Command {
+func (a *App) newGraphCmd() *cobra.Command {
 	// graphCmd represents the graph command
 	graphCmd := &cobra.Command{
 		Use:   "graph",
@@ -24,19 +22,26 @@ see official document (https://docs.pixe.la) for more detail`,
 		},
 	}
 
-	graphCmd.AddCommand(newGraphCreateCmd())
-	graphCmd.AddCommand(newGraphUpdateCmd())
-	graphCmd.AddCommand(newGraphDeleteCmd())
-	graphCmd.AddCommand(newGraphDefCmd())
-	graphCmd.AddCommand(newGraphSvgCmd())
-	graphCmd.AddCommand(newGraphPixelsDateCmd())
-	graphCmd.AddCommand(newGraphDetailURLCmd())
-	graphCmd.AddCommand(newGraphStatCmd())
+	graphCmd.AddCommand(a.newGraphCreateCmd())
+	graphCmd.AddCommand(a.newGraphUpdateCmd())
+	graphCmd.AddCommand(a.newGraphDeleteCmd())
+	graphCmd.AddCommand(a.newGraphDefCmd())
+	graphCmd.AddCommand(a.newGraphSvgCmd())
+	graphCmd.AddCommand(a.newGraphShowCmd())
+	graphCmd.AddCommand(a.newGraphRenderCmd())
+	graphCmd.AddCommand(a.newGraphPixelsDateCmd())
+	graphCmd.AddCommand(a.newGraphExportCmd())
+	graphCmd.AddCommand(a.newGraphImportCmd())
+	graphCmd.AddCommand(a.newGraphCloneCmd())
+	graphCmd.AddCommand(a.newGraphMigrateTypeCmd())
+	graphCmd.AddCommand(a.newGraphDetailURLCmd())
+	graphCmd.AddCommand(a.newGraphStatCmd())
+	graphCmd.AddCommand(a.newGraphTimezonesCmd())
 
 	return graphCmd
 }
 
-func newGraphCreateCmd() *cobra.Command {
+func (a *App) newGraphCreateCmd() *cobra.Command {
 	graphCreateCmd := &cobra.Command{
 		Use:   "create",
 		Short: "create pixe.la graph.",
@@ -45,13 +50,14 @@ func newGraphCreateCmd() *cobra.Command {
 $ pixela graph create <graph id> <graph name> <unit> <type> <color> [--timezone timezone] [--selfSufficient selfSufficient]
 
 see official document (https://docs.pixe.la/#/post-graph) for more detail.`,
+		ValidArgsFunction: completePositional(nil, nil, nil, completeValues(stringValues(pixela.NumTypes())...), completeValues(stringValues(pixela.Colors())...)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 5 {
-				return fmt.Errorf("argument error: `graph create` requires 5 arguments give %d arguments", len(args))
+				return usageErrorf("argument error: `graph create` requires 5 arguments give %d arguments", len(args))
 			}
 
 			// make request
-			client, err := pixela.New(viper.GetString("username"), viper.GetString("token"), viper.GetBool("verbose"))
+			client, err := a.newClient()
 
 			if err != nil {
 				return err
@@ -60,35 +66,35 @@ see official document (https://docs.pixe.la/#/post-graph) for more detail.`,
 			timezone, _ := cmd.Flags().GetString("timezone")
 			selfSufficient, _ := cmd.Flags().GetString("selfSufficient")
 
-			// do request
-			response, err := client.CreateGraph(args[0], args[1], args[2], args[3], args[4], timezone, selfSufficient)
+			err = a.checkTimezone(timezone)
 
 			if err != nil {
-				return errors.Wrap(err, "request error: ")
+				return err
 			}
 
-			responseJSON, err := json.Marshal(response)
+			// do request
+			response, err := client.CreateGraph(args[0], args[1], args[2], args[3], args[4], timezone, selfSufficient)
 
 			if err != nil {
-				return errors.Wrap(err, "response parse error: ")
+				return errors.Wrap(err, "request error: ")
 			}
 
-			// print result in verbose mode
-			if viper.GetBool("verbose") {
-				cui.Outputln(string(responseJSON))
-			}
+			a.clearCompletionCache()
 
-			return nil
+			return a.printResult(response)
 		},
 	}
 
 	graphCreateCmd.Flags().StringP("timezone", "", "", "timezone")
 	graphCreateCmd.Flags().StringP("selfSufficient", "", "none", "selfSufficient")
 
+	graphCreateCmd.RegisterFlagCompletionFunc("timezone", completeTimezones)
+	graphCreateCmd.RegisterFlagCompletionFunc("selfSufficient", completeValues(stringValues(pixela.SelfSufficients())...))
+
 	return graphCreateCmd
 }
 
-func newGraphUpdateCmd() *cobra.Command {
+func (a *App) newGraphUpdateCmd() *cobra.Command {
 	graphUpdateCmd := &cobra.Command{
 		Use:   "update",
 		Short: "update graph definition",
@@ -97,15 +103,16 @@ func newGraphUpdateCmd() *cobra.Command {
 $ pixela graph update <graph id> [--name graph_name] [--unit graph_unit] [--color color_name] [--timezone timezone] [--purgeCacheURLs [url1, url2, ...]]
 
 see official document (https://docs.pixe.la/#/put-graph) for more detail.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// check arguments
 			// TODO: add timezone option later
 			if len(args) != 1 {
-				return fmt.Errorf("argument error: `graph update` requires 1 arguments give %d arguments", len(args))
+				return usageErrorf("argument error: `graph update` requires 1 arguments give %d arguments", len(args))
 			}
 
 			// do request
-			client, err := pixela.New(viper.GetString("username"), viper.GetString("token"), viper.GetBool("verbose"))
+			client, err := a.newClient()
 
 			if err != nil {
 				return err
@@ -121,6 +128,12 @@ see official document (https://docs.pixe.la/#/put-graph) for more detail.`,
 				return err
 			}
 
+			err = a.checkTimezone(timezone)
+
+			if err != nil {
+				return err
+			}
+
 			pl := pixela.UpdateGraphPayload{
 				Name:           name,
 				Unit:           unit,
@@ -135,31 +148,25 @@ see official document (https://docs.pixe.la/#/put-graph) for more detail.`,
 				return errors.Wrap(err, "request error: ")
 			}
 
-			responseJSON, err := json.Marshal(response)
-
-			if err != nil {
-				return errors.Wrap(err, "response parse error: ")
-			}
-
-			// print result in verbose mode
-			if viper.GetBool("verbose") {
-				cui.Outputln(string(responseJSON))
-			}
+			a.clearCompletionCache()
 
-			return nil
+			return a.printResult(response)
 		},
 	}
 
-	graphUpdateCmd.Flags().StringP("name", "n", "", "graph name")
-	graphUpdateCmd.Flags().StringP("unit", "u", "", "graph unit")
+	graphUpdateCmd.Flags().StringP("name", "", "", "graph name")
+	graphUpdateCmd.Flags().StringP("unit", "", "", "graph unit")
 	graphUpdateCmd.Flags().StringP("color", "c", "", "graph color (shibafu/momiji/sora/ichou/ajisai/kuro)")
-	graphUpdateCmd.Flags().StringP("timezone", "t", "", "graph timezone")
+	graphUpdateCmd.Flags().StringP("timezone", "", "", "graph timezone")
 	graphUpdateCmd.Flags().StringArrayP("purge", "p", nil, "purge cache urls")
 
+	graphUpdateCmd.RegisterFlagCompletionFunc("color", completeValues(stringValues(pixela.Colors())...))
+	graphUpdateCmd.RegisterFlagCompletionFunc("timezone", completeTimezones)
+
 	return graphUpdateCmd
 }
 
-func newGraphDeleteCmd() *cobra.Command {
+func (a *App) newGraphDeleteCmd() *cobra.Command {
 	graphDeleteCmd := &cobra.Command{
 		Use:   "delete",
 		Short: "delete graph",
@@ -168,14 +175,15 @@ func newGraphDeleteCmd() *cobra.Command {
 $ pixela graph delete <graph id>
 
 see official document (https://docs.pixe.la/#/delete-graph) for more detail.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// check arguments
 			if len(args) != 1 {
-				return fmt.Errorf("argument error: `graph delete` requires 1 arguments give %d arguments", len(args))
+				return usageErrorf("argument error: `graph delete` requires 1 arguments give %d arguments", len(args))
 			}
 
 			// do request
-			client, err := pixela.New(viper.GetString("username"), viper.GetString("token"), viper.GetBool("verbose"))
+			client, err := a.newClient()
 
 			if err != nil {
 				return err
@@ -187,25 +195,16 @@ see official document (https://docs.pixe.la/#/delete-graph) for more detail.`,
 				return errors.Wrap(err, "request error: ")
 			}
 
-			responseJSON, err := json.Marshal(response)
-
-			if err != nil {
-				return errors.Wrap(err, "response parse error: ")
-			}
-
-			// print result in verbose mode
-			if viper.GetBool("verbose") {
-				cui.Outputln(string(responseJSON))
-			}
+			a.clearCompletionCache()
 
-			return nil
+			return a.printResult(response)
 		},
 	}
 
 	return graphDeleteCmd
 }
 
-func newGraphDefCmd() *cobra.Command {
+func (a *App) newGraphDefCmd() *cobra.Command {
 	graphDefinitionCmd := &cobra.Command{
 		Use:   "get",
 		Short: "get graph definitions",
@@ -217,11 +216,11 @@ see official document (https://docs.pixe.la/#/get-graph) for more detail.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// check arguments
 			if len(args) != 0 {
-				return errors.New("argument error: `graph get` does not accept any argument")
+				return usageErrorf("argument error: `graph get` does not accept any argument")
 			}
 
 			// do request
-			client, err := pixela.New(viper.GetString("username"), viper.GetString("token"), viper.GetBool("verbose"))
+			client, err := a.newClient()
 
 			if err != nil {
 				return err
@@ -233,23 +232,14 @@ see official document (https://docs.pixe.la/#/get-graph) for more detail.`,
 				return errors.Wrap(err, "request error: ")
 			}
 
-			responseJSON, err := json.Marshal(response)
-
-			if err != nil {
-				return errors.Wrap(err, "response parse error: ")
-			}
-
-			// print result
-			cui.Outputln(string(responseJSON))
-
-			return nil
+			return a.printOutput(response)
 		},
 	}
 
 	return graphDefinitionCmd
 }
 
-func newGraphSvgCmd() *cobra.Command {
+func (a *App) newGraphSvgCmd() *cobra.Command {
 	graphSvgCmd := &cobra.Command{
 		Use:   "svg",
 		Short: "get graph SVG HTML tag",
@@ -257,15 +247,18 @@ func newGraphSvgCmd() *cobra.Command {
 
 $ pixela graph svg <graph id> [--date yyyyMMdd] [--mode short/line]
 
+date accepts yyyyMMdd, yyyy-MM-dd, today, yesterday, -3d, 3 days ago and last friday.
+
 see official document (https://docs.pixe.la/#/get-svg) for more detail.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// check arguments
 			if len(args) != 1 {
-				return fmt.Errorf("argument error: `graph svg` requires 1 arguments give %d arguments", len(args))
+				return usageErrorf("argument error: `graph svg` requires 1 arguments give %d arguments", len(args))
 			}
 
 			// do request
-			client, err := pixela.New(viper.GetString("username"), viper.GetString("token"), viper.GetBool("verbose"))
+			client, err := a.newClient()
 
 			if err != nil {
 				return err
@@ -274,6 +267,12 @@ see official document (https://docs.pixe.la/#/get-svg) for more detail.`,
 			dateStr, _ := cmd.Flags().GetString("date")
 			mode, _ := cmd.Flags().GetString("mode")
 
+			dateStr, err = a.resolveOptionalDate(client, args[0], dateStr)
+
+			if err != nil {
+				return err
+			}
+
 			response, err := client.GetGraphSvg(args[0], dateStr, mode)
 
 			if err != nil {
@@ -281,19 +280,19 @@ see official document (https://docs.pixe.la/#/get-svg) for more detail.`,
 			}
 
 			// print result
-			cui.Outputln(string(response))
-
-			return nil
+			return a.printOutput(string(response))
 		},
 	}
 
 	graphSvgCmd.Flags().StringP("date", "", "", "date")
 	graphSvgCmd.Flags().StringP("mode", "", "", "mode")
 
+	graphSvgCmd.RegisterFlagCompletionFunc("mode", completeValues("short", "line"))
+
 	return graphSvgCmd
 }
 
-func newGraphPixelsDateCmd() *cobra.Command {
+func (a *App) newGraphPixelsDateCmd() *cobra.Command {
 	graphPixelsDateCmd := &cobra.Command{
 		Use:   "pixels",
 		Short: "get graph pixels list",
@@ -301,15 +300,18 @@ func newGraphPixelsDateCmd() *cobra.Command {
 
 $ pixela graph pixels <graph id> [--from yyyyMMdd] [--to yyyyMMdd]
 
+from and to accept yyyyMMdd, yyyy-MM-dd, today, yesterday, -3d, 3 days ago and last friday.
+
 see official document (https://docs.pixe.la/#/get-graph-pixels) for more detail.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// check arguments
 			if len(args) != 1 {
-				return fmt.Errorf("argument error: `graph pixels` requires 1 argument give %d arguments", len(args))
+				return usageErrorf("argument error: `graph pixels` requires 1 argument give %d arguments", len(args))
 			}
 
 			// do request
-			client, err := pixela.New(viper.GetString("username"), viper.GetString("token"), viper.GetBool("verbose"))
+			client, err := a.newClient()
 
 			if err != nil {
 				return err
@@ -318,32 +320,111 @@ see official document (https://docs.pixe.la/#/get-graph-pixels) for more detail.
 			from, _ := cmd.Flags().GetString("from")
 			to, _ := cmd.Flags().GetString("to")
 
+			if from, err = a.resolveOptionalDate(client, args[0], from); err != nil {
+				return err
+			}
+
+			if to, err = a.resolveOptionalDate(client, args[0], to); err != nil {
+				return err
+			}
+
 			response, err := client.GetGraphPixelsDateList(args[0], from, to)
 
 			if err != nil {
 				return errors.Wrap(err, "request error: ")
 			}
 
-			responseJSON, err := json.Marshal(response)
+			return a.printOutput(response)
+		},
+	}
+
+	graphPixelsDateCmd.Flags().StringP("from", "", "", "from")
+	graphPixelsDateCmd.Flags().StringP("to", "", "", "to")
+
+	return graphPixelsDateCmd
+}
+
+func (a *App) newGraphExportCmd() *cobra.Command {
+	graphExportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "export graph pixels to CSV/JSON/NDJSON",
+		Long: `export graph pixels (date, quantity and optionalData) to CSV/JSON/NDJSON. Usage:
+
+$ pixela graph export <graph id> [--from yyyyMMdd] [--to yyyyMMdd] [--format csv/json/ndjson] [--flatten] [--with-definition]
+
+from and to accept yyyyMMdd, yyyy-MM-dd, today, yesterday, -3d, 3 days ago and last friday.
+--flatten expands optionalData keys into optionalData.<key> columns.
+--with-definition writes graph definition as comment lines (CSV) or graph field (JSON/NDJSON).
+
+see official document (https://docs.pixe.la/#/get-graph-pixels) for more detail.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 1 {
+				return usageErrorf("argument error: `graph export` requires 1 argument give %d arguments", len(args))
+			}
+
+			formatStr, _ := cmd.Flags().GetString("format")
+			format, err := pixela.ParseExportFormat(formatStr)
 
 			if err != nil {
-				return errors.Wrap(err, "response parse error: ")
+				return wrapUsageError(err, "argument error")
 			}
 
-			// print result
-			cui.Outputln(string(responseJSON))
+			opts := pixela.ExportOptions{Format: format}
+			opts.FlattenOptionalData, _ = cmd.Flags().GetBool("flatten")
+			withDefinition, _ := cmd.Flags().GetBool("with-definition")
 
-			return nil
+			// do request
+			client, err := a.newClient()
+
+			if err != nil {
+				return err
+			}
+
+			from, _ := cmd.Flags().GetString("from")
+			to, _ := cmd.Flags().GetString("to")
+
+			if from, err = a.resolveOptionalDate(client, args[0], from); err != nil {
+				return err
+			}
+
+			if to, err = a.resolveOptionalDate(client, args[0], to); err != nil {
+				return err
+			}
+
+			if withDefinition {
+				graph, err := client.LookupGraph(args[0])
+
+				if err != nil {
+					return errors.Wrap(err, "request error")
+				}
+
+				opts.Graph = &graph
+			}
+
+			pixels, err := a.graphPixelsInRange(client, args[0], from, to)
+
+			if err != nil {
+				return errors.Wrap(err, "request error")
+			}
+
+			return pixela.WritePixels(a.ui.Writer(), pixels, opts)
 		},
 	}
 
-	graphPixelsDateCmd.Flags().StringP("from", "", "", "from")
-	graphPixelsDateCmd.Flags().StringP("to", "", "", "to")
+	graphExportCmd.Flags().String("from", "", "from")
+	graphExportCmd.Flags().String("to", "", "to")
+	graphExportCmd.Flags().String("format", pixela.ExportCSV.String(), "export format (csv/json/ndjson)")
+	graphExportCmd.Flags().Bool("flatten", false, "expand optionalData keys into columns")
+	graphExportCmd.Flags().Bool("with-definition", false, "include graph definition")
 
-	return graphPixelsDateCmd
+	graphExportCmd.RegisterFlagCompletionFunc("format", completeValues(stringValues(pixela.ExportFormats())...))
+
+	return graphExportCmd
 }
 
-func newGraphDetailURLCmd() *cobra.Command {
+func (a *App) newGraphDetailURLCmd() *cobra.Command {
 	graphDetailURLCmd := &cobra.Command{
 		Use:   "detail",
 		Short: "get graph detail URL",
@@ -352,14 +433,15 @@ func newGraphDetailURLCmd() *cobra.Command {
 $ pixela graph detail <graph id>
 
 see official document (https://docs.pixe.la/#/get-graph-html) for more detail.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// check arguments
 			if len(args) != 1 {
-				return fmt.Errorf("argument error: `graph detail` requires 1 argument give %d arguments", len(args))
+				return usageErrorf("argument error: `graph detail` requires 1 argument give %d arguments", len(args))
 			}
 
 			// do request
-			client, err := pixela.New(viper.GetString("username"), viper.GetString("token"), viper.GetBool("verbose"))
+			client, err := a.newClient()
 
 			if err != nil {
 				return err
@@ -368,16 +450,14 @@ see official document (https://docs.pixe.la/#/get-graph-html) for more detail.`,
 			response := client.GetGraphDetailURL(args[0])
 
 			// print result
-			cui.Outputln(response)
-
-			return nil
+			return a.printOutput(response)
 		},
 	}
 
 	return graphDetailURLCmd
 }
 
-func newGraphStatCmd() *cobra.Command {
+func (a *App) newGraphStatCmd() *cobra.Command {
 	graphStatCmd := &cobra.Command{
 		Use:   "stat",
 		Short: "get graph stat",
@@ -386,14 +466,15 @@ func newGraphStatCmd() *cobra.Command {
 $ pixela graph stat <graph id>
 
 see official document (https://docs.pixe.la/#/get-graph-stat) for more detail.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// check arguments
 			if len(args) != 1 {
-				return fmt.Errorf("argument error: `graph stat` requires 1 argument give %d arguments", len(args))
+				return usageErrorf("argument error: `graph stat` requires 1 argument give %d arguments", len(args))
 			}
 
 			// do request
-			client, err := pixela.New(viper.GetString("username"), viper.GetString("token"), viper.GetBool("verbose"))
+			client, err := a.newClient()
 
 			if err != nil {
 				return err
@@ -405,18 +486,99 @@ see official document (https://docs.pixe.la/#/get-graph-stat) for more detail.`,
 				return err
 			}
 
-			responseJSON, err := json.Marshal(response)
+			return a.printOutput(response)
+		},
+	}
 
-			if err != nil {
-				return errors.Wrap(err, "response parse error: ")
+	return graphStatCmd
+}
+
+func (a *App) newGraphTimezonesCmd() *cobra.Command {
+	graphTimezonesCmd := &cobra.Command{
+		Use:   "timezones",
+		Short: "list timezones available for graph",
+		Long: `list timezones (tz database zone names) available for graph. Usage:
+
+$ pixela graph timezones [query] [--limit n]
+
+When query is given, zone names similar to query are listed (fuzzy matching).`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) > 1 {
+				return usageErrorf("argument error: `graph timezones` accepts at most 1 argument give %d arguments", len(args))
 			}
 
-			// print result
-			cui.Outputln(string(responseJSON))
+			if len(args) == 1 {
+				limit, _ := cmd.Flags().GetInt("limit")
 
-			return nil
+				return a.printOutput(pixela.SuggestTimezones(args[0], limit))
+			}
+
+			names, err := pixela.TimezoneNames()
+
+			if err != nil {
+				return err
+			}
+
+			return a.printOutput(names)
 		},
 	}
 
-	return graphStatCmd
+	graphTimezonesCmd.Flags().IntP("limit", "", 10, "max number of suggestions")
+
+	return graphTimezonesCmd
+}
+
+// check timezone argument and suggest similar zone names when it is wrong
+func (a *App) checkTimezone(timezone string) error {
+	mode, err := a.validationMode()
+
+	if err != nil {
+		return err
+	}
+
+	if len(timezone) == 0 || mode != pixela.ValidationStrict {
+		return nil
+	}
+
+	if _, err := pixela.NormalizeTimezone(timezone); err != nil {
+		suggestions := pixela.SuggestTimezones(timezone, 3)
+
+		if len(suggestions) == 0 {
+			return wrapUsageError(err, "argument error")
+		}
+
+		return usageErrorf("argument error: unknown timezone `%s`. Did you mean `%s`? (see `pixela graph timezones`)",
+			timezone, strings.Join(suggestions, "`, `"))
+	}
+
+	return nil
+}
+
+// pixels from `from` to `to` by 365 days windows. every pixel is fetched without them,
+// and pixels until tomorrow (UTC, ahead of any graph timezone) are fetched without `to`.
+func (a *App) graphPixelsInRange(client *pixela.Pixela, graphID, from, to string) ([]pixela.PixelRecord, error) {
+	if from != "" {
+		if to == "" {
+			to = a.now().UTC().AddDate(0, 0, 1).Format(pixela.DateFormat)
+		}
+
+		return client.GetGraphPixelsInRange(graphID, from, to)
+	}
+
+	pixels, err := client.GetAllGraphPixels(graphID)
+
+	if err != nil || to == "" {
+		return pixels, err
+	}
+
+	filtered := pixels[:0]
+
+	for _, p := range pixels {
+		if p.Date <= to {
+			filtered = append(filtered, p)
+		}
+	}
+
+	return filtered, nil
 }
@@ -0,0 +1,397 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+func (a *App) newGraphImportCmd() *cobra.Command {
+	graphImportCmd := &cobra.Command{
+		Use:   "import",
+		Short: "import graph pixels from CSV/JSON/NDJSON",
+		Long: `import graph pixels (date, quantity and optionalData) from CSV/JSON/NDJSON file. Usage:
+
+$ pixela graph import <graph id> <file> [--format csv/json/ndjson] [--mode skip-existing/overwrite/add] [--dry-run]
+
+file is read from stdin when it is "-". format is guessed from file extension (csv by default).
+files written by "graph export" can be imported as they are. other files can be mapped by
+--date-column, --quantity-column and --optional-data-column.
+
+every row is validated before any request is sent. --dry-run shows changes against existing pixels:
+
+  + created pixel
+  ~ updated pixel
+    skipped pixel
+
+imported dates are recorded in checkpoint file (<file>.checkpoint by default) which is removed when
+import succeeds. interrupted import can be resumed by --resume.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 2 {
+				return usageErrorf("argument error: `graph import` requires 2 arguments give %d arguments", len(args))
+			}
+
+			graphID, file := args[0], args[1]
+
+			format, err := importFormat(cmd, file)
+
+			if err != nil {
+				return err
+			}
+
+			modeStr, _ := cmd.Flags().GetString("mode")
+			mode, err := pixela.ParseImportMode(modeStr)
+
+			if err != nil {
+				return wrapUsageError(err, "argument error")
+			}
+
+			mapping := pixela.DefaultImportMapping()
+			mapping.Date, _ = cmd.Flags().GetString("date-column")
+			mapping.Quantity, _ = cmd.Flags().GetString("quantity-column")
+			mapping.OptionalData, _ = cmd.Flags().GetString("optional-data-column")
+
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			resume, _ := cmd.Flags().GetBool("resume")
+			checkpointPath, _ := cmd.Flags().GetString("checkpoint")
+
+			if checkpointPath == "" && file != "-" {
+				checkpointPath = file + ".checkpoint"
+			}
+
+			// read and validate all rows
+			pixels, err := a.readImportFile(file, format, mapping)
+
+			if err != nil {
+				return err
+			}
+
+			client, err := a.newClient()
+
+			if err != nil {
+				return err
+			}
+
+			if err := client.ValidatePixels(graphID, pixels); err != nil {
+				return err
+			}
+
+			// skip pixels imported before interruption
+			checkpoint, err := loadImportCheckpoint(checkpointPath, graphID, mode)
+
+			if err != nil {
+				return err
+			}
+
+			if checkpoint != nil && !resume && !dryRun {
+				return usageErrorf("argument error: checkpoint %s exists (use --resume to continue or remove it)", checkpointPath)
+			}
+
+			if checkpoint != nil && resume {
+				pixels = checkpoint.remaining(pixels)
+			}
+
+			// compare with existing pixels
+			existing, err := a.existingPixels(client, graphID, pixels)
+
+			if err != nil {
+				return err
+			}
+
+			changes, err := pixela.PlanImport(pixels, existing, mode)
+
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				return a.printImportChanges(changes)
+			}
+
+			if checkpoint == nil {
+				checkpoint = &importCheckpoint{path: checkpointPath, GraphID: graphID, Mode: mode}
+			}
+
+			if err := checkpoint.open(); err != nil {
+				return err
+			}
+
+			err = client.ApplyImport(graphID, changes, func(change pixela.ImportChange) error {
+				return checkpoint.record(change.New.Date)
+			})
+
+			if closeErr := checkpoint.close(); err == nil {
+				err = closeErr
+			}
+
+			if err != nil {
+				if checkpoint.path != "" {
+					a.ui.OutputErrln(fmt.Sprintf("import is interrupted. run again with --resume to continue (checkpoint: %s)", checkpoint.path))
+				}
+
+				return errors.Wrap(err, "request error")
+			}
+
+			a.printStatus(importSummary(changes))
+
+			return checkpoint.remove()
+		},
+	}
+
+	graphImportCmd.Flags().String("format", "", "import format (csv/json/ndjson, default is guessed from file extension)")
+	graphImportCmd.Flags().String("mode", pixela.ImportSkipExisting.String(), "behavior for existing pixels (skip-existing/overwrite/add)")
+	graphImportCmd.Flags().String("date-column", "date", "column (key) name of date")
+	graphImportCmd.Flags().String("quantity-column", "quantity", "column (key) name of quantity")
+	graphImportCmd.Flags().String("optional-data-column", "optionalData", "column (key) name of optionalData")
+	graphImportCmd.Flags().Bool("dry-run", false, "show changes without sending requests")
+	graphImportCmd.Flags().String("checkpoint", "", "checkpoint file (default is <file>.checkpoint)")
+	graphImportCmd.Flags().Bool("resume", false, "resume interrupted import from checkpoint file")
+
+	graphImportCmd.RegisterFlagCompletionFunc("format", completeValues(stringValues(pixela.ExportFormats())...))
+	graphImportCmd.RegisterFlagCompletionFunc("mode", completeValues(stringValues(pixela.ImportModes())...))
+
+	return graphImportCmd
+}
+
+// format by flag or file extension
+func importFormat(cmd *cobra.Command, file string) (pixela.ExportFormat, error) {
+	formatStr, _ := cmd.Flags().GetString("format")
+
+	if formatStr == "" {
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".json":
+			formatStr = pixela.ExportJSON.String()
+		case ".ndjson", ".jsonl":
+			formatStr = pixela.ExportNDJSON.String()
+		default:
+			formatStr = pixela.ExportCSV.String()
+		}
+	}
+
+	format, err := pixela.ParseExportFormat(formatStr)
+
+	if err != nil {
+		return "", wrapUsageError(err, "argument error")
+	}
+
+	return format, nil
+}
+
+func (a *App) readImportFile(file string, format pixela.ExportFormat, mapping pixela.ImportMapping) ([]pixela.PixelRecord, error) {
+	var r io.Reader = a.ui.Reader()
+
+	if file != "-" {
+		f, err := os.Open(file)
+
+		if err != nil {
+			return nil, wrapUsageError(err, "input error")
+		}
+
+		defer f.Close()
+
+		r = f
+	}
+
+	pixels, err := pixela.ReadPixels(r, format, mapping)
+
+	if err != nil {
+		return nil, wrapUsageError(err, "input error")
+	}
+
+	return pixels, nil
+}
+
+// existing pixels of the dates of imported pixels (requested by 365 days windows)
+func (a *App) existingPixels(client *pixela.Pixela, graphID string, pixels []pixela.PixelRecord) ([]pixela.PixelRecord, error) {
+	if len(pixels) == 0 {
+		return nil, nil
+	}
+
+	dates := make([]string, 0, len(pixels))
+
+	for _, p := range pixels {
+		dates = append(dates, p.Date)
+	}
+
+	existing, err := client.GetGraphPixelsOfDates(graphID, dates)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "request error")
+	}
+
+	return existing, nil
+}
+
+// print changes as diff (or changes in `--output` format)
+func (a *App) printImportChanges(changes []pixela.ImportChange) error {
+	if a.viper.GetString("output") != "" {
+		return a.printOutput(changes)
+	}
+
+	for _, change := range changes {
+		switch change.Action {
+		case pixela.ImportCreate:
+			a.ui.Outputln(fmt.Sprintf("+ %s %s", change.New.Date, pixelSummary(change.New)))
+		case pixela.ImportUpdate:
+			a.ui.Outputln(fmt.Sprintf("~ %s %s -> %s", change.New.Date, pixelSummary(*change.Old), pixelSummary(change.New)))
+		case pixela.ImportSkip:
+			a.ui.Outputln(fmt.Sprintf("  %s %s", change.New.Date, pixelSummary(change.New)))
+		}
+	}
+
+	a.ui.Outputln(importSummary(changes) + " (dry run)")
+
+	return nil
+}
+
+func pixelSummary(p pixela.PixelRecord) string {
+	if p.OptionalData == "" {
+		return p.Quantity
+	}
+
+	return fmt.Sprintf("%s %s", p.Quantity, p.OptionalData)
+}
+
+func importSummary(changes []pixela.ImportChange) string {
+	count := map[pixela.ImportAction]int{}
+
+	for _, change := range changes {
+		count[change.Action]++
+	}
+
+	return fmt.Sprintf("%d created, %d updated, %d skipped", count[pixela.ImportCreate], count[pixela.ImportUpdate], count[pixela.ImportSkip])
+}
+
+// importCheckpoint is file recording imported dates.
+// first line is JSON header (graph id and mode) and following lines are imported dates.
+type importCheckpoint struct {
+	path string
+	file *os.File
+	done map[string]bool
+
+	GraphID string            `json:"graphID"`
+	Mode    pixela.ImportMode `json:"mode"`
+}
+
+// load checkpoint (nil when it does not exist)
+func loadImportCheckpoint(path, graphID string, mode pixela.ImportMode) (*importCheckpoint, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(path)
+
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	checkpoint := &importCheckpoint{path: path, done: map[string]bool{}}
+	scanner := bufio.NewScanner(f)
+
+	if !scanner.Scan() || json.Unmarshal(scanner.Bytes(), checkpoint) != nil {
+		return nil, usageErrorf("input error: checkpoint %s is broken", path)
+	}
+
+	if checkpoint.GraphID != graphID || checkpoint.Mode != mode {
+		return nil, usageErrorf("argument error: checkpoint %s is for graph `%s` with mode `%s`", path, checkpoint.GraphID, checkpoint.Mode)
+	}
+
+	for scanner.Scan() {
+		if date := strings.TrimSpace(scanner.Text()); date != "" {
+			checkpoint.done[date] = true
+		}
+	}
+
+	return checkpoint, scanner.Err()
+}
+
+// pixels which are not imported yet
+func (c *importCheckpoint) remaining(pixels []pixela.PixelRecord) []pixela.PixelRecord {
+	var remaining []pixela.PixelRecord
+
+	for _, p := range pixels {
+		if !c.done[p.Date] {
+			remaining = append(remaining, p)
+		}
+	}
+
+	return remaining
+}
+
+// open checkpoint for appending dates (header is written when it is new)
+func (c *importCheckpoint) open() error {
+	if c.path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(c.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+
+	if err != nil {
+		return err
+	}
+
+	c.file = f
+
+	if c.done != nil {
+		return nil
+	}
+
+	header, err := json.Marshal(c)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(f, "%s\n", header)
+
+	return err
+}
+
+func (c *importCheckpoint) record(date string) error {
+	if c.file == nil {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(c.file, date); err != nil {
+		return err
+	}
+
+	return c.file.Sync()
+}
+
+func (c *importCheckpoint) close() error {
+	if c.file == nil {
+		return nil
+	}
+
+	return c.file.Close()
+}
+
+func (c *importCheckpoint) remove() error {
+	if c.path == "" {
+		return nil
+	}
+
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+func TestApp_GraphImport(t *testing.T) {
+	auth := []string{"--username", "testuser", "--token", "testtoken"}
+	success := `{"message":"Success.","isSuccess":true}`
+
+	var posted []string
+	failOn := "20190103"
+
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/graphs") {
+			return newTestResponse(http.StatusOK, `{"graphs":[{"id":"graphid","type":"int"}]}`), nil
+		}
+
+		switch req.Method {
+		case http.MethodGet:
+			return newTestResponse(http.StatusOK, `{"pixels":[{"date":"20190102","quantity":"5"}]}`), nil
+		case http.MethodPost:
+			body, _ := ioutil.ReadAll(req.Body)
+
+			if strings.Contains(string(body), failOn) {
+				return newTestResponse(http.StatusServiceUnavailable, `{"message":"Please retry this request.","isSuccess":false}`), nil
+			}
+
+			posted = append(posted, string(body))
+
+			return newTestResponse(http.StatusOK, success), nil
+		}
+
+		t.Fatalf("unexpected request: %s %s", req.Method, req.URL)
+
+		return nil, nil
+	})
+
+	file := filepath.Join(t.TempDir(), "pixels.csv")
+	checkpoint := file + ".checkpoint"
+
+	if err := ioutil.WriteFile(file, []byte("date,quantity\n2019-01-01,1\n2019-01-02,2\n2019-01-03,3\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// dry run shows diff and sends no change
+	if got := app.Execute(append([]string{"graph", "import", "graphid", file, "--dry-run"}, auth...)); got != ExitNormal {
+		t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+	}
+
+	want := "+ 20190101 1\n  20190102 5\n+ 20190103 3\n2 created, 0 updated, 1 skipped (dry run)\n"
+
+	if out.String() != want || len(posted) != 0 {
+		t.Fatalf("want %q, but %q (posted %v)", want, out.String(), posted)
+	}
+
+	// interrupted import keeps checkpoint
+	if got := app.Execute(append([]string{"graph", "import", "graphid", file}, auth...)); got != ExitRetryable {
+		t.Fatalf("want %d, but %d: %s", ExitRetryable, got, errOut.String())
+	}
+
+	if _, err := os.Stat(checkpoint); err != nil {
+		t.Fatalf("want checkpoint, but %#v", err)
+	}
+
+	// import without --resume is refused
+	if got := app.Execute(append([]string{"graph", "import", "graphid", file}, auth...)); got != ExitUsage {
+		t.Fatalf("want %d, but %d: %s", ExitUsage, got, errOut.String())
+	}
+
+	// resumed import sends only remaining pixels and removes checkpoint
+	failOn = "never"
+
+	if got := app.Execute(append([]string{"graph", "import", "graphid", file, "--resume"}, auth...)); got != ExitNormal {
+		t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+	}
+
+	wantPosted := []string{`{"date":"20190101","quantity":"1"}`, `{"date":"20190103","quantity":"3"}`}
+
+	if !reflect.DeepEqual(posted, wantPosted) {
+		t.Fatalf("want %v, but %v", wantPosted, posted)
+	}
+
+	if _, err := os.Stat(checkpoint); !os.IsNotExist(err) {
+		t.Fatalf("want checkpoint removed, but %#v", err)
+	}
+}
+
+func TestApp_GraphImportInvalidRows(t *testing.T) {
+	app, _, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		t.Fatalf("unexpected request: %s %s", req.Method, req.URL)
+
+		return nil, nil
+	})
+
+	file := filepath.Join(t.TempDir(), "pixels.ndjson")
+
+	if err := ioutil.WriteFile(file, []byte(`{"date":"20190101","quantity":"one"}`+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := app.Execute([]string{"graph", "import", "graphid", file, "--username", "testuser", "--token", "testtoken"}); got != ExitValidation {
+		t.Fatalf("want %d, but %d: %s", ExitValidation, got, errOut.String())
+	}
+
+	if !strings.Contains(errOut.String(), "row 1 (20190101)") {
+		t.Fatalf("want row number in error, but %s", errOut.String())
+	}
+}
+
+func TestApp_GraphImportOverYears(t *testing.T) {
+	auth := []string{"--username", "testuser", "--token", "testtoken"}
+	var ranges []string
+
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/graphs") {
+			return newTestResponse(http.StatusOK, `{"graphs":[{"id":"graphid","type":"int"}]}`), nil
+		}
+
+		from, to := req.URL.Query().Get("from"), req.URL.Query().Get("to")
+		ranges = append(ranges, from+"-"+to)
+
+		if from <= "20170101" && "20170101" <= to {
+			return newTestResponse(http.StatusOK, `{"pixels":[{"date":"20170101","quantity":"5"}]}`), nil
+		}
+
+		return newTestResponse(http.StatusOK, `{"pixels":[]}`), nil
+	})
+
+	file := filepath.Join(t.TempDir(), "pixels.csv")
+
+	if err := ioutil.WriteFile(file, []byte("date,quantity\n2017-01-01,1\n2019-01-01,2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := app.Execute(append([]string{"graph", "import", "graphid", file, "--dry-run"}, auth...)); got != ExitNormal {
+		t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+	}
+
+	// existing pixel older than one year is found
+	want := "  20170101 5\n+ 20190101 2\n1 created, 0 updated, 1 skipped (dry run)\n"
+
+	if out.String() != want {
+		t.Fatalf("want %q, but %q", want, out.String())
+	}
+
+	if wantRanges := []string{"20170101-20170101", "20190101-20190101"}; !reflect.DeepEqual(ranges, wantRanges) {
+		t.Fatalf("want %v, but %v", wantRanges, ranges)
+	}
+}
+
+func TestApp_GraphExportImport(t *testing.T) {
+	auth := []string{"--username", "testuser", "--token", "testtoken"}
+	var posted []string
+
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodPost:
+			body, _ := ioutil.ReadAll(req.Body)
+			posted = append(posted, req.URL.Path+" "+string(body))
+
+			return newTestResponse(http.StatusOK, `{"message":"Success.","isSuccess":true}`), nil
+		case strings.HasSuffix(req.URL.Path, "/graphs"):
+			return newTestResponse(http.StatusOK, `{"graphs":[{"id":"src","name":"source","unit":"km","type":"float","color":"sora","timezone":"Asia/Tokyo"},{"id":"dst","type":"float"}]}`), nil
+		case strings.HasSuffix(req.URL.Path, "/src/stats"):
+			return newTestResponse(http.StatusOK, `{"totalPixelsCount":3}`), nil
+		case strings.HasSuffix(req.URL.Path, "/src/pixels"):
+			// pixel older than 365 days is exported
+			return newTestPixelsResponse(t, req, []pixela.PixelRecord{
+				{Date: "20150101", Quantity: "3"},
+				{Date: "20190101", Quantity: "1.5", OptionalData: `{"key":"value, with comma"}`},
+				{Date: "20190102", Quantity: "2"},
+			}), nil
+		case strings.HasSuffix(req.URL.Path, "/dst/pixels"):
+			return newTestResponse(http.StatusOK, `{"pixels":[]}`), nil
+		}
+
+		t.Fatalf("unexpected request: %s %s", req.Method, req.URL)
+
+		return nil, nil
+	})
+
+	wantPosted := []string{
+		`/v1/users/testuser/graphs/dst {"date":"20150101","quantity":"3"}`,
+		`/v1/users/testuser/graphs/dst {"date":"20190101","quantity":"1.5","optionalData":"{\"key\":\"value, with comma\"}"}`,
+		`/v1/users/testuser/graphs/dst {"date":"20190102","quantity":"2"}`,
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"csv", []string{"--format", "csv"}},
+		{"csv flatten", []string{"--format", "csv", "--flatten"}},
+		{"csv with definition", []string{"--format", "csv", "--with-definition"}},
+		{"json with definition", []string{"--format", "json", "--with-definition"}},
+		{"ndjson", []string{"--format", "ndjson"}},
+		{"ndjson flatten", []string{"--format", "ndjson", "--flatten"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posted = nil
+			out.Reset()
+
+			if got := app.Execute(append(append([]string{"graph", "export", "src"}, tt.args...), auth...)); got != ExitNormal {
+				t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+			}
+
+			file := filepath.Join(t.TempDir(), "pixels."+tt.args[1])
+
+			if err := ioutil.WriteFile(file, out.Bytes(), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			// exported file is imported as it is
+			if got := app.Execute(append([]string{"graph", "import", "dst", file}, auth...)); got != ExitNormal {
+				t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+			}
+
+			if !reflect.DeepEqual(posted, wantPosted) {
+				t.Fatalf("want\n%v\nbut\n%v", wantPosted, posted)
+			}
+		})
+	}
+}
+
+func TestApp_GraphExportRange(t *testing.T) {
+	auth := []string{"--username", "testuser", "--token", "testtoken"}
+	pixels := []pixela.PixelRecord{{Date: "20150101", Quantity: "1"}, {Date: "20170101", Quantity: "2"}, {Date: "20190101", Quantity: "3"}}
+
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/stats") {
+			return newTestResponse(http.StatusOK, `{"totalPixelsCount":3}`), nil
+		}
+
+		return newTestPixelsResponse(t, req, pixels), nil
+	})
+
+	app.now = func() time.Time { return time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"all", nil, "20150101,1\n20170101,2\n20190101,3\n"},
+		{"from", []string{"--from", "20160101"}, "20170101,2\n20190101,3\n"},
+		{"to", []string{"--to", "20180101"}, "20150101,1\n20170101,2\n"},
+		{"from and to", []string{"--from", "20160101", "--to", "20180101"}, "20170101,2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out.Reset()
+
+			if got := app.Execute(append(append([]string{"graph", "export", "graphid", "--tz", "UTC"}, tt.args...), auth...)); got != ExitNormal {
+				t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+			}
+
+			if want := "date,quantity,optionalData\n" + strings.ReplaceAll(tt.want, "\n", ",\n"); out.String() != want {
+				t.Fatalf("want %q, but %q", want, out.String())
+			}
+		})
+	}
+}
+
+// pixels in `from` and `to` query like pe.la (last 365 days without them). range longer than 365 days fails the test.
+func newTestPixelsResponse(t *testing.T, req *http.Request, pixels []pixela.PixelRecord) *http.Response {
+	from, to := req.URL.Query().Get("from"), req.URL.Query().Get("to")
+	start, err := time.Parse(pixela.DateFormat, from)
+
+	if err != nil {
+		t.Fatalf("pixels are requested without range: %s", req.URL)
+	}
+
+	end, _ := time.Parse(pixela.DateFormat, to)
+
+	if end.Sub(start) >= 365*24*time.Hour {
+		t.Fatalf("range is longer than 365 days: %s", req.URL)
+	}
+
+	var found []pixela.PixelRecord
+
+	for _, p := range pixels {
+		if from <= p.Date && p.Date <= to {
+			found = append(found, p)
+		}
+	}
+
+	body, _ := json.Marshal(struct {
+		Pixels []pixela.PixelRecord `json:"pixels"`
+	}{found})
+
+	return newTestResponse(http.StatusOK, string(body))
+}
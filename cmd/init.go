@@ -0,0 +1,362 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+// length of token generated by `init`
+const generatedTokenLength = 32
+
+const termsOfServiceURL = "https://github.com/a-know/Pixela/wiki/Terms-of-Service"
+
+// initSettings are answers of `init` wizard
+type initSettings struct {
+	username       string
+	token          string
+	generatedToken bool
+	profile        string
+	useProfile     bool
+	graph          *pixela.CreateGraphRequest
+}
+
+func (a *App) newInitCmd() *cobra.Command {
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "create pixe.la user and profile interactively",
+		Long: `create pixe.la user, save it as profile in config file and create first graph (optional). Usage:
+
+$ pixela init
+
+non-interactive mode for automation:
+
+$ pixela init --non-interactive --username <username> [--token <token> | --generate-token] \
+    --agree-terms-of-service --not-minor [--profile name] \
+    [--graph-id id --graph-name name --graph-unit unit [--graph-type int/float] [--graph-color color] [--graph-timezone timezone]]
+
+username and token are also read from PIXELA_USERNAME and PIXELA_TOKEN.
+
+see official document (https://docs.pixe.la/#/post-user) and terms of service (` + termsOfServiceURL + `) for more detail.`,
+		Annotations: map[string]string{annotationSkipProfileCheck: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 0 {
+				return usageErrorf("argument error: `init` requires 0 arguments give %d arguments", len(args))
+			}
+
+			rules, err := a.validationRules()
+
+			if err != nil {
+				return err
+			}
+
+			nonInteractive, _ := cmd.Flags().GetBool("non-interactive")
+
+			var settings initSettings
+
+			if nonInteractive {
+				settings, err = a.initSettingsFromFlags(cmd, rules)
+			} else {
+				settings, err = a.askInitSettings(cmd, rules)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			return a.runInit(settings)
+		},
+	}
+
+	initCmd.Flags().Bool("non-interactive", false, "do not prompt (answers are given by flags)")
+	initCmd.Flags().Bool("generate-token", false, "generate token")
+	initCmd.Flags().Bool("agree-terms-of-service", false, "agree terms of service")
+	initCmd.Flags().Bool("not-minor", false, "usage is not minor")
+	initCmd.Flags().String("graph-id", "", "create first graph with the ID")
+	initCmd.Flags().String("graph-name", "", "first graph name")
+	initCmd.Flags().String("graph-unit", "", "first graph unit")
+	initCmd.Flags().String("graph-type", pixela.NumTypeInt.String(), "first graph type (int/float)")
+	initCmd.Flags().String("graph-color", pixela.ColorShibafu.String(), "first graph color (shibafu/momiji/sora/ichou/ajisai/kuro)")
+	initCmd.Flags().String("graph-timezone", "", "first graph timezone")
+
+	initCmd.RegisterFlagCompletionFunc("graph-type", completeValues(stringValues(pixela.NumTypes())...))
+	initCmd.RegisterFlagCompletionFunc("graph-color", completeValues(stringValues(pixela.Colors())...))
+	initCmd.RegisterFlagCompletionFunc("graph-timezone", completeTimezones)
+
+	return initCmd
+}
+
+func (a *App) initSettingsFromFlags(cmd *cobra.Command, rules pixela.ValidationRules) (initSettings, error) {
+	var settings initSettings
+
+	settings.username = a.viper.GetString("username")
+	settings.token = a.viper.GetString("token")
+	settings.profile, _ = cmd.Flags().GetString("profile")
+	generate, _ := cmd.Flags().GetBool("generate-token")
+	agree, _ := cmd.Flags().GetBool("agree-terms-of-service")
+	notMinor, _ := cmd.Flags().GetBool("not-minor")
+
+	if err := matchPattern("username", rules.UsernamePattern, settings.username); err != nil {
+		return settings, wrapUsageError(err, "argument error")
+	}
+
+	// --generate-token is preferred to token of environment variable or config file
+	if generate && !cmd.Flags().Changed("token") {
+		settings.token = ""
+	}
+
+	if generate == (settings.token != "") {
+		return settings, usageErrorf("argument error: `init` requires one of --token or --generate-token")
+	}
+
+	if generate {
+		token, err := pixela.GenerateToken(generatedTokenLength)
+
+		if err != nil {
+			return settings, err
+		}
+
+		settings.token, settings.generatedToken = token, true
+	}
+
+	if !agree || !notMinor {
+		return settings, usageErrorf("argument error: `init` requires --agree-terms-of-service and --not-minor (see %s)", termsOfServiceURL)
+	}
+
+	// given profile is used by default as interactive mode does
+	settings.useProfile = settings.profile != ""
+
+	if settings.profile == "" {
+		settings.profile = settings.username
+	}
+
+	graphID, _ := cmd.Flags().GetString("graph-id")
+
+	if graphID != "" {
+		graph := &pixela.CreateGraphRequest{ID: graphID, SelfSufficient: pixela.SelfSufficientNone}
+		graph.Name, _ = cmd.Flags().GetString("graph-name")
+		graph.Unit, _ = cmd.Flags().GetString("graph-unit")
+		graph.Timezone, _ = cmd.Flags().GetString("graph-timezone")
+		numType, _ := cmd.Flags().GetString("graph-type")
+		color, _ := cmd.Flags().GetString("graph-color")
+		graph.Type, graph.Color = pixela.NumType(numType), pixela.Color(color)
+
+		if graph.Name == "" || graph.Unit == "" {
+			return settings, usageErrorf("argument error: --graph-id requires --graph-name and --graph-unit")
+		}
+
+		if err := a.checkTimezone(graph.Timezone); err != nil {
+			return settings, err
+		}
+
+		settings.graph = graph
+	}
+
+	return settings, nil
+}
+
+func (a *App) askInitSettings(cmd *cobra.Command, rules pixela.ValidationRules) (initSettings, error) {
+	var settings initSettings
+	var err error
+
+	p := a.newPrompter()
+
+	username, _ := cmd.Flags().GetString("username")
+
+	settings.username, err = p.ask("username", username, func(s string) error {
+		return matchPattern("username", rules.UsernamePattern, s)
+	})
+
+	if err != nil {
+		return settings, err
+	}
+
+	token, _ := cmd.Flags().GetString("token")
+
+	settings.token, err = p.ask("token (empty to generate)", token, func(s string) error {
+		if s == "" {
+			return nil
+		}
+
+		return matchPattern("token", rules.TokenPattern, s)
+	})
+
+	if err != nil {
+		return settings, err
+	}
+
+	if settings.token == "" {
+		if settings.token, err = pixela.GenerateToken(generatedTokenLength); err != nil {
+			return settings, err
+		}
+
+		settings.generatedToken = true
+	}
+
+	fmt.Fprintf(p.w, "terms of service: %s\n", termsOfServiceURL)
+
+	agree, err := p.confirm("Do you agree terms of service?", false)
+
+	if err != nil {
+		return settings, err
+	}
+
+	notMinor, err := p.confirm("Are you not a minor (or do you have consent of your guardian)?", false)
+
+	if err != nil {
+		return settings, err
+	}
+
+	if !agree || !notMinor {
+		return settings, usageErrorf("argument error: pixe.la user can not be created without agreement of terms of service")
+	}
+
+	profile, _ := cmd.Flags().GetString("profile")
+
+	if profile == "" {
+		profile = settings.username
+	}
+
+	if settings.profile, err = p.ask("profile name", profile, nil); err != nil {
+		return settings, err
+	}
+
+	if settings.useProfile, err = p.confirm("Use this profile by default?", true); err != nil {
+		return settings, err
+	}
+
+	createGraph, err := p.confirm("Create first graph?", true)
+
+	if err != nil || !createGraph {
+		return settings, err
+	}
+
+	graph := &pixela.CreateGraphRequest{SelfSufficient: pixela.SelfSufficientNone}
+
+	if graph.ID, err = p.ask("graph id", "", func(s string) error {
+		return matchPattern("graph id", rules.GraphIDPattern, s)
+	}); err != nil {
+		return settings, err
+	}
+
+	if graph.Name, err = p.ask("graph name", graph.ID, nil); err != nil {
+		return settings, err
+	}
+
+	if graph.Unit, err = p.ask("unit (such as commits, pages or km)", "", func(s string) error {
+		if s == "" {
+			return fmt.Errorf("unit is required")
+		}
+
+		return nil
+	}); err != nil {
+		return settings, err
+	}
+
+	numType, err := p.choose("type of quantity", stringValues(pixela.NumTypes()), pixela.NumTypeInt.String())
+
+	if err != nil {
+		return settings, err
+	}
+
+	color, err := p.choose("color", stringValues(pixela.Colors()), pixela.ColorShibafu.String())
+
+	if err != nil {
+		return settings, err
+	}
+
+	graph.Type, graph.Color = pixela.NumType(numType), pixela.Color(color)
+
+	if graph.Timezone, err = p.ask("timezone (empty for UTC)", "", a.checkTimezone); err != nil {
+		return settings, err
+	}
+
+	settings.graph = graph
+
+	return settings, nil
+}
+
+// create user, save profile and create first graph
+func (a *App) runInit(settings initSettings) error {
+	client, err := a.newClientWithAuth(settings.username, settings.token)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = client.CreateUserWithRequest(pixela.CreateUserRequest{AgreeTermsOfService: true, NotMinor: true})
+
+	if err != nil {
+		return errors.Wrap(err, "request error")
+	}
+
+	a.printStatus(fmt.Sprintf("user `%s` is created", settings.username))
+
+	if settings.generatedToken {
+		a.printStatus(fmt.Sprintf("token: %s", settings.token))
+	}
+
+	if err := a.saveInitProfile(settings); err != nil {
+		// user is created. show settings not to lose token.
+		a.printStatus("You should take a note following settings.")
+		a.printStatus(fmt.Sprintf("username: %s", settings.username))
+		a.printStatus(fmt.Sprintf("token: %s", settings.token))
+
+		return err
+	}
+
+	if settings.graph == nil {
+		return nil
+	}
+
+	if _, err := client.CreateGraphWithRequest(*settings.graph); err != nil {
+		return errors.Wrap(err, "request error")
+	}
+
+	a.clearCompletionCache()
+	a.printStatus(fmt.Sprintf("graph `%s` is created: %s", settings.graph.ID, client.GetGraphDetailURL(settings.graph.ID)))
+
+	return nil
+}
+
+func (a *App) saveInitProfile(settings initSettings) error {
+	config, err := a.loadConfigFile()
+
+	if err != nil {
+		return err
+	}
+
+	config.set(settings.profile, "username", settings.username)
+	config.set(settings.profile, "token", settings.token)
+
+	if settings.useProfile || config.currentProfile() == "" {
+		config.set("", configCurrentProfile, settings.profile)
+	}
+
+	if err := config.save(); err != nil {
+		return err
+	}
+
+	a.printStatus(fmt.Sprintf("profile `%s` is saved to %s", settings.profile, config.path))
+
+	return nil
+}
+
+func matchPattern(name, pattern, value string) error {
+	re, err := regexp.Compile(pattern)
+
+	if err != nil {
+		return err
+	}
+
+	if !re.MatchString(value) {
+		return fmt.Errorf("invalid %s `%s`: must match `%s`", name, value, pattern)
+	}
+
+	return nil
+}
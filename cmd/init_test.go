@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApp_InitNonInteractive(t *testing.T) {
+	var requests []string
+
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		body, _ := ioutil.ReadAll(req.Body)
+		requests = append(requests, req.Method+" "+req.URL.Path+" "+string(body))
+
+		return newTestResponse(http.StatusOK, `{"message":"Success.","isSuccess":true}`), nil
+	})
+
+	configFile := filepath.Join(t.TempDir(), ".pixela.yaml")
+
+	if err := os.WriteFile(configFile, []byte("current-profile: personal\nprofiles:\n  personal:\n    username: foo\n    token: xxxxxxxx\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) {
+		t.Helper()
+		out.Reset()
+		errOut.Reset()
+
+		args = append([]string{"init", "--non-interactive", "--agree-terms-of-service", "--not-minor", "--config", configFile}, args...)
+
+		if got := app.Execute(args); got != ExitNormal {
+			t.Fatalf("%v: want %d, but %d: %s", args, ExitNormal, got, errOut.String())
+		}
+	}
+
+	config := func(key string) string {
+		t.Helper()
+		out.Reset()
+
+		if got := app.Execute([]string{"config", "get", key, "--config", configFile}); got != ExitNormal {
+			t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+		}
+
+		return strings.TrimSpace(out.String())
+	}
+
+	// current profile is kept without --profile
+	run("--username", "bar", "--token", "yyyyyyyy")
+
+	if got := config("username"); got != "foo" {
+		t.Fatalf("want foo, but %s", got)
+	}
+
+	// given profile is used by default
+	run("--username", "baz", "--generate-token", "--profile", "team", "--graph-id", "graphid", "--graph-name", "name", "--graph-unit", "km")
+
+	if got := config("username"); got != "baz" {
+		t.Fatalf("want baz, but %s", got)
+	}
+
+	// username and token are read from environment variables
+	t.Setenv("PIXELA_USERNAME", "qux")
+	t.Setenv("PIXELA_TOKEN", "zzzzzzzz")
+	run("--profile", "env")
+
+	if got := config("username"); got != "qux" {
+		t.Fatalf("want qux, but %s", got)
+	}
+
+	// --generate-token is preferred to token of environment variable
+	run("--generate-token", "--profile", "generated")
+
+	want := []string{
+		`POST /v1/users {"username":"bar","token":"yyyyyyyy","agreeTermsOfService":"yes","notMinor":"yes"}`,
+		`POST /v1/users `,
+		`POST /v1/users/baz/graphs `,
+		`POST /v1/users {"username":"qux","token":"zzzzzzzz","agreeTermsOfService":"yes","notMinor":"yes"}`,
+		`POST /v1/users {"username":"qux","token":"`,
+	}
+
+	if len(requests) != len(want) {
+		t.Fatalf("want %v, but %v", want, requests)
+	}
+
+	for i := range want {
+		if !strings.HasPrefix(requests[i], want[i]) {
+			t.Fatalf("want %s, but %s", want[i], requests[i])
+		}
+	}
+}
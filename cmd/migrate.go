@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+func (a *App) newGraphMigrateTypeCmd() *cobra.Command {
+	graphMigrateTypeCmd := &cobra.Command{
+		Use:   "migrate-type",
+		Short: "change graph type (int/float) by migration",
+		Long: `change graph type (int/float) by migration. Usage:
+
+$ pixela graph migrate-type <graph id> <int/float> [--yes] [--temp-id id] [--backup-dir dir] [--scale factor]
+
+pixe.la can not change type of graph. so migrate-type
+  1. saves local backup of the graph (pixels and webhooks),
+  2. clones the graph into temporary graph with new type (quantities are converted),
+  3. deletes the graph and its webhooks,
+  4. clones temporary graph back into the graph ID,
+  5. recreates webhooks (with new hashes) and deletes temporary graph.
+
+local backup is removed only when all steps succeed. it can be restored by "pixela restore".
+quantities are rounded when type is changed to int.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs, completeValues(stringValues(pixela.NumTypes())...)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 2 {
+				return usageErrorf("argument error: `graph migrate-type` requires 2 arguments give %d arguments", len(args))
+			}
+
+			graphID := args[0]
+			numType, err := pixela.ParseNumType(args[1])
+
+			if err != nil {
+				return wrapUsageError(err, "argument error")
+			}
+
+			var opts pixela.MigrateOptions
+
+			opts.TempID, _ = cmd.Flags().GetString("temp-id")
+			opts.Concurrency, _ = cmd.Flags().GetInt("concurrency")
+			yes, _ := cmd.Flags().GetBool("yes")
+			backupDir, _ := cmd.Flags().GetString("backup-dir")
+
+			if scale, _ := cmd.Flags().GetString("scale"); scale != "" {
+				if opts.Convert, err = pixela.ScaleQuantity(scale, numType); err != nil {
+					return wrapUsageError(err, "argument error")
+				}
+			}
+
+			if opts.TempID == "" {
+				opts.TempID = pixela.TempGraphID(graphID)
+			}
+
+			// do request
+			client, err := a.newClient()
+
+			if err != nil {
+				return err
+			}
+
+			backup, err := client.CreateGraphBackup(graphID)
+
+			if err != nil {
+				return errors.Wrap(err, "request error")
+			}
+
+			backupPath := filepath.Join(backupDir, fmt.Sprintf("pixela-%s-%s.json.gz", graphID, time.Now().Format("20060102150405")))
+
+			if err := writeFileAtomically(backupPath, func(w io.Writer) error {
+				return pixela.WriteBackup(w, backup, true)
+			}); err != nil {
+				return err
+			}
+
+			a.printStatus(fmt.Sprintf("backup of `%s` (%d pixels and %d webhooks) is saved to %s", graphID, len(backup.Graphs[0].Pixels), len(backup.Webhooks), backupPath))
+
+			if !yes {
+				ok, err := a.newPrompter().confirm(fmt.Sprintf("graph `%s` will be deleted and recreated as %s (temporary graph `%s`). continue?", graphID, numType, opts.TempID), false)
+
+				if err != nil {
+					return err
+				}
+
+				if !ok {
+					os.Remove(backupPath)
+					a.printStatus("migration is canceled")
+
+					return nil
+				}
+			}
+
+			opts.Progress = func(step string) {
+				a.printStatus(step)
+			}
+
+			result, err := client.MigrateGraphType(graphID, numType, opts)
+			a.clearCompletionCache()
+
+			if err != nil {
+				a.ui.OutputErrln(fmt.Sprintf("backup is kept in %s", backupPath))
+
+				return errors.Wrap(err, "request error")
+			}
+
+			for oldHash, newHash := range result.WebhookHashes {
+				a.printStatus(fmt.Sprintf("webhook hash is changed: %s -> %s", oldHash, newHash))
+			}
+
+			a.printStatus(fmt.Sprintf("graph `%s` is migrated to %s with %d pixels", graphID, numType, result.Pixels))
+
+			if err := os.Remove(backupPath); err != nil {
+				return err
+			}
+
+			return a.printResult(result)
+		},
+	}
+
+	graphMigrateTypeCmd.Flags().Bool("yes", false, "do not ask confirmation")
+	graphMigrateTypeCmd.Flags().String("temp-id", "", "temporary graph ID (default is <graph id>-tmp)")
+	graphMigrateTypeCmd.Flags().String("backup-dir", ".", "directory of local backup")
+	graphMigrateTypeCmd.Flags().String("scale", "", "multiply quantities by factor (such as 60 or 1/60)")
+	graphMigrateTypeCmd.Flags().Int("concurrency", 1, "number of parallel requests")
+
+	return graphMigrateTypeCmd
+}
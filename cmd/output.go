@@ -0,0 +1,344 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// output formats of `--output` flag
+const (
+	outputDefault    = ""
+	outputJSON       = "json"
+	outputJSONPretty = "json-pretty"
+	outputYAML       = "yaml"
+	outputTable      = "table"
+	outputCSV        = "csv"
+	outputTemplate   = "template"
+)
+
+const outputFlagUsage = "output format (json/json-pretty/yaml/table/csv/template=GO_TEMPLATE)"
+
+// parse `--output` flag value into format and go template
+func parseOutputFormat(output string) (string, string, error) {
+	for _, prefix := range []string{"template=", "go-template="} {
+		if strings.HasPrefix(output, prefix) {
+			return outputTemplate, strings.TrimPrefix(output, prefix), nil
+		}
+	}
+
+	switch output {
+	case outputDefault, outputJSON, outputJSONPretty, outputYAML, outputTable, outputCSV:
+		return output, "", nil
+	case "pretty":
+		return outputJSONPretty, "", nil
+	case "yml":
+		return outputYAML, "", nil
+	}
+
+	return "", "", usageErrorf("argument error: unknown output format `%s`: allows %s", output, outputFlagUsage)
+}
+
+// print response of get subcommands
+func (a *App) printOutput(v interface{}) error {
+	format, tmpl, err := parseOutputFormat(a.viper.GetString("output"))
+
+	if err != nil {
+		return err
+	}
+
+	out, err := formatOutput(v, format, tmpl)
+
+	if err != nil {
+		return errors.Wrap(err, "response parse error")
+	}
+
+	a.ui.Outputln(strings.TrimRight(out, "\n"))
+
+	return nil
+}
+
+// print status line of subcommand (such as progress or summary).
+// it is printed to stderr when `--output` is given, so stdout is kept parsable.
+func (a *App) printStatus(message string) {
+	if a.viper.GetString("output") != "" {
+		a.ui.OutputErrln(message)
+		return
+	}
+
+	a.ui.Outputln(message)
+}
+
+// print response of create, update and delete subcommands in verbose mode or when `--output` is given
+func (a *App) printResult(v interface{}) error {
+	if !a.viper.GetBool("verbose") && a.viper.GetString("output") == "" {
+		return nil
+	}
+
+	return a.printOutput(v)
+}
+
+func formatOutput(v interface{}, format, tmpl string) (string, error) {
+	switch format {
+	case outputDefault:
+		switch value := v.(type) {
+		case string:
+			return value, nil
+		case []string:
+			return strings.Join(value, "\n"), nil
+		}
+
+		return formatJSON(v, false)
+	case outputJSON:
+		return formatJSON(v, false)
+	case outputJSONPretty:
+		return formatJSON(v, true)
+	case outputYAML:
+		return formatYAML(v)
+	case outputTable:
+		return formatTable(v)
+	case outputCSV:
+		return formatCSV(v)
+	case outputTemplate:
+		return formatTemplate(v, tmpl)
+	}
+
+	return "", fmt.Errorf("unknown output format `%s`", format)
+}
+
+func formatJSON(v interface{}, pretty bool) (string, error) {
+	var out []byte
+	var err error
+
+	if pretty {
+		out, err = json.MarshalIndent(v, "", "  ")
+	} else {
+		out, err = json.Marshal(v)
+	}
+
+	return string(out), err
+}
+
+// YAML keeps key order of JSON output
+func formatYAML(v interface{}) (string, error) {
+	out, err := json.Marshal(v)
+
+	if err != nil {
+		return "", err
+	}
+
+	var node yaml.Node
+
+	if err := yaml.Unmarshal(out, &node); err != nil {
+		return "", err
+	}
+
+	resetYAMLStyle(&node)
+
+	buf := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(&node); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// JSON is parsed as flow style and quoted YAML. reset to plain block style.
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+
+	for _, child := range node.Content {
+		resetYAMLStyle(child)
+	}
+}
+
+// go template is executed with JSON representation (keys are JSON field names)
+func formatTemplate(v interface{}, tmpl string) (string, error) {
+	t, err := template.New("output").Parse(tmpl)
+
+	if err != nil {
+		return "", errors.Wrap(err, "invalid template")
+	}
+
+	out, err := json.Marshal(v)
+
+	if err != nil {
+		return "", err
+	}
+
+	var data interface{}
+
+	if err := json.Unmarshal(out, &data); err != nil {
+		return "", err
+	}
+
+	buf := &bytes.Buffer{}
+
+	if err := t.Execute(buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func formatTable(v interface{}) (string, error) {
+	header, rows := tabulate(v)
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(header, "\t")))
+
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func formatCSV(v interface{}) (string, error) {
+	header, rows := tabulate(v)
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+
+	if err := w.Write(header); err != nil {
+		return "", err
+	}
+
+	if err := w.WriteAll(rows); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// convert value into header and rows.
+// struct which has only one slice field (such as graph definitions) is tabulated as the slice.
+func tabulate(v interface{}) ([]string, [][]string) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+
+	if rv.Kind() == reflect.Struct {
+		if name, list, ok := soleSliceField(rv); ok {
+			return tabulateSlice(name, list)
+		}
+
+		header, row := structRow(rv)
+
+		return header, [][]string{row}
+	}
+
+	if rv.Kind() == reflect.Slice {
+		return tabulateSlice("value", rv)
+	}
+
+	return []string{"value"}, [][]string{{cellString(rv)}}
+}
+
+func tabulateSlice(name string, list reflect.Value) ([]string, [][]string) {
+	elemType := list.Type().Elem()
+
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	if elemType.Kind() != reflect.Struct {
+		rows := make([][]string, 0, list.Len())
+
+		for i := 0; i < list.Len(); i++ {
+			rows = append(rows, []string{cellString(list.Index(i))})
+		}
+
+		return []string{name}, rows
+	}
+
+	header := structHeader(elemType)
+	rows := make([][]string, 0, list.Len())
+
+	for i := 0; i < list.Len(); i++ {
+		_, row := structRow(reflect.Indirect(list.Index(i)))
+		rows = append(rows, row)
+	}
+
+	return header, rows
+}
+
+func soleSliceField(rv reflect.Value) (string, reflect.Value, bool) {
+	if rv.NumField() != 1 || rv.Field(0).Kind() != reflect.Slice {
+		return "", reflect.Value{}, false
+	}
+
+	return jsonFieldName(rv.Type().Field(0)), rv.Field(0), true
+}
+
+func structHeader(t reflect.Type) []string {
+	var header []string
+
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonFieldName(t.Field(i)); name != "-" && t.Field(i).IsExported() {
+			header = append(header, name)
+		}
+	}
+
+	return header
+}
+
+func structRow(rv reflect.Value) ([]string, []string) {
+	var header, row []string
+
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Type().Field(i)
+
+		if name := jsonFieldName(field); name != "-" && field.IsExported() {
+			header = append(header, name)
+			row = append(row, cellString(rv.Field(i)))
+		}
+	}
+
+	return header, row
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+func cellString(v reflect.Value) string {
+	v = reflect.Indirect(v)
+
+	switch v.Kind() {
+	case reflect.Invalid:
+		return ""
+	case reflect.Slice, reflect.Array:
+		values := make([]string, 0, v.Len())
+
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, cellString(v.Index(i)))
+		}
+
+		return strings.Join(values, ",")
+	case reflect.Struct, reflect.Map:
+		out, _ := json.Marshal(v.Interface())
+		return string(out)
+	}
+
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+type testOutputRow struct {
+	ID     string   `json:"id"`
+	Count  int      `json:"count"`
+	Tags   []string `json:"tags,omitempty"`
+	hidden string
+}
+
+func TestFormatOutput(t *testing.T) {
+	rows := []testOutputRow{{"a", 1, []string{"x", "y"}, ""}, {"b", 20, nil, ""}}
+	list := struct {
+		Rows []testOutputRow `json:"rows"`
+	}{rows}
+
+	tests := []struct {
+		name   string
+		v      interface{}
+		format string
+		tmpl   string
+		want   string
+	}{
+		{"default string", "message", outputDefault, "", "message"},
+		{"default lines", []string{"a", "b"}, outputDefault, "", "a\nb"},
+		{"default struct", rows[1], outputDefault, "", `{"id":"b","count":20}`},
+		{"json", rows, outputJSON, "", `[{"id":"a","count":1,"tags":["x","y"]},{"id":"b","count":20}]`},
+		{"json pretty", rows[1], outputJSONPretty, "", "{\n  \"id\": \"b\",\n  \"count\": 20\n}"},
+		{"yaml keeps key order", rows, outputYAML, "", "- id: a\n  count: 1\n  tags:\n    - x\n    - y\n- id: b\n  count: 20\n"},
+		{"table", rows, outputTable, "", "ID  COUNT  TAGS\na   1      x,y\nb   20     \n"},
+		{"table of sole slice field", list, outputTable, "", "ID  COUNT  TAGS\na   1      x,y\nb   20     \n"},
+		{"table of struct", rows[0], outputTable, "", "ID  COUNT  TAGS\na   1      x,y\n"},
+		{"table of strings", []string{"a", "b"}, outputTable, "", "VALUE\na\nb\n"},
+		{"csv", rows, outputCSV, "", "id,count,tags\na,1,\"x,y\"\nb,20,\n"},
+		{"csv of value", 3, outputCSV, "", "value\n3\n"},
+		{"template", rows, outputTemplate, `{{range .}}{{.id}}={{.count}};{{end}}`, "a=1;b=20;"},
+		{"template of sole slice field", list, outputTemplate, `{{len .rows}}`, "2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatOutput(tt.v, tt.format, tt.tmpl)
+
+			if err != nil {
+				t.Fatalf("want nil, but %#v", err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("want %q, but %q", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := formatOutput(rows, outputTemplate, "{{"); err == nil {
+		t.Fatalf("want error for invalid template")
+	}
+
+	if _, err := formatOutput(rows, "xml", ""); err == nil {
+		t.Fatalf("want error for unknown format")
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	tests := []struct {
+		output string
+		format string
+		tmpl   string
+	}{
+		{"", outputDefault, ""},
+		{"pretty", outputJSONPretty, ""},
+		{"yml", outputYAML, ""},
+		{"table", outputTable, ""},
+		{"template={{.id}}", outputTemplate, "{{.id}}"},
+		{"go-template={{.id}}", outputTemplate, "{{.id}}"},
+	}
+
+	for _, tt := range tests {
+		format, tmpl, err := parseOutputFormat(tt.output)
+
+		if err != nil || format != tt.format || tmpl != tt.tmpl {
+			t.Fatalf("%s: want %s %s, but %s %s (%v)", tt.output, tt.format, tt.tmpl, format, tmpl, err)
+		}
+	}
+
+	if _, _, err := parseOutputFormat("xml"); exitCodeOf(err) != ExitUsage {
+		t.Fatalf("want usage error, but %v", err)
+	}
+}
+
+func TestApp_PrintStatus(t *testing.T) {
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		t.Fatalf("unexpected request: %s %s", req.Method, req.URL)
+		return nil, nil
+	})
+
+	// status line is on stdout without --output
+	if got := app.Execute([]string{"timer", "start", "focus", "--username", "testuser"}); got != ExitNormal {
+		t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+	}
+
+	if out.Len() == 0 || errOut.Len() != 0 {
+		t.Fatalf("unexpected output: %q %q", out.String(), errOut.String())
+	}
+
+	// status line is on stderr and stdout is parsable with --output
+	out.Reset()
+
+	if got := app.Execute([]string{"timer", "pause", "focus", "-o", "json", "--username", "testuser"}); got != ExitNormal {
+		t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+	}
+
+	if out.Len() != 0 || errOut.Len() == 0 {
+		t.Fatalf("unexpected output: %q %q", out.String(), errOut.String())
+	}
+}
+
+func TestApp_GraphImportDryRunOutput(t *testing.T) {
+	auth := []string{"--username", "testuser", "--token", "testtoken"}
+
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/graphs") {
+			return newTestResponse(http.StatusOK, `{"graphs":[{"id":"graphid","type":"int"}]}`), nil
+		}
+
+		if req.Method == http.MethodGet {
+			return newTestResponse(http.StatusOK, `{"pixels":[{"date":"20190102","quantity":"5"}]}`), nil
+		}
+
+		t.Fatalf("unexpected request: %s %s", req.Method, req.URL)
+		return nil, nil
+	})
+
+	file := filepath.Join(t.TempDir(), "pixels.csv")
+
+	if err := ioutil.WriteFile(file, []byte("date,quantity\n2019-01-01,1\n2019-01-02,2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := app.Execute(append([]string{"graph", "import", "graphid", file, "--dry-run", "--mode", "overwrite", "-o", "json"}, auth...)); got != ExitNormal {
+		t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+	}
+
+	want := `[{"action":"create","new":{"date":"20190101","quantity":"1"}},{"action":"update","old":{"date":"20190102","quantity":"5"},"new":{"date":"20190102","quantity":"2"}}]` + "\n"
+
+	if out.String() != want {
+		t.Fatalf("want %s, but %s", want, out.String())
+	}
+}
+
+func TestApp_GraphCloneOutput(t *testing.T) {
+	auth := []string{"--username", "testuser", "--token", "testtoken"}
+
+	graphs := `{"graphs":[{"id":"src","name":"source","unit":"km","type":"int","color":"sora"}]}`
+
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/graphs"):
+			graphs = `{"graphs":[{"id":"src","name":"source","unit":"km","type":"int","color":"sora"},{"id":"dst","type":"int"}]}`
+			return newTestResponse(http.StatusOK, `{"message":"Success.","isSuccess":true}`), nil
+		case req.Method != http.MethodGet:
+			return newTestResponse(http.StatusOK, `{"message":"Success.","isSuccess":true}`), nil
+		case strings.HasSuffix(req.URL.Path, "/graphs"):
+			return newTestResponse(http.StatusOK, graphs), nil
+		case strings.HasSuffix(req.URL.Path, "/stats"):
+			return newTestResponse(http.StatusOK, `{"totalPixelsCount":1}`), nil
+		}
+
+		return newTestResponse(http.StatusOK, `{"pixels":[{"date":"20190101","quantity":"1"}]}`), nil
+	})
+
+	if got := app.Execute(append([]string{"graph", "clone", "src", "dst", "-o", "json"}, auth...)); got != ExitNormal {
+		t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+	}
+
+	// status line is not mixed into JSON
+	var result pixela.CloneResult
+
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil || result.Pixels != 1 {
+		t.Fatalf("unexpected output: %q (%v)", out.String(), err)
+	}
+
+	if !strings.Contains(errOut.String(), "is cloned into `dst`") {
+		t.Fatalf("unexpected status: %q", errOut.String())
+	}
+}
+
+func TestApp_ConfiguredOutput(t *testing.T) {
+	auth := []string{"--username", "testuser", "--token", "testtoken"}
+
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/graphs") {
+			return newTestResponse(http.StatusOK, `{"graphs":[{"id":"graphid","type":"int"}]}`), nil
+		}
+
+		return newTestResponse(http.StatusOK, `{"message":"Success.","isSuccess":true}`), nil
+	})
+
+	configFile := filepath.Join(t.TempDir(), ".pixela.yaml")
+
+	if err := ioutil.WriteFile(configFile, []byte("output: json\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// result is printed by output format of config file, and config file line is not mixed into it
+	for _, args := range [][]string{
+		{"pixel", "create", "graphid", "20190101", "5", "--config", configFile},
+		{"pixel", "create", "graphid", "20190101", "5", "--config", configFile, "-n"},
+	} {
+		out.Reset()
+		errOut.Reset()
+
+		if got := app.Execute(append(args, auth...)); got != ExitNormal {
+			t.Fatalf("%v: want %d, but %d: %s", args, ExitNormal, got, errOut.String())
+		}
+
+		var result pixela.NoneGetResponseBody
+
+		if err := json.Unmarshal(out.Bytes(), &result); err != nil || !result.IsSuccess {
+			t.Fatalf("%v: unexpected output: %q (%v)", args, out.String(), err)
+		}
+	}
+
+	if !strings.Contains(errOut.String(), "Using config file: "+configFile) {
+		t.Fatalf("unexpected stderr: %q", errOut.String())
+	}
+}
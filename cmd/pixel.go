@@ -1,19 +1,13 @@
 package cmd
 
 import (
-	"encoding/json"
-	"fmt"
-
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 
 	"github.com/noissefnoc/pixela-client-go/pixela"
 )
 
-var optionalData string
-
-func newPixelCmd() *cobra.Command {
+func (a *App) newPixelCmd() *cobra.Command {
 	pixelCmd := &cobra.Command{
 		Use:   "pixel",
 		Short: "handle pixel subcommands (create, get, update, increment, decrement and delete)",
@@ -25,123 +19,129 @@ see official document (https://docs.pixe.la) for more detail`,
 		},
 	}
 
-	pixelCmd.AddCommand(newPixelPostCmd())
-	pixelCmd.AddCommand(newPixelGetCmd())
-	pixelCmd.AddCommand(newPixelUpdateCmd())
-	pixelCmd.AddCommand(newPixelDeleteCmd())
-	pixelCmd.AddCommand(newPixelIncrementCmd())
-	pixelCmd.AddCommand(newPixelDecrementCmd())
+	pixelCmd.AddCommand(a.newPixelPostCmd())
+	pixelCmd.AddCommand(a.newPixelGetCmd())
+	pixelCmd.AddCommand(a.newPixelUpdateCmd())
+	pixelCmd.AddCommand(a.newPixelDeleteCmd())
+	pixelCmd.AddCommand(a.newPixelIncrementCmd())
+	pixelCmd.AddCommand(a.newPixelDecrementCmd())
 
 	return pixelCmd
 }
 
-func newPixelPostCmd() *cobra.Command {
+func (a *App) newPixelPostCmd() *cobra.Command {
 	pixelPostCmd := &cobra.Command{
 		Use:   "create",
 		Short: "create pixel",
 		Long: `create pixel. Usage:
 
-$ pixela pixel create <graph id> <date> <quantity>
+$ pixela pixel create <graph id> [date] <quantity> [--optionalData JSON]
+
+date accepts yyyyMMdd, yyyy-MM-dd, today, yesterday, -3d, 3 days ago and last friday (default today).
+relative date is resolved in the graph timezone (or --tz).
 
 see official document (https://docs.pixe.la/#/post-pixel) for more detail.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// check arguments
-			if len(args) != 3 {
-				return fmt.Errorf("argument error: `pixel create` requires 3 arguments give %d arguments", len(args))
+			if len(args) != 2 && len(args) != 3 {
+				return usageErrorf("argument error: `pixel create` requires 2 or 3 arguments give %d arguments", len(args))
 			}
 
 			// do request
-			client, err := pixela.New(viper.GetString("username"), viper.GetString("token"), viper.GetBool("verbose"))
+			client, err := a.newClient()
 
 			if err != nil {
 				return err
 			}
 
-			response, err := client.PostPixel(args[0], args[1], args[2], optionalData)
+			date, quantity, err := a.dateAndQuantity(client, args)
 
 			if err != nil {
-				return errors.Wrap(err, "request error: ")
+				return err
 			}
 
-			responseJSON, err := json.Marshal(response)
+			optionalData, _ := cmd.Flags().GetString("optionalData")
 
-			if err != nil {
-				return errors.Wrap(err, "response parse error: ")
-			}
+			response, err := client.PostPixel(args[0], date, quantity, optionalData)
 
-			// print result in verbose mode
-			if viper.GetBool("verbose") {
-				cui.Outputln(string(responseJSON))
+			if err != nil {
+				return errors.Wrap(err, "request error: ")
 			}
 
-			return nil
+			return a.printResult(response)
 		},
 	}
 
+	pixelPostCmd.Flags().StringP("optionalData", "", "", "optionalData (JSON format)")
+
 	return pixelPostCmd
 }
 
-func newPixelGetCmd() *cobra.Command {
+func (a *App) newPixelGetCmd() *cobra.Command {
 	pixelGetCmd := &cobra.Command{
 		Use:   "get",
 		Short: "get pixel value",
 		Long: `get pixel value. Usage:
 
-$ pixela pixel get <graph id> <date>
+$ pixela pixel get <graph id> [date]
+
+date accepts yyyyMMdd, yyyy-MM-dd, today, yesterday, -3d, 3 days ago and last friday (default today).
 
 see official document (https://docs.pixe.la/#/get-pixel) for more detail.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// check arguments
-			if len(args) != 2 {
-				return fmt.Errorf("argument error: `pixel record` requires 2 arguments give %d arguments", len(args))
+			if len(args) != 1 && len(args) != 2 {
+				return usageErrorf("argument error: `pixel get` requires 1 or 2 arguments give %d arguments", len(args))
 			}
 
 			// do request
-			client, err := pixela.New(viper.GetString("username"), viper.GetString("token"), viper.GetBool("verbose"))
+			client, err := a.newClient()
 
 			if err != nil {
 				return err
 			}
 
-			response, err := client.GetPixel(args[0], args[1])
+			date, err := a.resolveDate(client, args[0], optionalArg(args, 1))
 
 			if err != nil {
-				return errors.Wrap(err, "request error: ")
+				return err
 			}
 
-			responseJSON, err := json.Marshal(response)
+			response, err := client.GetPixel(args[0], date)
 
 			if err != nil {
-				return errors.Wrap(err, "response parse error: ")
+				return errors.Wrap(err, "request error: ")
 			}
 
-			// print result
-			cui.Outputln(string(responseJSON))
-
-			return nil
+			return a.printOutput(response)
 		},
 	}
 
 	return pixelGetCmd
 }
 
-func newPixelUpdateCmd() *cobra.Command {
+func (a *App) newPixelUpdateCmd() *cobra.Command {
 	pixelUpdateCmd := &cobra.Command{
 		Use:   "update",
 		Short: "update pixel",
 		Long: `update pixel. Usage:
 
-$ pixela pixel update <graph id> <date> <quantity>
+$ pixela pixel update <graph id> [date] <quantity> [--optionalData JSON]
+
+date accepts yyyyMMdd, yyyy-MM-dd, today, yesterday, -3d, 3 days ago and last friday (default today).
 
 see official document (https://docs.pixe.la/#/put-pixel) for more detail.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// check arguments
-			if len(args) != 3 {
-				return fmt.Errorf("argument error: `pixel update` requires 3 arguments give %d arguments", len(args))
+			if len(args) != 2 && len(args) != 3 {
+				return usageErrorf("argument error: `pixel update` requires 2 or 3 arguments give %d arguments", len(args))
 			}
 
 			// do request
-			client, err := pixela.New(viper.GetString("username"), viper.GetString("token"), viper.GetBool("verbose"))
+			client, err := a.newClient()
 
 			if err != nil {
 				return err
@@ -153,24 +153,19 @@ see official document (https://docs.pixe.la/#/put-pixel) for more detail.`,
 				return err
 			}
 
-			response, err := client.UpdatePixel(args[0], args[1], args[2], optionalData)
+			date, quantity, err := a.dateAndQuantity(client, args)
 
 			if err != nil {
-				return errors.Wrap(err, "request error: ")
+				return err
 			}
 
-			responseJSON, err := json.Marshal(response)
+			response, err := client.UpdatePixel(args[0], date, quantity, optionalData)
 
 			if err != nil {
-				return errors.Wrap(err, "response parse error: %v\n")
-			}
-
-			// print result in verbose mode
-			if viper.GetBool("verbose") {
-				cui.Outputln(string(responseJSON))
+				return errors.Wrap(err, "request error: ")
 			}
 
-			return nil
+			return a.printResult(response)
 		},
 	}
 
@@ -179,53 +174,51 @@ see official document (https://docs.pixe.la/#/put-pixel) for more detail.`,
 	return pixelUpdateCmd
 }
 
-func newPixelDeleteCmd() *cobra.Command {
+func (a *App) newPixelDeleteCmd() *cobra.Command {
 	pixelDeleteCmd := &cobra.Command{
 		Use:   "delete",
 		Short: "delete pixel",
 		Long: `delete pixel. Usage:
 
-$ pixela pixel delete <graph id> <date>
+$ pixela pixel delete <graph id> [date]
+
+date accepts yyyyMMdd, yyyy-MM-dd, today, yesterday, -3d, 3 days ago and last friday (default today).
 
 see official document (https://docs.pixe.la/#/delete-pixel) for more detail.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// check arguments
-			if len(args) != 2 {
-				return fmt.Errorf("argument error: `pixel delete` requires 2 arguments give %d arguments", len(args))
+			if len(args) != 1 && len(args) != 2 {
+				return usageErrorf("argument error: `pixel delete` requires 1 or 2 arguments give %d arguments", len(args))
 			}
 
 			// do request
-			client, err := pixela.New(viper.GetString("username"), viper.GetString("token"), viper.GetBool("verbose"))
+			client, err := a.newClient()
 
 			if err != nil {
 				return err
 			}
 
-			response, err := client.DeletePixel(args[0], args[1])
+			date, err := a.resolveDate(client, args[0], optionalArg(args, 1))
 
 			if err != nil {
-				return errors.Wrap(err, "request error: ")
+				return err
 			}
 
-			responseJSON, err := json.Marshal(response)
+			response, err := client.DeletePixel(args[0], date)
 
 			if err != nil {
-				return errors.Wrap(err, "response parse error: ")
-			}
-
-			// print result in verbose mode
-			if viper.GetBool("verbose") {
-				cui.Outputln(string(responseJSON))
+				return errors.Wrap(err, "request error: ")
 			}
 
-			return nil
+			return a.printResult(response)
 		},
 	}
 
 	return pixelDeleteCmd
 }
 
-func newPixelIncrementCmd() *cobra.Command {
+func (a *App) newPixelIncrementCmd() *cobra.Command {
 	pixelIncrementCmd := &cobra.Command{
 		Use:   "increment",
 		Short: "increment pixel quantity",
@@ -234,14 +227,15 @@ func newPixelIncrementCmd() *cobra.Command {
 $ pixela pixel increment <graph id>
 
 see official document (https://docs.pixe.la/#/increment-pixel) for more detail.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// check arguments
 			if len(args) != 1 {
-				return fmt.Errorf("argument error: `pixel increment` requires 1 arguments give %d arguments", len(args))
+				return usageErrorf("argument error: `pixel increment` requires 1 arguments give %d arguments", len(args))
 			}
 
 			// do request
-			client, err := pixela.New(viper.GetString("username"), viper.GetString("token"), viper.GetBool("verbose"))
+			client, err := a.newClient()
 
 			if err != nil {
 				return err
@@ -253,25 +247,14 @@ see official document (https://docs.pixe.la/#/increment-pixel) for more detail.`
 				return errors.Wrap(err, "request error: ")
 			}
 
-			responseJSON, err := json.Marshal(response)
-
-			if err != nil {
-				return errors.Wrap(err, "response parse error: ")
-			}
-
-			// print result in verbose mode
-			if viper.GetBool("verbose") {
-				cui.Outputln(string(responseJSON))
-			}
-
-			return nil
+			return a.printResult(response)
 		},
 	}
 
 	return pixelIncrementCmd
 }
 
-func newPixelDecrementCmd() *cobra.Command {
+func (a *App) newPixelDecrementCmd() *cobra.Command {
 	pixelDecrementCmd := &cobra.Command{
 		Use:   "decrement",
 		Short: "decrement today's pixel quantity",
@@ -280,14 +263,15 @@ func newPixelDecrementCmd() *cobra.Command {
 $ pixela pixel decrement <graph id>
 
 see official document (https://docs.pixe.la/#/decrement-pixel) for more detail.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// check arguments
 			if len(args) != 1 {
-				return fmt.Errorf("argument error: `pixel decrement` requires 1 arguments give %d arguments", len(args))
+				return usageErrorf("argument error: `pixel decrement` requires 1 arguments give %d arguments", len(args))
 			}
 
 			// do request
-			client, err := pixela.New(viper.GetString("username"), viper.GetString("token"), viper.GetBool("verbose"))
+			client, err := a.newClient()
 
 			if err != nil {
 				return err
@@ -299,20 +283,34 @@ see official document (https://docs.pixe.la/#/decrement-pixel) for more detail.`
 				return errors.Wrap(err, "request error: ")
 			}
 
-			responseJSON, err := json.Marshal(response)
+			return a.printResult(response)
+		},
+	}
 
-			if err != nil {
-				return errors.Wrap(err, "response parse error: ")
-			}
+	return pixelDecrementCmd
+}
 
-			// print result in verbose mode
-			if viper.GetBool("verbose") {
-				cui.Outputln(string(responseJSON))
-			}
+// split `<graph id> [date] <quantity>` arguments into resolved date and quantity
+func (a *App) dateAndQuantity(client *pixela.Pixela, args []string) (string, string, error) {
+	expr := ""
 
-			return nil
-		},
+	if len(args) == 3 {
+		expr = args[1]
 	}
 
-	return pixelDecrementCmd
+	date, err := a.resolveDate(client, args[0], expr)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	return date, args[len(args)-1], nil
+}
+
+func optionalArg(args []string, i int) string {
+	if len(args) > i {
+		return args[i]
+	}
+
+	return ""
 }
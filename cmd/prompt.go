@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// prompter asks questions on interactive subcommands
+type prompter struct {
+	scanner *bufio.Scanner
+	w       io.Writer
+}
+
+func (a *App) newPrompter() *prompter {
+	return &prompter{
+		scanner: bufio.NewScanner(a.ui.Reader()),
+		w:       a.ui.Writer(),
+	}
+}
+
+// read one line (error when input is closed)
+func (p *prompter) readLine() (string, error) {
+	if !p.scanner.Scan() {
+		if err := p.scanner.Err(); err != nil {
+			return "", err
+		}
+
+		return "", usageErrorf("input error: input is closed (use non-interactive mode)")
+	}
+
+	return strings.TrimSpace(p.scanner.Text()), nil
+}
+
+// ask value. empty answer is default value. answer is asked again while check returns error.
+func (p *prompter) ask(label, def string, check func(string) error) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(p.w, "%s [%s]: ", label, def)
+		} else {
+			fmt.Fprintf(p.w, "%s: ", label)
+		}
+
+		answer, err := p.readLine()
+
+		if err != nil {
+			return "", err
+		}
+
+		if answer == "" {
+			answer = def
+		}
+
+		if check == nil {
+			return answer, nil
+		}
+
+		if err := check(answer); err != nil {
+			fmt.Fprintln(p.w, err)
+			continue
+		}
+
+		return answer, nil
+	}
+}
+
+// ask yes or no
+func (p *prompter) confirm(label string, def bool) (bool, error) {
+	choices := "y/N"
+
+	if def {
+		choices = "Y/n"
+	}
+
+	for {
+		fmt.Fprintf(p.w, "%s [%s]: ", label, choices)
+
+		answer, err := p.readLine()
+
+		if err != nil {
+			return false, err
+		}
+
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// choose one of choices by number or value
+func (p *prompter) choose(label string, choices []string, def string) (string, error) {
+	fmt.Fprintln(p.w, label)
+
+	for i, choice := range choices {
+		fmt.Fprintf(p.w, "  %d) %s\n", i+1, choice)
+	}
+
+	answer, err := p.ask("choose", def, func(answer string) error {
+		for i, choice := range choices {
+			if answer == choice || answer == strconv.Itoa(i+1) {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("choose one of %s", strings.Join(choices, ", "))
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	if i, err := strconv.Atoi(answer); err == nil {
+		return choices[i-1], nil
+	}
+
+	return answer, nil
+}
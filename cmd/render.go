@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+	"github.com/noissefnoc/pixela-client-go/render"
+)
+
+// image formats of `graph render`
+const (
+	imageSVG = "svg"
+	imagePNG = "png"
+)
+
+// days of pixels fetched for rendering (full mode has 53 weeks)
+const renderDays = 53 * 7
+
+func (a *App) newGraphRenderCmd() *cobra.Command {
+	graphRenderCmd := &cobra.Command{
+		Use:   "render",
+		Short: "render graph SVG/PNG locally",
+		Long: `render Pixela style graph image (SVG or PNG) locally. Usage:
+
+$ pixela graph render <graph id> [--mode full/short/badge/line] [--appearance light/dark] [--theme color] [--date yyyyMMdd] [--out file]
+$ pixela graph render --offline <exported file> [--format csv/json/ndjson] [options above]
+
+graph definition and pixels are fetched from pixe.la. with --offline, they are read from file written by "graph export"
+(graph definition is read when file is exported with --with-definition) and no request is sent.
+image format is --image-format or extension of --out (svg by default).
+date is last day of graph (today by default) and accepts yyyyMMdd, yyyy-MM-dd, today, yesterday, -3d, 3 days ago and last friday.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 1 {
+				return usageErrorf("argument error: `graph render` requires 1 argument give %d arguments", len(args))
+			}
+
+			var opts render.Options
+			var err error
+
+			modeStr, _ := cmd.Flags().GetString("mode")
+			appearanceStr, _ := cmd.Flags().GetString("appearance")
+			themeStr, _ := cmd.Flags().GetString("theme")
+			dateStr, _ := cmd.Flags().GetString("date")
+			offline, _ := cmd.Flags().GetBool("offline")
+			out, _ := cmd.Flags().GetString("out")
+
+			if opts.Mode, err = render.ParseMode(modeStr); err != nil {
+				return wrapUsageError(err, "argument error")
+			}
+
+			if opts.Appearance, err = render.ParseAppearance(appearanceStr); err != nil {
+				return wrapUsageError(err, "argument error")
+			}
+
+			if themeStr != "" {
+				if opts.Theme, err = pixela.ParseColor(themeStr); err != nil {
+					return wrapUsageError(err, "argument error")
+				}
+			}
+
+			imageFormat, err := renderImageFormat(cmd, out)
+
+			if err != nil {
+				return err
+			}
+
+			var graph pixela.Graph
+			var pixels []pixela.PixelRecord
+
+			if offline {
+				if graph, pixels, err = a.readRenderFile(cmd, args[0]); err != nil {
+					return err
+				}
+
+				if opts.Date, err = a.offlineRenderDate(dateStr, graph.Timezone); err != nil {
+					return err
+				}
+			} else {
+				if graph, pixels, opts.Date, err = a.fetchRenderData(args[0], dateStr); err != nil {
+					return err
+				}
+			}
+
+			write := func(w io.Writer) error {
+				if imageFormat == imagePNG {
+					return render.PNG(w, graph, pixels, opts)
+				}
+
+				return render.SVG(w, graph, pixels, opts)
+			}
+
+			if out == "" || out == "-" {
+				return write(a.ui.Writer())
+			}
+
+			return writeFileAtomically(out, write)
+		},
+	}
+
+	graphRenderCmd.Flags().Bool("offline", false, "render exported file without request")
+	graphRenderCmd.Flags().String("format", "", "format of exported file (csv/json/ndjson, guessed by extension)")
+	graphRenderCmd.Flags().String("mode", render.ModeFull.String(), "mode (full/short/badge/line)")
+	graphRenderCmd.Flags().String("appearance", render.AppearanceLight.String(), "appearance (light/dark)")
+	graphRenderCmd.Flags().String("theme", "", "color theme (default is graph color)")
+	graphRenderCmd.Flags().String("date", "", "last day of graph")
+	graphRenderCmd.Flags().String("image-format", "", "image format (svg/png)")
+	graphRenderCmd.Flags().String("out", "", "output file (default is stdout)")
+
+	graphRenderCmd.RegisterFlagCompletionFunc("format", completeValues(stringValues(pixela.ExportFormats())...))
+	graphRenderCmd.RegisterFlagCompletionFunc("mode", completeValues(stringValues(render.Modes())...))
+	graphRenderCmd.RegisterFlagCompletionFunc("appearance", completeValues(stringValues(render.Appearances())...))
+	graphRenderCmd.RegisterFlagCompletionFunc("theme", completeValues(stringValues(pixela.Colors())...))
+	graphRenderCmd.RegisterFlagCompletionFunc("image-format", completeValues(imageSVG, imagePNG))
+
+	return graphRenderCmd
+}
+
+// image format by flag or extension of output file
+func renderImageFormat(cmd *cobra.Command, out string) (string, error) {
+	format, _ := cmd.Flags().GetString("image-format")
+
+	if format == "" {
+		if strings.ToLower(filepath.Ext(out)) == ".png" {
+			return imagePNG, nil
+		}
+
+		return imageSVG, nil
+	}
+
+	if format != imageSVG && format != imagePNG {
+		return "", usageErrorf("argument error: unknown image format `%s`: allows svg and png", format)
+	}
+
+	return format, nil
+}
+
+// graph definition and pixels of exported file. graph ID is file name when file has no definition.
+func (a *App) readRenderFile(cmd *cobra.Command, file string) (pixela.Graph, []pixela.PixelRecord, error) {
+	format, err := importFormat(cmd, file)
+
+	if err != nil {
+		return pixela.Graph{}, nil, err
+	}
+
+	var data []byte
+
+	if file == "-" {
+		data, err = io.ReadAll(a.ui.Reader())
+	} else {
+		data, err = os.ReadFile(file)
+	}
+
+	if err != nil {
+		return pixela.Graph{}, nil, wrapUsageError(err, "input error")
+	}
+
+	definition, err := pixela.ReadGraphDefinition(bytes.NewReader(data), format)
+
+	if err != nil {
+		return pixela.Graph{}, nil, wrapUsageError(err, "input error")
+	}
+
+	graph := pixela.Graph{ID: strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))}
+
+	if definition != nil {
+		graph = *definition
+	}
+
+	pixels, err := pixela.ReadPixels(bytes.NewReader(data), format, pixela.DefaultImportMapping())
+
+	if err != nil {
+		return pixela.Graph{}, nil, wrapUsageError(err, "input error")
+	}
+
+	return graph, pixels, nil
+}
+
+// date of offline rendering is resolved in graph timezone of exported file
+func (a *App) offlineRenderDate(expr, timezone string) (time.Time, error) {
+	if expr == "" {
+		return time.Time{}, nil
+	}
+
+	loc := time.UTC
+
+	if canonical, err := pixela.NormalizeTimezone(timezone); err == nil && timezone != "" {
+		if l, err := time.LoadLocation(canonical); err == nil {
+			loc = l
+		}
+	}
+
+	date, err := pixela.ResolveDate(expr, a.now(), loc)
+
+	if err != nil {
+		return time.Time{}, wrapUsageError(err, "argument error")
+	}
+
+	return time.Parse(pixela.DateFormat, date)
+}
+
+// graph definition and pixels of the days before the date
+func (a *App) fetchRenderData(graphID, dateStr string) (pixela.Graph, []pixela.PixelRecord, time.Time, error) {
+	client, err := a.newClient()
+
+	if err != nil {
+		return pixela.Graph{}, nil, time.Time{}, err
+	}
+
+	graph, err := client.LookupGraph(graphID)
+
+	if err != nil {
+		return pixela.Graph{}, nil, time.Time{}, errors.Wrap(err, "request error")
+	}
+
+	if dateStr, err = a.resolveDate(client, graphID, dateStr); err != nil {
+		return pixela.Graph{}, nil, time.Time{}, err
+	}
+
+	end, _ := time.Parse(pixela.DateFormat, dateStr)
+
+	// render days are longer than 365 days of one request
+	pixels, err := client.GetGraphPixelsInRange(graphID, end.AddDate(0, 0, 1-renderDays).Format(pixela.DateFormat), dateStr)
+
+	if err != nil {
+		return pixela.Graph{}, nil, time.Time{}, errors.Wrap(err, "request error")
+	}
+
+	return graph, pixels, end, nil
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+func TestApp_GraphRenderOffline(t *testing.T) {
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		t.Fatalf("unexpected request: %s %s", req.Method, req.URL)
+
+		return nil, nil
+	})
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "pixels.ndjson")
+	data := `{"graph":{"id":"reading","name":"reading","unit":"pages","type":"int","color":"momiji","timezone":"UTC"}}
+{"date":"20190101","quantity":"3"}
+{"date":"20190102","quantity":"5"}
+`
+
+	if err := ioutil.WriteFile(file, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// SVG to stdout
+	if got := app.Execute([]string{"graph", "render", "--offline", file, "--mode", "badge", "--date", "2019-01-02"}); got != ExitNormal {
+		t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+	}
+
+	if !strings.Contains(out.String(), ">reading</text>") || !strings.Contains(out.String(), ">5 pages</text>") {
+		t.Fatalf("unexpected SVG: %s", out.String())
+	}
+
+	// PNG by extension of output file
+	image := filepath.Join(dir, "reading.png")
+
+	if got := app.Execute([]string{"graph", "render", "--offline", file, "--appearance", "dark", "--out", image}); got != ExitNormal {
+		t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+	}
+
+	f, err := os.ReadFile(image)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := png.Decode(bytes.NewReader(f)); err != nil {
+		t.Fatalf("want PNG, but %v", err)
+	}
+
+	// wrong arguments
+	for _, args := range [][]string{
+		{"graph", "render", "--offline", file, "--mode", "large"},
+		{"graph", "render", "--offline", file, "--image-format", "gif"},
+		{"graph", "render", "--offline", file, "--theme", "aka"},
+		{"graph", "render", "--offline", filepath.Join(dir, "missing.csv")},
+	} {
+		if got := app.Execute(args); got != ExitUsage {
+			t.Fatalf("%v: want %d, but %d", args, ExitUsage, got)
+		}
+	}
+}
+
+func TestApp_GraphRender(t *testing.T) {
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/graphs") {
+			return newTestResponse(http.StatusOK, `{"graphs":[{"id":"reading","name":"reading","unit":"pages","type":"int","color":"momiji","timezone":"UTC"}]}`), nil
+		}
+
+		// 53 weeks are longer than one request of 365 days
+		return newTestPixelsResponse(t, req, []pixela.PixelRecord{{Date: "20181230", Quantity: "7"}, {Date: "20191231", Quantity: "3"}}), nil
+	})
+
+	if got := app.Execute([]string{"graph", "render", "reading", "--date", "2019-12-31", "--username", "testuser", "--token", "testtoken"}); got != ExitNormal {
+		t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+	}
+
+	for _, want := range []string{"2018-12-30 7 pages", "2019-12-31 3 pages"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("SVG does not contain %q", want)
+		}
+	}
+}
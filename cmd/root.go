@@ -2,113 +2,201 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"runtime"
+	"strings"
+	"time"
 
 	"github.com/goark/gocli/exitcode"
 	"github.com/goark/gocli/rwi"
 	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-)
 
-// variable for configuration file name
-var (
-	cui     = rwi.New() // CUI instance
-	cfgFile string
+	"github.com/noissefnoc/pixela-client-go/pixela"
 )
 
-func newRootCmd(ui *rwi.RWI, args []string) *cobra.Command {
-	cui = ui
+// App is pixela command line application.
+// App holds its own settings, I/O and client factory instead of package globals,
+// so that it can be executed repeatedly in one process or mounted into other cobra commands.
+type App struct {
+	ui            *rwi.RWI
+	viper         *viper.Viper
+	cfgFile       string
+	clientFactory ClientFactory
+	// now returns current time (replaced in tests)
+	now func() time.Time
+}
+
+// ClientFactory creates pixe.la client (pixela.New by default)
+type ClientFactory func(username, token string, verbose bool, opts ...pixela.Option) (*pixela.Pixela, error)
+
+// AppOption is option for NewApp
+type AppOption func(*App)
+
+// WithClientFactory replaces pixe.la client factory (such as adding pixela.OptionHTTPClient)
+func WithClientFactory(factory ClientFactory) AppOption {
+	return func(a *App) {
+		a.clientFactory = factory
+	}
+}
+
+// NewApp creates pixela command line application with the I/O
+func NewApp(ui *rwi.RWI, opts ...AppOption) *App {
+	a := &App{
+		ui:            ui,
+		viper:         viper.New(),
+		clientFactory: pixela.New,
+		now:           time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// Command creates `pixela` root command which can be mounted into other cobra command.
+// settings are reset for each created command.
+func (a *App) Command() *cobra.Command {
+	a.viper = viper.New()
+	a.cfgFile = ""
 
 	rootCmd := &cobra.Command{
 		Use:   "pixela",
 		Short: "Simple API Client for pixela (https://pixe.la/)",
 		Long: `Simple API Client for pixela (https://pixe.la/).
 This command can handle user, graph, pixel and webhook via API.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := a.initConfig(); err != nil {
+				return err
+			}
+
+			return a.checkProfile(cmd)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return nil
 		},
 	}
 
 	// global flags
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.pixela.yaml)")
+	rootCmd.PersistentFlags().StringVar(&a.cfgFile, "config", "", "config file (default is $HOME/.pixela.yaml)")
+	rootCmd.PersistentFlags().StringP("profile", "", "", "profile in config file (default is current-profile)")
 	rootCmd.PersistentFlags().StringP("username", "u", "", "pixe.la username")
 	rootCmd.PersistentFlags().StringP("token", "t", "", "pixe.la user token")
 	rootCmd.PersistentFlags().BoolP("verbose", "n", false, "verbose mode")
+	rootCmd.PersistentFlags().StringP("output", "o", "", outputFlagUsage)
+	rootCmd.PersistentFlags().String("validation-mode", "strict", "argument validation mode (strict/lenient/off)")
+	rootCmd.PersistentFlags().String("validation-rules", "", "validation rules file overriding built-in rules")
+	rootCmd.PersistentFlags().Bool("skip-validation", false, "skip argument validation and leave it to pixe.la")
+	rootCmd.PersistentFlags().String("locale", "en", "locale of validation error messages (en/ja)")
+	rootCmd.PersistentFlags().Bool("check-quantity-type", false, "check quantity against graph type (int/float) before sending (default is true in strict validation mode)")
+	rootCmd.PersistentFlags().String("tz", "", "timezone resolving relative dates (default is graph timezone)")
+
+	for _, key := range []string{"profile", "username", "token", "verbose", "output", "validation-mode", "validation-rules", "skip-validation", "locale", "check-quantity-type", "tz"} {
+		a.viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(key))
+	}
 
-	viper.BindPFlag("username", rootCmd.PersistentFlags().Lookup("username"))
-	viper.BindPFlag("token", rootCmd.PersistentFlags().Lookup("token"))
-	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
-
-	rootCmd.SetArgs(args)
-	rootCmd.SetOutput(ui.ErrorWriter())
-
-	cobra.OnInitialize(initConfig)
-
-	rootCmd.AddCommand(newPixelCmd())
-	rootCmd.AddCommand(newGraphCmd())
-	rootCmd.AddCommand(newUserCmd())
-	rootCmd.AddCommand(newWebhookCmd())
+	rootCmd.SetOutput(a.ui.ErrorWriter())
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &usageError{err}
+	})
+
+	// replaced by `completion` subcommand completing graph IDs and webhook hashes
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+
+	rootCmd.AddCommand(a.newPixelCmd())
+	rootCmd.AddCommand(a.newGraphCmd())
+	rootCmd.AddCommand(a.newUserCmd())
+	rootCmd.AddCommand(a.newWebhookCmd())
+	rootCmd.AddCommand(a.newConfigCmd())
+	rootCmd.AddCommand(a.newInitCmd())
+	rootCmd.AddCommand(a.newCompletionCmd())
+	rootCmd.AddCommand(a.newBackupCmd())
+	rootCmd.AddCommand(a.newRestoreCmd())
+	rootCmd.AddCommand(a.newTimerCmd())
+	rootCmd.AddCommand(a.newWatchCmd())
+	rootCmd.AddCommand(a.newExecCmd())
+	rootCmd.AddCommand(a.newGitCmd())
 
 	return rootCmd
 }
 
-// Execute is method for wrapping command execution and catch panic
-func Execute(cui *rwi.RWI, args []string) (exit exitcode.ExitCode) {
+// Execute is method for wrapping command execution and catch panic.
+// exit code tells kind of error (see ExitUsage, ExitValidation and so on).
+func (a *App) Execute(args []string) (exit exitcode.ExitCode) {
 	defer func() {
 		// panic handling
 		if r := recover(); r != nil {
-			cui.OutputErrln("Panic:", r)
+			a.ui.OutputErrln("Panic:", r)
 			for depth := 0; ; depth++ {
 				pc, src, line, ok := runtime.Caller(depth)
 
 				if !ok {
 					break
 				}
-				cui.OutputErrln(" ->", depth, ":", runtime.FuncForPC(pc).Name(), ":", src, ":", line)
+				a.ui.OutputErrln(" ->", depth, ":", runtime.FuncForPC(pc).Name(), ":", src, ":", line)
 			}
-			exit = exitcode.Abnormal
+			exit = ExitPanic
 		}
 	}()
 
-	// execution
-	exit = exitcode.Normal
+	rootCmd := a.Command()
+	rootCmd.SetArgs(negativeArgs(rootCmd, args))
 
-	if err := newRootCmd(cui, args).Execute(); err != nil {
-		exit = exitcode.Abnormal
+	// shell completion reads candidates from standard output
+	if len(args) != 0 && strings.HasPrefix(args[0], cobra.ShellCompRequestCmd) {
+		rootCmd.SetOut(a.ui.Writer())
 	}
 
-	return
+	// execution
+	return exitCodeOf(rootCmd.Execute())
 }
 
-// initConfig reads in config file and ENV variables if set.
-func initConfig() {
-	if cfgFile != "" {
+// Execute is function for executing pixela command with the I/O
+func Execute(ui *rwi.RWI, args []string) exitcode.ExitCode {
+	return NewApp(ui).Execute(args)
+}
+
+// initConfig reads in config file (if exists) and ENV variables if set.
+// settings are resolved in order of flag, environment variable (PIXELA_*), profile and config file.
+func (a *App) initConfig() error {
+	// read in environment variables such as PIXELA_USERNAME and PIXELA_VALIDATION_MODE
+	a.viper.SetEnvPrefix("PIXELA")
+	a.viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	a.viper.AutomaticEnv()
+
+	if a.cfgFile != "" {
 		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
+		a.viper.SetConfigFile(a.cfgFile)
 	} else {
 		// Find home directory.
 		home, err := homedir.Dir()
+
 		if err != nil {
-			cui.OutputErrln(err)
-			os.Exit(1)
+			return errors.Wrap(err, "config error")
 		}
 
 		// Search config in home directory with name ".pixela" (without extension).
-		viper.AddConfigPath(home)
-		viper.SetConfigName(".pixela")
+		a.viper.AddConfigPath(home)
+		a.viper.SetConfigName(".pixela")
 	}
 
-	viper.AutomaticEnv() // read in environment variables that match
+	// config file is optional unless it is given by `--config`
+	if err := a.viper.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return wrapUsageError(err, "config error")
+		}
+	}
 
-	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err != nil {
-		cui.OutputErrln(err)
-		os.Exit(1)
+	if err := a.applyProfile(); err != nil {
+		return wrapUsageError(err, "config error")
 	}
 
-	if viper.GetBool("verbose") {
-		cui.Outputln(fmt.Sprintf("Using config file: %s", viper.ConfigFileUsed()))
+	if a.viper.GetBool("verbose") && a.viper.ConfigFileUsed() != "" {
+		a.ui.OutputErrln(fmt.Sprintf("Using config file: %s", a.viper.ConfigFileUsed()))
 	}
+
+	return nil
 }
@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+// color modes of `graph show`
+const (
+	colorModeAuto      = "auto"
+	colorModeTrueColor = "truecolor"
+	colorMode256       = "256"
+	colorModeASCII     = "ascii"
+)
+
+// characters of heatmap levels in ASCII mode
+var asciiLevels = []string{".", "-", "+", "*", "#"}
+
+func (a *App) newGraphShowCmd() *cobra.Command {
+	graphShowCmd := &cobra.Command{
+		Use:   "show",
+		Short: "show graph heatmap in terminal",
+		Long: `show graph heatmap (contribution grid) of last weeks in terminal. Usage:
+
+$ pixela graph show <graph id> [--weeks 26] [--date yyyyMMdd] [--color-mode auto/truecolor/256/ascii]
+
+date is last day of heatmap (today by default) and accepts yyyyMMdd, yyyy-MM-dd, today, yesterday, -3d, 3 days ago and last friday.
+colors follow graph color. --color-mode auto uses truecolor when COLORTERM is truecolor or 24bit,
+256 colors on other terminals and ASCII characters when output is not terminal or NO_COLOR is set.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 1 {
+				return usageErrorf("argument error: `graph show` requires 1 argument give %d arguments", len(args))
+			}
+
+			weeks, _ := cmd.Flags().GetInt("weeks")
+
+			if weeks < 1 {
+				return usageErrorf("argument error: --weeks must be positive")
+			}
+
+			colorMode, _ := cmd.Flags().GetString("color-mode")
+			mode, err := a.terminalColorMode(colorMode)
+
+			if err != nil {
+				return err
+			}
+
+			// do request
+			client, err := a.newClient()
+
+			if err != nil {
+				return err
+			}
+
+			graph, err := client.LookupGraph(args[0])
+
+			if err != nil {
+				return errors.Wrap(err, "request error")
+			}
+
+			dateStr, _ := cmd.Flags().GetString("date")
+
+			if dateStr, err = a.resolveDate(client, args[0], dateStr); err != nil {
+				return err
+			}
+
+			end, _ := time.Parse(pixela.DateFormat, dateStr)
+			start := pixela.HeatmapStart(end, weeks)
+
+			// weeks can be longer than 365 days of one request
+			pixels, err := client.GetGraphPixelsInRange(args[0], start.Format(pixela.DateFormat), end.Format(pixela.DateFormat))
+
+			if err != nil {
+				return errors.Wrap(err, "request error")
+			}
+
+			heatmap, err := pixela.NewHeatmap(pixels, end, weeks)
+
+			if err != nil {
+				return errors.Wrap(err, "response parse error")
+			}
+
+			a.ui.Outputln(strings.TrimRight(renderTerminalHeatmap(graph, heatmap, mode), "\n"))
+
+			return nil
+		},
+	}
+
+	graphShowCmd.Flags().Int("weeks", 26, "number of weeks")
+	graphShowCmd.Flags().String("date", "", "last day of heatmap")
+	graphShowCmd.Flags().String("color-mode", colorModeAuto, "color mode (auto/truecolor/256/ascii)")
+
+	graphShowCmd.RegisterFlagCompletionFunc("color-mode", completeValues(colorModeAuto, colorModeTrueColor, colorMode256, colorModeASCII))
+
+	return graphShowCmd
+}
+
+// resolve `auto` color mode by output and environment variables
+func (a *App) terminalColorMode(mode string) (string, error) {
+	switch mode {
+	case colorModeTrueColor, colorMode256, colorModeASCII:
+		return mode, nil
+	case colorModeAuto:
+	default:
+		return "", usageErrorf("argument error: unknown color mode `%s`: allows auto, truecolor, 256 and ascii", mode)
+	}
+
+	if !isTerminal(a.ui.Writer()) || os.Getenv("NO_COLOR") != "" {
+		return colorModeASCII, nil
+	}
+
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return colorModeTrueColor, nil
+	}
+
+	return colorMode256, nil
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// render heatmap with title, month and weekday labels and legend.
+// each day is 2 columns (block and space).
+func renderTerminalHeatmap(graph pixela.Graph, heatmap pixela.Heatmap, mode string) string {
+	palette := pixela.Palette(pixela.Color(graph.Color))
+
+	cell := func(level int) string {
+		switch mode {
+		case colorModeTrueColor:
+			c := palette[level]
+			return fmt.Sprintf("\x1b[38;2;%d;%d;%dm■\x1b[0m ", c.R, c.G, c.B)
+		case colorMode256:
+			return fmt.Sprintf("\x1b[38;5;%dm■\x1b[0m ", xterm256(palette[level]))
+		}
+
+		return asciiLevels[level] + " "
+	}
+
+	var b strings.Builder
+	const labelWidth = 4
+
+	// title
+	first := heatmap.Weeks[0][0].Date
+	last := first
+
+	for _, day := range heatmap.Weeks[len(heatmap.Weeks)-1] {
+		if day.InRange {
+			last = day.Date
+		}
+	}
+
+	title := fmt.Sprintf("%s (%s)  %s - %s  total %s %s", graph.Name, graph.ID,
+		first.Format("2006-01-02"), last.Format("2006-01-02"), formatQuantity(heatmap.Total), graph.Unit)
+	b.WriteString(strings.TrimSpace(title) + "\n")
+
+	// month labels at week which contains first day of month (and first week when it has room)
+	months := []byte(strings.Repeat(" ", labelWidth+2*len(heatmap.Weeks)+3))
+	next := 0
+
+	for w, week := range heatmap.Weeks {
+		label := ""
+
+		for _, day := range week {
+			if day.InRange && day.Date.Day() == 1 {
+				label = day.Date.Format("Jan")
+			}
+		}
+
+		if w == 0 && label == "" && week[0].Date.AddDate(0, 0, 14).Month() == week[0].Date.Month() {
+			label = week[0].Date.Format("Jan")
+		}
+
+		pos := labelWidth + 2*w
+
+		if label != "" && pos >= next {
+			copy(months[pos:], label)
+			next = pos + len(label) + 1
+		}
+	}
+
+	b.WriteString(strings.TrimRight(string(months), " ") + "\n")
+
+	// weekday rows
+	for d := 0; d < 7; d++ {
+		label := ""
+
+		switch time.Weekday(d) {
+		case time.Monday, time.Wednesday, time.Friday:
+			label = time.Weekday(d).String()[:3]
+		}
+
+		row := fmt.Sprintf("%-*s", labelWidth, label)
+
+		for _, week := range heatmap.Weeks {
+			if week[d].InRange {
+				row += cell(week[d].Level)
+			}
+		}
+
+		b.WriteString(strings.TrimRight(row, " ") + "\n")
+	}
+
+	// legend
+	legend := strings.Repeat(" ", labelWidth) + "Less "
+
+	for level := 0; level <= pixela.HeatmapLevels; level++ {
+		legend += cell(level)
+	}
+
+	fmt.Fprintf(&b, "%sMore  (max %s)\n", legend, strings.TrimSpace(formatQuantity(heatmap.Max)+" "+graph.Unit))
+
+	return b.String()
+}
+
+// nearest color of xterm 256 color cube (16-231)
+func xterm256(c pixela.RGB) int {
+	level := func(v uint8) int {
+		return (int(v)*5 + 127) / 255
+	}
+
+	return 16 + 36*level(c.R) + 6*level(c.G) + level(c.B)
+}
+
+func formatQuantity(q float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.4f", q), "0"), ".")
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+func TestApp_GraphShow(t *testing.T) {
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/graphs") {
+			return newTestResponse(http.StatusOK, `{"graphs":[{"id":"graphid","name":"reading","unit":"pages","type":"int","color":"sora","timezone":"UTC"}]}`), nil
+		}
+
+		if from := req.URL.Query().Get("from"); from != "20190120" {
+			t.Fatalf("want from 20190120, but %s", from)
+		}
+
+		return newTestResponse(http.StatusOK, `{"pixels":[{"date":"20190121","quantity":"4"},{"date":"20190201","quantity":"1"},{"date":"20190206","quantity":"3"}]}`), nil
+	})
+
+	args := []string{"graph", "show", "graphid", "--weeks", "3", "--date", "20190206", "--username", "testuser", "--token", "testtoken"}
+
+	if got := app.Execute(args); got != ExitNormal {
+		t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+	}
+
+	want := strings.Join([]string{
+		"reading (graphid)  2019-01-20 - 2019-02-06  total 8 pages",
+		"      Feb",
+		"    . . .",
+		"Mon # . .",
+		"    . . .",
+		"Wed . . *",
+		"    . .",
+		"Fri . -",
+		"    . .",
+		"    Less . - + * # More  (max 4 pages)",
+		"",
+	}, "\n")
+
+	if out.String() != want {
+		t.Fatalf("want\n%s\nbut\n%s", want, out.String())
+	}
+}
+
+func TestApp_GraphShowManyWeeks(t *testing.T) {
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/graphs") {
+			return newTestResponse(http.StatusOK, `{"graphs":[{"id":"graphid","name":"reading","unit":"pages","type":"int","color":"sora","timezone":"UTC"}]}`), nil
+		}
+
+		// 60 weeks are longer than one request of 365 days
+		return newTestPixelsResponse(t, req, []pixela.PixelRecord{{Date: "20181201", Quantity: "4"}, {Date: "20191231", Quantity: "3"}}), nil
+	})
+
+	args := []string{"graph", "show", "graphid", "--weeks", "60", "--date", "20191231", "--username", "testuser", "--token", "testtoken"}
+
+	if got := app.Execute(args); got != ExitNormal {
+		t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+	}
+
+	if !strings.HasPrefix(out.String(), "reading (graphid)  2018-11-11 - 2019-12-31  total 7 pages\n") {
+		t.Fatalf("unexpected heatmap:\n%s", out.String())
+	}
+}
+
+func TestApp_TerminalColorMode(t *testing.T) {
+	app, _, _ := newTestAppWithTransport(t, nil)
+
+	for _, mode := range []string{colorModeTrueColor, colorMode256, colorModeASCII} {
+		if got, _ := app.terminalColorMode(mode); got != mode {
+			t.Fatalf("want %s, but %s", mode, got)
+		}
+	}
+
+	// buffer is not terminal
+	if got, _ := app.terminalColorMode(colorModeAuto); got != colorModeASCII {
+		t.Fatalf("want %s, but %s", colorModeASCII, got)
+	}
+
+	if _, err := app.terminalColorMode("16"); exitCodeOf(err) != ExitUsage {
+		t.Fatalf("want usage error, but %v", err)
+	}
+}
+
+func TestRenderTerminalHeatmap(t *testing.T) {
+	graph := pixela.Graph{ID: "graphid", Name: "reading", Color: "shibafu"}
+	heatmap, _ := pixela.NewHeatmap([]pixela.PixelRecord{{Date: "20190101", Quantity: "1"}}, time.Date(2019, 1, 5, 0, 0, 0, 0, time.UTC), 1)
+
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{colorModeTrueColor, "\n    \x1b[38;2;57;168;29m■\x1b[0m\n"},
+		{colorMode256, "\n    \x1b[38;5;71m■\x1b[0m\n"},
+		{colorModeASCII, "\n    #\n"},
+	}
+
+	for _, tt := range tests {
+		if got := renderTerminalHeatmap(graph, heatmap, tt.mode); !strings.Contains(got, tt.want) {
+			t.Fatalf("%s: want %q in\n%s", tt.mode, tt.want, got)
+		}
+	}
+}
+
+func TestXterm256(t *testing.T) {
+	tests := map[pixela.RGB]int{
+		{R: 0, G: 0, B: 0}:          16,
+		{R: 0xff, G: 0xff, B: 0xff}: 231,
+		{R: 0xee, G: 0xee, B: 0xee}: 231,
+		{R: 0xff, G: 0, B: 0}:       196,
+	}
+
+	for c, want := range tests {
+		if got := xterm256(c); got != want {
+			t.Fatalf("%s: want %d, but %d", c.Hex(), want, got)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// path of local state file (such as running timers) of current user.
+// state directory is `state-dir` setting (PIXELA_STATE_DIR) or pixela directory under user config directory.
+func (a *App) statePath(name string) (string, error) {
+	if a.viper.GetString("username") == "" {
+		return "", usageErrorf("config error: username is required for local state")
+	}
+
+	dir := a.viper.GetString("state-dir")
+
+	if dir == "" {
+		configDir, err := os.UserConfigDir()
+
+		if err != nil {
+			return "", err
+		}
+
+		dir = filepath.Join(configDir, "pixela")
+	}
+
+	return filepath.Join(dir, a.userFileName(name)), nil
+}
+
+// file name of current user (username is hashed, so it is not used as path as it is)
+func (a *App) userFileName(name string) string {
+	sum := sha256.Sum256([]byte(a.viper.GetString("username")))
+
+	return fmt.Sprintf("%s-%s.json", name, hex.EncodeToString(sum[:8]))
+}
+
+// load JSON state (v is not changed when state file does not exist)
+func loadState(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return usageErrorf("input error: state file %s is broken: %s", path, err)
+	}
+
+	return nil
+}
+
+// save JSON state atomically
+func saveState(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	return writeFileAtomically(path, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(v)
+	})
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApp_StatePath(t *testing.T) {
+	app, _, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		t.Fatalf("unexpected request: %s %s", req.Method, req.URL)
+		return nil, nil
+	})
+
+	dir := os.Getenv("PIXELA_STATE_DIR")
+
+	// state is not touched without username
+	for _, args := range [][]string{
+		{"timer", "start", "focus"},
+		{"timer", "status"},
+	} {
+		errOut.Reset()
+
+		if got := app.Execute(args); got != ExitUsage {
+			t.Fatalf("%v: want %d, but %d: %s", args, ExitUsage, got, errOut.String())
+		}
+	}
+
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 0 {
+		t.Fatalf("unexpected state files: %v", files)
+	}
+
+	// username is not used as file name as it is
+	if got := app.Execute([]string{"timer", "start", "focus", "--username", "../x"}); got != ExitNormal {
+		t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "timer-*.json"))
+
+	if err != nil || len(files) != 1 || strings.Contains(filepath.Base(files[0]), "x") {
+		t.Fatalf("unexpected state files: %v (%v)", files, err)
+	}
+}
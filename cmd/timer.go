@@ -0,0 +1,612 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+// timerState is local state of timers (by graph ID)
+type timerState struct {
+	Timers map[string]*graphTimer `json:"timers"`
+}
+
+// graphTimer is timer of graph. last segment has no end while timer is running.
+type graphTimer struct {
+	GraphID  string         `json:"graphID"`
+	Segments []timerSegment `json:"segments"`
+	Notes    []string       `json:"notes,omitempty"`
+	// Recorded is dates already added to pixels by stop which failed midway (skipped on retry)
+	Recorded []string `json:"recorded,omitempty"`
+}
+
+type timerSegment struct {
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end,omitempty"`
+}
+
+// timerStatus is output of `timer status`
+type timerStatus struct {
+	GraphID   string    `json:"graphID"`
+	State     string    `json:"state"`
+	StartedAt time.Time `json:"startedAt"`
+	Elapsed   string    `json:"elapsed"`
+	Minutes   int       `json:"minutes"`
+	Notes     []string  `json:"notes,omitempty"`
+}
+
+func (t *graphTimer) running() bool {
+	return len(t.Segments) != 0 && t.Segments[len(t.Segments)-1].End == nil
+}
+
+func (t *graphTimer) elapsed(now time.Time) time.Duration {
+	var elapsed time.Duration
+
+	for _, s := range t.Segments {
+		end := now
+
+		if s.End != nil {
+			end = *s.End
+		}
+
+		elapsed += end.Sub(s.Start)
+	}
+
+	return elapsed
+}
+
+// close running segment at the time
+func (t *graphTimer) pause(at time.Time) error {
+	last := &t.Segments[len(t.Segments)-1]
+
+	if at.Before(last.Start) {
+		return usageErrorf("argument error: %s is before timer is started or resumed (%s)", at.Format("2006-01-02 15:04"), last.Start.Local().Format("2006-01-02 15:04"))
+	}
+
+	last.End = &at
+
+	return nil
+}
+
+func (t *graphTimer) status(now time.Time) timerStatus {
+	state := "paused"
+
+	if t.running() {
+		state = "running"
+	}
+
+	elapsed := t.elapsed(now)
+
+	return timerStatus{
+		GraphID:   t.GraphID,
+		State:     state,
+		StartedAt: t.Segments[0].Start,
+		Elapsed:   formatElapsed(elapsed),
+		Minutes:   int(elapsed.Minutes()),
+		Notes:     t.Notes,
+	}
+}
+
+func formatElapsed(d time.Duration) string {
+	d = d.Truncate(time.Minute)
+
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// durations of segments per day (yyyyMMdd) in the location. segment over midnight is split into both days.
+func dailyDurations(segments []timerSegment, loc *time.Location) map[string]time.Duration {
+	durations := map[string]time.Duration{}
+
+	for _, s := range segments {
+		start, end := s.Start.In(loc), s.End.In(loc)
+
+		for start.Before(end) {
+			midnight := time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, loc)
+			until := end
+
+			if midnight.Before(end) {
+				until = midnight
+			}
+
+			durations[start.Format(pixela.DateFormat)] += until.Sub(start)
+			start = until
+		}
+	}
+
+	return durations
+}
+
+// minutes for int graph and hours for float graph
+func timerQuantity(d time.Duration, numType pixela.NumType) (string, error) {
+	if numType == pixela.NumTypeFloat {
+		return pixela.ConvertQuantity(numType)(strconv.FormatFloat(d.Hours(), 'f', 2, 64))
+	}
+
+	return pixela.ConvertQuantity(pixela.NumTypeInt)(strconv.FormatFloat(d.Minutes(), 'f', 2, 64))
+}
+
+// parse `--at` flag (HH:MM of today or yesterday, `yyyy-MM-dd HH:MM` or RFC3339) in local time
+func parseTimerAt(at string, now time.Time) (time.Time, error) {
+	if at == "" {
+		return now, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, at); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02 15:04", at, time.Local); err == nil {
+		return t, nil
+	}
+
+	clock, err := time.ParseInLocation("15:04", at, time.Local)
+
+	if err != nil {
+		return time.Time{}, usageErrorf("argument error: --at `%s` is not HH:MM, yyyy-MM-dd HH:MM or RFC3339", at)
+	}
+
+	local := now.Local()
+	t := time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, time.Local)
+
+	if t.After(now) {
+		t = t.AddDate(0, 0, -1)
+	}
+
+	return t, nil
+}
+
+func (a *App) loadTimerState() (string, *timerState, error) {
+	path, err := a.statePath("timer")
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	state := &timerState{}
+
+	if err := loadState(path, state); err != nil {
+		return "", nil, err
+	}
+
+	if state.Timers == nil {
+		state.Timers = map[string]*graphTimer{}
+	}
+
+	return path, state, nil
+}
+
+func (a *App) newTimerCmd() *cobra.Command {
+	timerCmd := &cobra.Command{
+		Use:   "timer",
+		Short: "measure time locally and record it into graph (start, pause, resume, stop and status)",
+		Long: `measure time by local timer and record it into graph.
+timer state is kept in local state file, so timer can be paused, annotated and recovered after sleep.
+on stop, elapsed minutes (int graph) or hours (float graph) are added to pixel of the day.
+session over midnight (in graph timezone) is split into both days.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.Help()
+			return nil
+		},
+	}
+
+	timerCmd.AddCommand(a.newTimerStartCmd())
+	timerCmd.AddCommand(a.newTimerPauseCmd())
+	timerCmd.AddCommand(a.newTimerResumeCmd())
+	timerCmd.AddCommand(a.newTimerStopCmd())
+	timerCmd.AddCommand(a.newTimerStatusCmd())
+
+	return timerCmd
+}
+
+func (a *App) newTimerStartCmd() *cobra.Command {
+	timerStartCmd := &cobra.Command{
+		Use:   "start",
+		Short: "start timer",
+		Long: `start timer of graph. Usage:
+
+$ pixela timer start <graph id> [--note text] [--at HH:MM]`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 1 {
+				return usageErrorf("argument error: `timer start` requires 1 argument give %d arguments", len(args))
+			}
+
+			at, _ := cmd.Flags().GetString("at")
+			now, err := parseTimerAt(at, a.now())
+
+			if err != nil {
+				return err
+			}
+
+			path, state, err := a.loadTimerState()
+
+			if err != nil {
+				return err
+			}
+
+			if timer, ok := state.Timers[args[0]]; ok {
+				if timer.running() {
+					return usageErrorf("argument error: timer of `%s` is already running", args[0])
+				}
+
+				return usageErrorf("argument error: timer of `%s` is paused (use `timer resume` or `timer stop`)", args[0])
+			}
+
+			notes, _ := cmd.Flags().GetStringArray("note")
+			state.Timers[args[0]] = &graphTimer{GraphID: args[0], Segments: []timerSegment{{Start: now}}, Notes: notes}
+
+			if err := saveState(path, state); err != nil {
+				return err
+			}
+
+			a.printStatus(fmt.Sprintf("timer of `%s` is started at %s", args[0], now.Local().Format("15:04")))
+
+			return nil
+		},
+	}
+
+	timerStartCmd.Flags().StringArray("note", nil, "note recorded into optionalData")
+	timerStartCmd.Flags().String("at", "", "start time (HH:MM, yyyy-MM-dd HH:MM or RFC3339)")
+
+	return timerStartCmd
+}
+
+func (a *App) newTimerPauseCmd() *cobra.Command {
+	timerPauseCmd := &cobra.Command{
+		Use:   "pause",
+		Short: "pause timer",
+		Long: `pause timer of graph. Usage:
+
+$ pixela timer pause <graph id> [--note text] [--at HH:MM]
+
+--at pauses timer at the time (such as when laptop went to sleep).`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 1 {
+				return usageErrorf("argument error: `timer pause` requires 1 argument give %d arguments", len(args))
+			}
+
+			at, _ := cmd.Flags().GetString("at")
+			now, err := parseTimerAt(at, a.now())
+
+			if err != nil {
+				return err
+			}
+
+			path, state, err := a.loadTimerState()
+
+			if err != nil {
+				return err
+			}
+
+			timer, ok := state.Timers[args[0]]
+
+			if !ok || !timer.running() {
+				return usageErrorf("argument error: timer of `%s` is not running", args[0])
+			}
+
+			if err := timer.pause(now); err != nil {
+				return err
+			}
+
+			notes, _ := cmd.Flags().GetStringArray("note")
+			timer.Notes = append(timer.Notes, notes...)
+
+			if err := saveState(path, state); err != nil {
+				return err
+			}
+
+			a.printStatus(fmt.Sprintf("timer of `%s` is paused (%s elapsed)", args[0], formatElapsed(timer.elapsed(now))))
+
+			return nil
+		},
+	}
+
+	timerPauseCmd.Flags().StringArray("note", nil, "note recorded into optionalData")
+	timerPauseCmd.Flags().String("at", "", "pause time (HH:MM, yyyy-MM-dd HH:MM or RFC3339)")
+
+	return timerPauseCmd
+}
+
+func (a *App) newTimerResumeCmd() *cobra.Command {
+	timerResumeCmd := &cobra.Command{
+		Use:   "resume",
+		Short: "resume timer",
+		Long: `resume paused timer of graph. Usage:
+
+$ pixela timer resume <graph id> [--at HH:MM]`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 1 {
+				return usageErrorf("argument error: `timer resume` requires 1 argument give %d arguments", len(args))
+			}
+
+			at, _ := cmd.Flags().GetString("at")
+			now, err := parseTimerAt(at, a.now())
+
+			if err != nil {
+				return err
+			}
+
+			path, state, err := a.loadTimerState()
+
+			if err != nil {
+				return err
+			}
+
+			timer, ok := state.Timers[args[0]]
+
+			if !ok || timer.running() {
+				return usageErrorf("argument error: timer of `%s` is not paused", args[0])
+			}
+
+			if len(timer.Recorded) != 0 {
+				return usageErrorf("argument error: timer of `%s` is partially recorded (retry `timer stop`)", args[0])
+			}
+
+			if last := timer.Segments[len(timer.Segments)-1]; now.Before(*last.End) {
+				return usageErrorf("argument error: %s is before timer is paused (%s)", now.Format("2006-01-02 15:04"), last.End.Local().Format("2006-01-02 15:04"))
+			}
+
+			timer.Segments = append(timer.Segments, timerSegment{Start: now})
+
+			if err := saveState(path, state); err != nil {
+				return err
+			}
+
+			a.printStatus(fmt.Sprintf("timer of `%s` is resumed (%s elapsed)", args[0], formatElapsed(timer.elapsed(now))))
+
+			return nil
+		},
+	}
+
+	timerResumeCmd.Flags().String("at", "", "resume time (HH:MM, yyyy-MM-dd HH:MM or RFC3339)")
+
+	return timerResumeCmd
+}
+
+func (a *App) newTimerStopCmd() *cobra.Command {
+	timerStopCmd := &cobra.Command{
+		Use:   "stop",
+		Short: "stop timer and record elapsed time",
+		Long: `stop timer of graph and add elapsed time to pixel of the day. Usage:
+
+$ pixela timer stop <graph id> [--note text] [--at HH:MM] [--discard]
+
+elapsed time is minutes for int graph and hours for float graph.
+session over midnight is split into both days in graph timezone (or --tz).
+notes are recorded into optionalData ("notes" array).
+timer is kept (paused) when request fails, so stop can be retried
+(days already recorded are not added again).
+--discard stops timer without recording.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 1 {
+				return usageErrorf("argument error: `timer stop` requires 1 argument give %d arguments", len(args))
+			}
+
+			at, _ := cmd.Flags().GetString("at")
+			now, err := parseTimerAt(at, a.now())
+
+			if err != nil {
+				return err
+			}
+
+			path, state, err := a.loadTimerState()
+
+			if err != nil {
+				return err
+			}
+
+			timer, ok := state.Timers[args[0]]
+
+			if !ok {
+				return usageErrorf("argument error: timer of `%s` is not started", args[0])
+			}
+
+			if discard, _ := cmd.Flags().GetBool("discard"); discard {
+				delete(state.Timers, args[0])
+
+				if err := saveState(path, state); err != nil {
+					return err
+				}
+
+				a.printStatus(fmt.Sprintf("timer of `%s` is discarded", args[0]))
+
+				return nil
+			}
+
+			if timer.running() {
+				if err := timer.pause(now); err != nil {
+					return err
+				}
+			}
+
+			notes, _ := cmd.Flags().GetStringArray("note")
+			timer.Notes = append(timer.Notes, notes...)
+
+			// keep paused timer until it is recorded
+			if err := saveState(path, state); err != nil {
+				return err
+			}
+
+			// save each recorded day, so retry does not add it again
+			pixels, err := a.recordTimer(timer, func(date string) error {
+				timer.Recorded = append(timer.Recorded, date)
+
+				return saveState(path, state)
+			})
+
+			if err != nil {
+				return err
+			}
+
+			for _, p := range pixels {
+				a.printStatus(fmt.Sprintf("%s +%s", p.Date, p.Quantity))
+			}
+
+			delete(state.Timers, args[0])
+
+			if err := saveState(path, state); err != nil {
+				return err
+			}
+
+			a.printStatus(fmt.Sprintf("timer of `%s` is stopped (%s)", args[0], formatElapsed(timer.elapsed(now))))
+
+			return nil
+		},
+	}
+
+	timerStopCmd.Flags().StringArray("note", nil, "note recorded into optionalData")
+	timerStopCmd.Flags().String("at", "", "stop time (HH:MM, yyyy-MM-dd HH:MM or RFC3339)")
+	timerStopCmd.Flags().Bool("discard", false, "stop timer without recording")
+
+	return timerStopCmd
+}
+
+// record stopped timer into graph and return recorded pixels.
+// dates in timer.Recorded are skipped and recorded is called after each date is recorded.
+func (a *App) recordTimer(timer *graphTimer, recorded func(date string) error) ([]pixela.PixelRecord, error) {
+	client, err := a.newClient()
+
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := client.LookupGraph(timer.GraphID)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "request error")
+	}
+
+	loc, err := a.dateLocation(client, timer.GraphID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	optionalData := ""
+
+	if len(timer.Notes) != 0 {
+		out, err := formatJSON(map[string][]string{"notes": timer.Notes}, false)
+
+		if err != nil {
+			return nil, err
+		}
+
+		optionalData = out
+	}
+
+	durations := dailyDurations(timer.Segments, loc)
+	dates := make([]string, 0, len(durations))
+
+	for date := range durations {
+		dates = append(dates, date)
+	}
+
+	sort.Strings(dates)
+
+	skip := map[string]bool{}
+
+	for _, date := range timer.Recorded {
+		skip[date] = true
+	}
+
+	var pixels []pixela.PixelRecord
+
+	for _, date := range dates {
+		if skip[date] {
+			continue
+		}
+
+		quantity, err := timerQuantity(durations[date], pixela.NumType(graph.Type))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if q, _ := strconv.ParseFloat(quantity, 64); q == 0 {
+			continue
+		}
+
+		pixels = append(pixels, pixela.PixelRecord{Date: date, Quantity: quantity, OptionalData: optionalData})
+	}
+
+	done := func(change pixela.ImportChange) error {
+		return recorded(change.New.Date)
+	}
+
+	if _, err := client.RecordPixels(timer.GraphID, pixels, pixela.ImportAdd, done); err != nil {
+		return nil, errors.Wrap(err, "request error")
+	}
+
+	return pixels, nil
+}
+
+func (a *App) newTimerStatusCmd() *cobra.Command {
+	timerStatusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "show timers",
+		Long: `show timer of graph (or all timers). Usage:
+
+$ pixela timer status [graph id]`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) > 1 {
+				return usageErrorf("argument error: `timer status` accepts at most 1 argument give %d arguments", len(args))
+			}
+
+			_, state, err := a.loadTimerState()
+
+			if err != nil {
+				return err
+			}
+
+			now := a.now()
+			var statuses []timerStatus
+
+			for graphID, timer := range state.Timers {
+				if len(args) == 0 || args[0] == graphID {
+					statuses = append(statuses, timer.status(now))
+				}
+			}
+
+			if len(args) == 1 && len(statuses) == 0 {
+				return usageErrorf("argument error: timer of `%s` is not started", args[0])
+			}
+
+			sort.Slice(statuses, func(i, j int) bool {
+				return statuses[i].GraphID < statuses[j].GraphID
+			})
+
+			if a.viper.GetString("output") != "" {
+				return a.printOutput(statuses)
+			}
+
+			lines := []string{}
+
+			for _, s := range statuses {
+				lines = append(lines, fmt.Sprintf("%s: %s %s (started at %s)", s.GraphID, s.State, s.Elapsed, s.StartedAt.Local().Format("2006-01-02 15:04")))
+			}
+
+			return a.printOutput(lines)
+		},
+	}
+
+	return timerStatusCmd
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goark/gocli/exitcode"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+func TestApp_Timer(t *testing.T) {
+	auth := []string{"--username", "testuser", "--token", "testtoken"}
+	var requests []string
+
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/graphs"):
+			return newTestResponse(http.StatusOK, `{"graphs":[{"id":"focus","type":"int","timezone":"Asia/Tokyo"}]}`), nil
+		case req.Method == http.MethodGet:
+			return newTestResponse(http.StatusOK, `{"pixels":[{"date":"20190101","quantity":"10"}]}`), nil
+		}
+
+		body, _ := ioutil.ReadAll(req.Body)
+		requests = append(requests, req.Method+" "+req.URL.Path+" "+string(body))
+
+		return newTestResponse(http.StatusOK, `{"message":"Success.","isSuccess":true}`), nil
+	})
+
+	tokyo := time.FixedZone("JST", 9*60*60)
+	now := time.Date(2019, 1, 1, 23, 30, 0, 0, tokyo)
+	app.now = func() time.Time { return now }
+
+	run := func(args ...string) {
+		t.Helper()
+
+		if got := app.Execute(append(args, auth...)); got != ExitNormal {
+			t.Fatalf("%v: want %d, but %d: %s", args, ExitNormal, got, errOut.String())
+		}
+	}
+
+	// 23:30-23:50 and 00:00-00:45 (next day)
+	run("timer", "start", "focus", "--note", "writing")
+	now = now.Add(20 * time.Minute)
+	run("timer", "pause", "focus")
+
+	if got := app.Execute(append([]string{"timer", "pause", "focus"}, auth...)); got != ExitUsage {
+		t.Fatalf("want %d, but %d", ExitUsage, got)
+	}
+
+	now = now.Add(10 * time.Minute)
+	run("timer", "resume", "focus")
+	now = now.Add(45 * time.Minute)
+
+	out.Reset()
+	run("timer", "status", "focus", "-o", "json")
+
+	if !strings.Contains(out.String(), `"state":"running","startedAt":"2019-01-01T23:30:00+09:00","elapsed":"1h05m","minutes":65`) {
+		t.Fatalf("unexpected status: %s", out.String())
+	}
+
+	out.Reset()
+	run("timer", "stop", "focus", "--note", "done")
+
+	want := []string{
+		`PUT /v1/users/testuser/graphs/focus/20190101 {"date":"","quantity":"30","optionalData":"{\"notes\":[\"writing\",\"done\"]}"}`,
+		`POST /v1/users/testuser/graphs/focus {"date":"20190102","quantity":"45","optionalData":"{\"notes\":[\"writing\",\"done\"]}"}`,
+	}
+
+	if !reflect.DeepEqual(requests, want) {
+		t.Fatalf("want\n%s\nbut\n%s", strings.Join(want, "\n"), strings.Join(requests, "\n"))
+	}
+
+	if want := "20190101 +20\n20190102 +45\ntimer of `focus` is stopped (1h05m)\n"; out.String() != want {
+		t.Fatalf("want %q, but %q", want, out.String())
+	}
+
+	// timer is removed
+	if got := app.Execute(append([]string{"timer", "status", "focus"}, auth...)); got != ExitUsage {
+		t.Fatalf("want %d, but %d", ExitUsage, got)
+	}
+}
+
+func TestApp_TimerStopRetry(t *testing.T) {
+	auth := []string{"--username", "testuser", "--token", "testtoken"}
+	var requests []string
+	fail := true
+
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/graphs"):
+			return newTestResponse(http.StatusOK, `{"graphs":[{"id":"focus","type":"int","timezone":"Asia/Tokyo"}]}`), nil
+		case req.Method == http.MethodGet:
+			return newTestResponse(http.StatusOK, `{"pixels":[]}`), nil
+		}
+
+		requests = append(requests, req.Method+" "+req.URL.Path)
+
+		// pixel of the second day fails once
+		if body, _ := ioutil.ReadAll(req.Body); fail && strings.Contains(string(body), "20190102") {
+			fail = false
+
+			return newTestResponse(http.StatusServiceUnavailable, `{"message":"unavailable","isSuccess":false}`), nil
+		}
+
+		return newTestResponse(http.StatusOK, `{"message":"Success.","isSuccess":true}`), nil
+	})
+
+	now := time.Date(2019, 1, 1, 23, 30, 0, 0, time.FixedZone("JST", 9*60*60))
+	app.now = func() time.Time { return now }
+
+	run := func(args ...string) exitcode.ExitCode {
+		return app.Execute(append(args, auth...))
+	}
+
+	if got := run("timer", "start", "focus"); got != ExitNormal {
+		t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+	}
+
+	now = now.Add(time.Hour)
+
+	if got := run("timer", "stop", "focus"); got == ExitNormal {
+		t.Fatalf("want error, but %d", got)
+	}
+
+	// partially recorded timer can not be resumed
+	if got := run("timer", "resume", "focus"); got != ExitUsage {
+		t.Fatalf("want %d, but %d", ExitUsage, got)
+	}
+
+	out.Reset()
+
+	if got := run("timer", "stop", "focus"); got != ExitNormal {
+		t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+	}
+
+	// the first day is not added again
+	want := []string{
+		"POST /v1/users/testuser/graphs/focus",
+		"POST /v1/users/testuser/graphs/focus",
+		"POST /v1/users/testuser/graphs/focus",
+	}
+
+	if !reflect.DeepEqual(requests, want) {
+		t.Fatalf("want %v, but %v", want, requests)
+	}
+
+	if want := "20190102 +30\ntimer of `focus` is stopped (1h00m)\n"; out.String() != want {
+		t.Fatalf("want %q, but %q", want, out.String())
+	}
+}
+
+func TestDailyDurations(t *testing.T) {
+	loc := time.FixedZone("JST", 9*60*60)
+	at := func(day, hour, min int) *time.Time {
+		t := time.Date(2019, 1, day, hour, min, 0, 0, loc)
+		return &t
+	}
+
+	segments := []timerSegment{
+		{Start: *at(1, 22, 0), End: at(3, 1, 0)},
+		{Start: *at(3, 9, 0), End: at(3, 9, 30)},
+	}
+
+	want := map[string]time.Duration{
+		"20190101": 2 * time.Hour,
+		"20190102": 24 * time.Hour,
+		"20190103": 90 * time.Minute,
+	}
+
+	if got := dailyDurations(segments, loc); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, but %v", want, got)
+	}
+
+	// split in UTC
+	if got := dailyDurations(segments[1:], time.UTC); !reflect.DeepEqual(got, map[string]time.Duration{"20190103": 30 * time.Minute}) {
+		t.Fatalf("unexpected durations: %v", got)
+	}
+}
+
+func TestTimerQuantity(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		numType  string
+		want     string
+	}{
+		{90 * time.Second, "int", "2"},
+		{89 * time.Second, "int", "1"},
+		{90 * time.Minute, "float", "1.5"},
+		{20 * time.Minute, "float", "0.33"},
+	}
+
+	for _, tt := range tests {
+		if got, _ := timerQuantity(tt.duration, pixela.NumType(tt.numType)); got != tt.want {
+			t.Fatalf("%s (%s): want %s, but %s", tt.duration, tt.numType, tt.want, got)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -10,9 +9,6 @@ import (
 	"github.com/mitchellh/go-homedir"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
-
-	"github.com/noissefnoc/pixela-client-go/pixela"
 )
 
 // UserCreateOptions is struct for `user create` subcommand
@@ -21,7 +17,7 @@ type UserCreateOptions struct {
 	NotMinor            string
 }
 
-func newUserCmd() *cobra.Command {
+func (a *App) newUserCmd() *cobra.Command {
 	userCmd := &cobra.Command{
 		Use:   "user",
 		Short: "handle user subcommands (create, update and delete)",
@@ -33,14 +29,14 @@ see official document (https://docs.pixe.la) for more detail`,
 		},
 	}
 
-	userCmd.AddCommand(newUserCreateCmd())
-	userCmd.AddCommand(newUserUpdateCmd())
-	userCmd.AddCommand(newUserDeleteCmd())
+	userCmd.AddCommand(a.newUserCreateCmd())
+	userCmd.AddCommand(a.newUserUpdateCmd())
+	userCmd.AddCommand(a.newUserDeleteCmd())
 
 	return userCmd
 }
 
-func newUserCreateCmd() *cobra.Command {
+func (a *App) newUserCreateCmd() *cobra.Command {
 	userCreateCmd := &cobra.Command{
 		Use:   "create",
 		Short: "create pixe.la user",
@@ -52,14 +48,14 @@ see official document (https://docs.pixe.la/#/post-user) for more detail.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// check arguments
 			if len(args) != 2 {
-				return fmt.Errorf("argument error: `user create` requires 2 arguments give %d arguments", len(args))
+				return usageErrorf("argument error: `user create` requires 2 arguments give %d arguments", len(args))
 			}
 
 			username := args[0]
 			token := args[1]
 
 			// do request
-			client, err := pixela.New(username, token, viper.GetBool("verbose"))
+			client, err := a.newClientWithAuth(username, token)
 
 			if err != nil {
 				return err
@@ -74,20 +70,14 @@ see official document (https://docs.pixe.la/#/post-user) for more detail.`,
 				return errors.Wrap(err, "request error")
 			}
 
-			responseJSON, err := json.Marshal(response)
+			err = a.printResult(response)
 
 			if err != nil {
-				return errors.Wrap(err, "response parse error")
-			}
-
-			// print result in verbose mode
-			if viper.GetBool("verbose") {
-				cui.Outputln(string(responseJSON))
+				return err
 			}
 
 			// save authentications into file
-			configFilePath := viper.GetString("config")
-			err = saveConfigFile(configFilePath, username, token)
+			err = a.saveConfigFile(a.cfgFile, username, token)
 
 			if err != nil {
 				return errors.Wrap(err, "save config file error")
@@ -103,7 +93,7 @@ see official document (https://docs.pixe.la/#/post-user) for more detail.`,
 	return userCreateCmd
 }
 
-func newUserUpdateCmd() *cobra.Command {
+func (a *App) newUserUpdateCmd() *cobra.Command {
 	userUpdateCmd := &cobra.Command{
 		Use:   "update",
 		Short: "update user token",
@@ -115,11 +105,11 @@ see official document (https://docs.pixe.la/#/put-user) for more detail.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// check arguments
 			if len(args) != 1 {
-				return fmt.Errorf("argument error: `user update` requires 1 arguments give %d arguments", len(args))
+				return usageErrorf("argument error: `user update` requires 1 arguments give %d arguments", len(args))
 			}
 
 			// do request
-			client, err := pixela.New(viper.GetString("username"), viper.GetString("token"), viper.GetBool("verbose"))
+			client, err := a.newClient()
 
 			if err != nil {
 				return err
@@ -131,25 +121,14 @@ see official document (https://docs.pixe.la/#/put-user) for more detail.`,
 				return errors.Wrap(err, "request error")
 			}
 
-			responseJSON, err := json.Marshal(response)
-
-			if err != nil {
-				return errors.Wrap(err, "response parse error")
-			}
-
-			// print result in verbose mode
-			if viper.GetBool("verbose") {
-				cui.Outputln(string(responseJSON))
-			}
-
-			return nil
+			return a.printResult(response)
 		},
 	}
 
 	return userUpdateCmd
 }
 
-func newUserDeleteCmd() *cobra.Command {
+func (a *App) newUserDeleteCmd() *cobra.Command {
 	userDeleteCmd := &cobra.Command{
 		Use:   "delete",
 		Short: "delete pixe.la user",
@@ -161,11 +140,11 @@ see official document (https://docs.pixe.la/#/delete-user) for more detail.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// check arguments
 			if len(args) != 2 {
-				return fmt.Errorf("argument error: `user update` requires 2 arguments give %d arguments", len(args))
+				return usageErrorf("argument error: `user update` requires 2 arguments give %d arguments", len(args))
 			}
 
 			// do request
-			client, err := pixela.New(args[0], args[1], viper.GetBool("verbose"))
+			client, err := a.newClientWithAuth(args[0], args[1])
 
 			if err != nil {
 				return err
@@ -177,18 +156,7 @@ see official document (https://docs.pixe.la/#/delete-user) for more detail.`,
 				return errors.Wrap(err, "request error")
 			}
 
-			responseJSON, err := json.Marshal(response)
-
-			if err != nil {
-				return errors.Wrap(err, "response parse error")
-			}
-
-			// print result in verbose mode
-			if viper.GetBool("verbose") {
-				cui.Outputln(string(responseJSON))
-			}
-
-			return nil
+			return a.printResult(response)
 		},
 	}
 
@@ -218,7 +186,7 @@ func getDefaultFilePath() (string, error) {
 }
 
 // save username and token to file
-func saveConfigFile(path, username, token string) error {
+func (a *App) saveConfigFile(path, username, token string) error {
 	defaultPath, err := getDefaultFilePath()
 
 	if err != nil {
@@ -226,10 +194,10 @@ func saveConfigFile(path, username, token string) error {
 	}
 
 	if existFile(path) && existFile(defaultPath) {
-		cui.Outputln(fmt.Sprintf("`pixel create` successd but there are aleady config file at %s", path))
-		cui.Outputln(fmt.Sprintf("You should take a note following settings(save to yaml file)."))
-		cui.Outputln(fmt.Sprintf("username: %s", username))
-		cui.Outputln(fmt.Sprintf("token: %s", token))
+		a.ui.Outputln(fmt.Sprintf("`pixel create` successd but there are aleady config file at %s", path))
+		a.ui.Outputln(fmt.Sprintf("You should take a note following settings(save to yaml file)."))
+		a.ui.Outputln(fmt.Sprintf("username: %s", username))
+		a.ui.Outputln(fmt.Sprintf("token: %s", token))
 
 		return errors.New("cannot save configs")
 	}
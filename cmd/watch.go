@@ -0,0 +1,491 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+// interval of checking appended lines and rotation of tailed file
+const tailPollInterval = 500 * time.Millisecond
+
+// watchStates is local state of watch by graph ID and input (file path or `-`)
+type watchStates map[string]*watchState
+
+// watchState is offset of counted lines (tailed file) and counts which are not recorded yet.
+// offset is 0 at the beginning of rotated file, so started tells whether file has been tailed.
+type watchState struct {
+	Started bool           `json:"started,omitempty"`
+	Offset  int64          `json:"offset,omitempty"`
+	Pending map[string]int `json:"pending,omitempty"`
+}
+
+func (a *App) newWatchCmd() *cobra.Command {
+	watchCmd := &cobra.Command{
+		Use:   "watch",
+		Short: "count lines matching regex into graph",
+		Long: `count lines of stdin or file matching regex and add the counts to pixels. Usage:
+
+$ tail -F app.log | pixela watch <graph id> --match <regex> [--interval 1m]
+$ pixela watch <graph id> --match <regex> --file app.log [--from-beginning] [--no-follow]
+
+matches are counted per day in graph timezone (or --tz) and added to pixels every --interval,
+so one line does not mean one request. counts are also recorded when input ends or watch is interrupted.
+--file tails the file and follows rotation (file is replaced or truncated).
+offset of counted lines and counts which are not recorded yet are saved in local state file,
+so restarted watch does not count lines twice. --no-follow counts lines appended since last run and exits.
+on interrupt, counts are recorded and watch exits without waiting for next line of stdin.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check arguments
+			if len(args) != 1 {
+				return usageErrorf("argument error: `watch` requires 1 argument give %d arguments", len(args))
+			}
+
+			match, _ := cmd.Flags().GetString("match")
+
+			if match == "" {
+				return usageErrorf("argument error: `watch` requires --match")
+			}
+
+			re, err := regexp.Compile(match)
+
+			if err != nil {
+				return wrapUsageError(err, "argument error")
+			}
+
+			interval, _ := cmd.Flags().GetDuration("interval")
+
+			if interval <= 0 {
+				return usageErrorf("argument error: --interval must be positive")
+			}
+
+			file, _ := cmd.Flags().GetString("file")
+			fromBeginning, _ := cmd.Flags().GetBool("from-beginning")
+			noFollow, _ := cmd.Flags().GetBool("no-follow")
+
+			// do request
+			client, err := a.newClient()
+
+			if err != nil {
+				return err
+			}
+
+			graph, err := client.LookupGraph(args[0])
+
+			if err != nil {
+				return errors.Wrap(err, "request error")
+			}
+
+			loc, err := a.dateLocation(client, args[0])
+
+			if err != nil {
+				return err
+			}
+
+			w := &watcher{
+				app:    a,
+				client: client,
+				graph:  graph,
+				match:  re,
+				loc:    loc,
+			}
+
+			if err := w.loadState(file); err != nil {
+				return err
+			}
+
+			stop := make(chan struct{})
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+			defer signal.Stop(signals)
+
+			go func() {
+				select {
+				case <-signals:
+					close(stop)
+				case <-stop:
+				}
+			}()
+
+			var lines <-chan watchLine
+
+			if file == "" || file == "-" {
+				lines = readLines(a.ui.Reader(), stop)
+			} else {
+				// first run starts at the end of file (state of older version has no started)
+				if !w.state.Started && w.state.Offset == 0 && !fromBeginning && !noFollow {
+					info, err := os.Stat(file)
+
+					if err != nil {
+						return wrapUsageError(err, "input error")
+					}
+
+					w.state.Offset = info.Size()
+				}
+
+				w.state.Started = true
+				offset := w.state.Offset
+
+				tailer := &fileTailer{path: file, offset: offset, follow: !noFollow, poll: tailPollInterval, stop: stop}
+
+				if lines, err = tailer.lines(); err != nil {
+					return wrapUsageError(err, "input error")
+				}
+			}
+
+			return w.run(lines, interval, stop)
+		},
+	}
+
+	watchCmd.Flags().String("match", "", "regex of counted lines")
+	watchCmd.Flags().String("file", "", "tailed file (default is stdin)")
+	watchCmd.Flags().Duration("interval", time.Minute, "interval of recording counts")
+	watchCmd.Flags().Bool("from-beginning", false, "count existing lines of file at first run")
+	watchCmd.Flags().Bool("no-follow", false, "count lines appended since last run and exit")
+
+	return watchCmd
+}
+
+// watchLine is line and offset after the line (tailed file)
+type watchLine struct {
+	text   string
+	offset int64
+	err    error
+}
+
+type watcher struct {
+	app    *App
+	client *pixela.Pixela
+	graph  pixela.Graph
+	match  *regexp.Regexp
+	loc    *time.Location
+
+	statePath string
+	key       string
+	states    watchStates
+	state     *watchState
+}
+
+func (w *watcher) loadState(file string) error {
+	path, err := w.app.statePath("watch")
+
+	if err != nil {
+		return err
+	}
+
+	w.statePath, w.key, w.states = path, w.graph.ID+" -", watchStates{}
+
+	if file != "" && file != "-" {
+		abs, err := filepath.Abs(file)
+
+		if err != nil {
+			return err
+		}
+
+		w.key = w.graph.ID + " " + abs
+	}
+
+	if err := loadState(path, &w.states); err != nil {
+		return err
+	}
+
+	if w.states[w.key] == nil {
+		w.states[w.key] = &watchState{}
+	}
+
+	w.state = w.states[w.key]
+
+	if w.state.Pending == nil {
+		w.state.Pending = map[string]int{}
+	}
+
+	return nil
+}
+
+// count lines and record counts every interval, at the end of input and on stop.
+// reader of stdin may be blocked until next line, so run returns on stop without waiting for it.
+func (w *watcher) run(lines <-chan watchLine, interval time.Duration, stop <-chan struct{}) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return w.flush()
+			}
+
+			if line.err != nil {
+				if err := w.flush(); err != nil {
+					w.app.ui.OutputErrln(err.Error())
+				}
+
+				return wrapUsageError(line.err, "input error")
+			}
+
+			if w.match.MatchString(line.text) {
+				w.state.Pending[w.app.now().In(w.loc).Format(pixela.DateFormat)]++
+			}
+
+			w.state.Offset = line.offset
+		case <-stop:
+			return w.flush()
+		case <-ticker.C:
+			// keep watching while pe.la is not available (counts are kept)
+			if err := w.flush(); err != nil {
+				w.app.ui.OutputErrln(err.Error())
+			}
+		}
+	}
+}
+
+// record pending counts and save state.
+// each recorded date is removed from pending counts and saved at once, so it is not added twice after failure.
+func (w *watcher) flush() error {
+	if len(w.state.Pending) != 0 {
+		dates := make([]string, 0, len(w.state.Pending))
+
+		for date := range w.state.Pending {
+			dates = append(dates, date)
+		}
+
+		sort.Strings(dates)
+
+		convert := pixela.ConvertQuantity(pixela.NumType(w.graph.Type))
+		pixels := make([]pixela.PixelRecord, 0, len(dates))
+
+		for _, date := range dates {
+			quantity, err := convert(strconv.Itoa(w.state.Pending[date]))
+
+			if err != nil {
+				return err
+			}
+
+			pixels = append(pixels, pixela.PixelRecord{Date: date, Quantity: quantity})
+		}
+
+		done := func(change pixela.ImportChange) error {
+			for _, p := range pixels {
+				if p.Date == change.New.Date {
+					w.app.printStatus(fmt.Sprintf("%s +%s", p.Date, p.Quantity))
+				}
+			}
+
+			delete(w.state.Pending, change.New.Date)
+
+			return saveState(w.statePath, w.states)
+		}
+
+		if _, err := w.client.RecordPixels(w.graph.ID, pixels, pixela.ImportAdd, done); err != nil {
+			if saveErr := saveState(w.statePath, w.states); saveErr != nil {
+				return saveErr
+			}
+
+			return errors.Wrap(err, "request error")
+		}
+	}
+
+	return saveState(w.statePath, w.states)
+}
+
+// lines of reader (offset is not tracked)
+func readLines(r io.Reader, stop <-chan struct{}) <-chan watchLine {
+	lines := make(chan watchLine)
+
+	go func() {
+		defer close(lines)
+
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+		for scanner.Scan() {
+			select {
+			case lines <- watchLine{text: scanner.Text()}:
+			case <-stop:
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			select {
+			case lines <- watchLine{err: err}:
+			case <-stop:
+			}
+		}
+	}()
+
+	return lines
+}
+
+// fileTailer reads lines of file from offset (end of file when offset is negative).
+// with follow, appended lines are read until stop and file is reopened when it is rotated (replaced or truncated).
+// partial line at the end of file is read when it is completed.
+type fileTailer struct {
+	path   string
+	offset int64
+	follow bool
+	poll   time.Duration
+	stop   <-chan struct{}
+
+	file   *os.File
+	reader *bufio.Reader
+}
+
+func (t *fileTailer) open() error {
+	f, err := os.Open(t.path)
+
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	if t.offset < 0 {
+		t.offset = info.Size()
+	} else if t.offset > info.Size() {
+		// file is rotated while watch is stopped
+		t.offset = 0
+	}
+
+	if _, err := f.Seek(t.offset, io.SeekStart); err != nil {
+		f.Close()
+		return err
+	}
+
+	t.file, t.reader = f, bufio.NewReader(f)
+
+	return nil
+}
+
+func (t *fileTailer) lines() (<-chan watchLine, error) {
+	if err := t.open(); err != nil {
+		return nil, err
+	}
+
+	lines := make(chan watchLine)
+
+	go func() {
+		defer close(lines)
+		defer t.file.Close()
+
+		send := func(line watchLine) bool {
+			select {
+			case lines <- line:
+				return true
+			case <-t.stop:
+				return false
+			}
+		}
+
+		partial := ""
+
+		for {
+			text, err := t.reader.ReadString('\n')
+
+			if err == nil {
+				t.offset += int64(len(text))
+				line := partial + text[:len(text)-1]
+				partial = ""
+
+				if !send(watchLine{text: trimCR(line), offset: t.offset}) {
+					return
+				}
+
+				continue
+			}
+
+			if err != io.EOF {
+				send(watchLine{err: err})
+				return
+			}
+
+			// partial line is kept until it is completed (offset is start of line)
+			partial += text
+			t.offset += int64(len(text))
+
+			if !t.follow {
+				return
+			}
+
+			rotated, err := t.rotated()
+
+			if err != nil {
+				send(watchLine{err: err})
+				return
+			}
+
+			if rotated {
+				if partial != "" && !send(watchLine{text: trimCR(partial), offset: 0}) {
+					return
+				}
+
+				partial = ""
+				t.file.Close()
+				t.offset = 0
+
+				if err := t.open(); err != nil {
+					send(watchLine{err: err})
+					return
+				}
+
+				continue
+			}
+
+			select {
+			case <-time.After(t.poll):
+			case <-t.stop:
+				return
+			}
+		}
+	}()
+
+	return lines, nil
+}
+
+// file is replaced by other file or truncated. missing file (while it is being rotated) is not rotated yet.
+func (t *fileTailer) rotated() (bool, error) {
+	info, err := os.Stat(t.path)
+
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	current, err := t.file.Stat()
+
+	if err != nil {
+		return false, err
+	}
+
+	return !os.SameFile(info, current) || info.Size() < t.offset, nil
+}
+
+func trimCR(s string) string {
+	if len(s) != 0 && s[len(s)-1] == '\r' {
+		return s[:len(s)-1]
+	}
+
+	return s
+}
@@ -0,0 +1,355 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goark/gocli/exitcode"
+	"github.com/goark/gocli/rwi"
+)
+
+func TestApp_Watch(t *testing.T) {
+	auth := []string{"--username", "testuser", "--token", "testtoken"}
+	var requests []string
+	fail := false
+	failDate := ""
+
+	app, out, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/graphs"):
+			return newTestResponse(http.StatusOK, `{"graphs":[{"id":"errors","type":"int","timezone":"Asia/Tokyo"}]}`), nil
+		case req.Method == http.MethodGet:
+			return newTestResponse(http.StatusOK, `{"pixels":[{"date":"20190101","quantity":"10"}]}`), nil
+		}
+
+		body, _ := ioutil.ReadAll(req.Body)
+
+		if fail || (failDate != "" && strings.Contains(string(body), failDate)) {
+			return newTestResponse(http.StatusServiceUnavailable, `{"message":"Service Unavailable.","isSuccess":false}`), nil
+		}
+
+		requests = append(requests, req.Method+" "+req.URL.Path+" "+string(body))
+
+		return newTestResponse(http.StatusOK, `{"message":"Success.","isSuccess":true}`), nil
+	})
+
+	// 2019-01-01 23:30 in Asia/Tokyo
+	now := time.Date(2019, 1, 1, 14, 30, 0, 0, time.UTC)
+	app.now = func() time.Time { return now }
+
+	run := func(want exitcode.ExitCode, args ...string) {
+		t.Helper()
+		requests = nil
+		out.Reset()
+
+		if got := app.Execute(append(args, auth...)); got != want {
+			t.Fatalf("%v: want %d, but %d: %s", args, want, got, errOut.String())
+		}
+	}
+
+	t.Run("stdin", func(t *testing.T) {
+		app.ui = rwi.New(rwi.WithReader(strings.NewReader("ERROR a\nINFO b\nERROR c\n")), rwi.WithWriter(out), rwi.WithErrorWriter(errOut))
+		run(ExitNormal, "watch", "errors", "--match", "^ERROR")
+
+		want := []string{`PUT /v1/users/testuser/graphs/errors/20190101 {"date":"","quantity":"12"}`}
+
+		if !reflect.DeepEqual(requests, want) {
+			t.Fatalf("want %v, but %v", want, requests)
+		}
+
+		if out.String() != "20190101 +2\n" {
+			t.Fatalf("unexpected output: %q", out.String())
+		}
+	})
+
+	t.Run("file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "app.log")
+		write := func(content string, flag int) {
+			f, err := os.OpenFile(file, flag|os.O_WRONLY|os.O_CREATE, 0600)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer f.Close()
+
+			if _, err := f.WriteString(content); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		write("ERROR a\nINFO b\nERROR c\nERROR partial", os.O_TRUNC)
+		run(ExitNormal, "watch", "errors", "--match", "^ERROR", "--file", file, "--no-follow")
+
+		if out.String() != "20190101 +2\n" {
+			t.Fatalf("unexpected output: %q", out.String())
+		}
+
+		// lines counted by last run are skipped and counts are kept while request fails
+		write(" line\nINFO d\n", os.O_APPEND)
+		fail = true
+		run(ExitRetryable, "watch", "errors", "--match", "^ERROR", "--file", file, "--no-follow")
+
+		fail = false
+		now = now.Add(time.Hour)
+		write("ERROR e\n", os.O_APPEND)
+		run(ExitNormal, "watch", "errors", "--match", "^ERROR", "--file", file, "--no-follow")
+
+		want := []string{
+			`PUT /v1/users/testuser/graphs/errors/20190101 {"date":"","quantity":"11"}`,
+			`POST /v1/users/testuser/graphs/errors {"date":"20190102","quantity":"1"}`,
+		}
+
+		if !reflect.DeepEqual(requests, want) {
+			t.Fatalf("want\n%s\nbut\n%s", strings.Join(want, "\n"), strings.Join(requests, "\n"))
+		}
+
+		// rotated file is counted from the beginning
+		write("ERROR f\n", os.O_TRUNC)
+		run(ExitNormal, "watch", "errors", "--match", "^ERROR", "--file", file, "--no-follow")
+
+		if out.String() != "20190102 +1\n" {
+			t.Fatalf("unexpected output: %q", out.String())
+		}
+
+		// no new lines
+		run(ExitNormal, "watch", "errors", "--match", "^ERROR", "--file", file, "--no-follow")
+
+		if len(requests) != 0 || out.String() != "" {
+			t.Fatalf("unexpected requests: %v: %q", requests, out.String())
+		}
+	})
+
+	t.Run("partial failure", func(t *testing.T) {
+		// counts of two days are pending and the second day fails
+		path, err := app.statePath("watch")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := saveState(path, watchStates{"errors -": {Pending: map[string]int{"20190101": 2, "20190102": 3}}}); err != nil {
+			t.Fatal(err)
+		}
+
+		app.ui = rwi.New(rwi.WithReader(strings.NewReader("")), rwi.WithWriter(out), rwi.WithErrorWriter(errOut))
+		failDate = "20190102"
+		run(ExitRetryable, "watch", "errors", "--match", "^ERROR")
+
+		if out.String() != "20190101 +2\n" {
+			t.Fatalf("unexpected output: %q", out.String())
+		}
+
+		// recorded day is not added again
+		failDate = ""
+		run(ExitNormal, "watch", "errors", "--match", "^ERROR")
+
+		want := []string{`POST /v1/users/testuser/graphs/errors {"date":"20190102","quantity":"3"}`}
+
+		if !reflect.DeepEqual(requests, want) {
+			t.Fatalf("want %v, but %v", want, requests)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		run(ExitUsage, "watch", "errors")
+		run(ExitUsage, "watch", "errors", "--match", "(")
+		run(ExitUsage, "watch", "errors", "--match", "a", "--interval", "0s")
+	})
+}
+
+func TestApp_WatchRestartAfterRotation(t *testing.T) {
+	auth := []string{"--username", "testuser", "--token", "testtoken"}
+	requests := make(chan string, 10)
+
+	app, _, errOut := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/graphs"):
+			return newTestResponse(http.StatusOK, `{"graphs":[{"id":"errors","type":"int","timezone":"UTC"}]}`), nil
+		case req.Method == http.MethodGet:
+			return newTestResponse(http.StatusOK, `{"pixels":[]}`), nil
+		}
+
+		body, _ := ioutil.ReadAll(req.Body)
+		requests <- string(body)
+
+		return newTestResponse(http.StatusOK, `{"message":"Success.","isSuccess":true}`), nil
+	})
+
+	app.now = func() time.Time { return time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	file := filepath.Join(t.TempDir(), "app.log")
+
+	if err := os.WriteFile(file, []byte("ERROR old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// watch is interrupted after the request is sent (signal is handled by then)
+	watch := func(rotate func(), want string) {
+		t.Helper()
+		done := make(chan exitcode.ExitCode)
+
+		go func() {
+			done <- app.Execute(append([]string{"watch", "errors", "--match", "^ERROR", "--file", file, "--interval", "10ms"}, auth...))
+		}()
+
+		rotate()
+
+		select {
+		case got := <-requests:
+			if got != want {
+				t.Fatalf("want %s, but %s", want, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting %s", want)
+		}
+
+		p, err := os.FindProcess(os.Getpid())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := p.Signal(os.Interrupt); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case got := <-done:
+			if got != ExitNormal {
+				t.Fatalf("want %d, but %d: %s", ExitNormal, got, errOut.String())
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("watch is not stopped")
+		}
+	}
+
+	// existing line is skipped and file is rotated to empty file after partial line (offset is 0)
+	watch(func() {
+		time.Sleep(100 * time.Millisecond)
+
+		f, err := os.OpenFile(file, os.O_APPEND|os.O_WRONLY, 0600)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		f.WriteString("ERROR partial")
+		f.Close()
+
+		time.Sleep(2 * tailPollInterval)
+
+		if err := os.Rename(file, file+".1"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(file, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}, `{"date":"20190101","quantity":"1"}`)
+
+	// restarted watch counts lines of rotated file from the beginning
+	if err := os.WriteFile(file, []byte("ERROR new\nERROR new\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	watch(func() {}, `{"date":"20190101","quantity":"2"}`)
+}
+
+func TestWatcher_RunStop(t *testing.T) {
+	app, _, _ := newTestAppWithTransport(t, func(req *http.Request) (*http.Response, error) {
+		t.Fatalf("unexpected request: %s %s", req.Method, req.URL)
+		return nil, nil
+	})
+
+	w := &watcher{app: app, statePath: filepath.Join(t.TempDir(), "watch.json"), states: watchStates{}, state: &watchState{Pending: map[string]int{}}}
+
+	// stdin is blocked until next line
+	lines := make(chan watchLine)
+	stop := make(chan struct{})
+	done := make(chan error)
+
+	go func() {
+		done <- w.run(lines, time.Hour, stop)
+	}()
+
+	close(stop)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("want nil, but %#v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("watch is not stopped")
+	}
+}
+
+func TestFileTailer(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.log")
+
+	if err := os.WriteFile(file, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	tailer := &fileTailer{path: file, offset: -1, follow: true, poll: 10 * time.Millisecond, stop: stop}
+	lines, err := tailer.lines()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next := func(want string) {
+		t.Helper()
+
+		select {
+		case line := <-lines:
+			if line.err != nil || line.text != want {
+				t.Fatalf("want %q, but %q (%v)", want, line.text, line.err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting %q", want)
+		}
+	}
+
+	appendFile := func(content string) {
+		f, err := os.OpenFile(file, os.O_APPEND|os.O_WRONLY, 0600)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer f.Close()
+
+		if _, err := f.WriteString(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	appendFile("a\nb")
+	next("a")
+	time.Sleep(50 * time.Millisecond)
+	appendFile("c\r\n")
+	next("bc")
+
+	// rotation
+	if err := os.Rename(file, file+".1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(file, []byte("d\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	next("d")
+}
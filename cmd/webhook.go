@@ -1,17 +1,12 @@
 package cmd
 
 import (
-	"encoding/json"
-	"fmt"
-
+	"github.com/noissefnoc/pixela-client-go/pixela"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
-
-	"github.com/noissefnoc/pixela-client-go/pixela"
 )
 
-func newWebhookCmd() *cobra.Command {
+func (a *App) newWebhookCmd() *cobra.Command {
 	webhookCmd := &cobra.Command{
 		Use:   "webhook",
 		Short: "handle webhook subcommands (create, get, invoke and delete)",
@@ -23,15 +18,15 @@ see official document (https://docs.pixe.la) for more detail`,
 		},
 	}
 
-	webhookCmd.AddCommand(newWebhookCreateCmd())
-	webhookCmd.AddCommand(newWebhookGetCmd())
-	webhookCmd.AddCommand(newWebhookInvokeCmd())
-	webhookCmd.AddCommand(newWebhookDeleteCmd())
+	webhookCmd.AddCommand(a.newWebhookCreateCmd())
+	webhookCmd.AddCommand(a.newWebhookGetCmd())
+	webhookCmd.AddCommand(a.newWebhookInvokeCmd())
+	webhookCmd.AddCommand(a.newWebhookDeleteCmd())
 
 	return webhookCmd
 }
 
-func newWebhookCreateCmd() *cobra.Command {
+func (a *App) newWebhookCreateCmd() *cobra.Command {
 	webhookCreateCmd := &cobra.Command{
 		Use:   "create",
 		Short: "create webhook",
@@ -40,15 +35,16 @@ func newWebhookCreateCmd() *cobra.Command {
 $ pixela webhook create <graph id> <type>
 
 see official document (https://docs.pixe.la/#/post-webhook) for more detail.`,
+		ValidArgsFunction: completePositional(a.completeGraphIDs, completeValues(stringValues(pixela.WebhookTypes())...)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// check arguments
 			// TODO: add timezone option later
 			if len(args) != 2 {
-				return fmt.Errorf("argument error: `webhook create` requires 2 arguments give %d arguments", len(args))
+				return usageErrorf("argument error: `webhook create` requires 2 arguments give %d arguments", len(args))
 			}
 
 			// do request
-			client, err := pixela.New(viper.GetString("username"), viper.GetString("token"), viper.GetBool("verbose"))
+			client, err := a.newClient()
 
 			if err != nil {
 				return err
@@ -60,25 +56,16 @@ see official document (https://docs.pixe.la/#/post-webhook) for more detail.`,
 				return errors.Wrap(err, "request error: ")
 			}
 
-			responseJSON, err := json.Marshal(response)
+			a.clearCompletionCache()
 
-			if err != nil {
-				return errors.Wrap(err, "response parse error: ")
-			}
-
-			// print result in verbose mode
-			if viper.GetBool("verbose") {
-				cui.Outputln(string(responseJSON))
-			}
-
-			return nil
+			return a.printResult(response)
 		},
 	}
 
 	return webhookCreateCmd
 }
 
-func newWebhookGetCmd() *cobra.Command {
+func (a *App) newWebhookGetCmd() *cobra.Command {
 	webhookGetCmd := &cobra.Command{
 		Use:   "get",
 		Short: "get user's webhook definitions",
@@ -91,11 +78,11 @@ see official document (https://docs.pixe.la/#/get-webhook) for more detail.`,
 			// check arguments
 			// TODO: add timezone option later
 			if len(args) != 0 {
-				return fmt.Errorf("argument error: `webhook get` requires 0 arguments give %d arguments", len(args))
+				return usageErrorf("argument error: `webhook get` requires 0 arguments give %d arguments", len(args))
 			}
 
 			// do request
-			client, err := pixela.New(viper.GetString("username"), viper.GetString("token"), viper.GetBool("verbose"))
+			client, err := a.newClient()
 
 			if err != nil {
 				return err
@@ -107,23 +94,14 @@ see official document (https://docs.pixe.la/#/get-webhook) for more detail.`,
 				return errors.Wrap(err, "request error: ")
 			}
 
-			responseJSON, err := json.Marshal(response)
-
-			if err != nil {
-				return errors.Wrap(err, "response parse error: ")
-			}
-
-			// print result
-			cui.Outputln(string(responseJSON))
-
-			return nil
+			return a.printOutput(response)
 		},
 	}
 
 	return webhookGetCmd
 }
 
-func newWebhookInvokeCmd() *cobra.Command {
+func (a *App) newWebhookInvokeCmd() *cobra.Command {
 	webhookInvokeCmd := &cobra.Command{
 		Use:   "invoke",
 		Short: "invoke webhook registered in advance",
@@ -132,15 +110,16 @@ func newWebhookInvokeCmd() *cobra.Command {
 $ pixela webhook invoke <webhook hash>
 
 see official document (https://docs.pixe.la/#/invoke-webhook) for more detail.`,
+		ValidArgsFunction: completePositional(a.completeWebhookHashes),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// check arguments
 			// TODO: add timezone option later
 			if len(args) != 1 {
-				return fmt.Errorf("argument error: `webhook create` requires 1 arguments give %d arguments", len(args))
+				return usageErrorf("argument error: `webhook create` requires 1 arguments give %d arguments", len(args))
 			}
 
 			// do request
-			client, err := pixela.New(viper.GetString("username"), viper.GetString("token"), viper.GetBool("verbose"))
+			client, err := a.newClient()
 
 			if err != nil {
 				return err
@@ -152,25 +131,14 @@ see official document (https://docs.pixe.la/#/invoke-webhook) for more detail.`,
 				return errors.Wrap(err, "request error: ")
 			}
 
-			responseJSON, err := json.Marshal(response)
-
-			if err != nil {
-				return errors.Wrap(err, "response parse error:")
-			}
-
-			// print result in verbose mode
-			if viper.GetBool("verbose") {
-				cui.Outputln(string(responseJSON))
-			}
-
-			return nil
+			return a.printResult(response)
 		},
 	}
 
 	return webhookInvokeCmd
 }
 
-func newWebhookDeleteCmd() *cobra.Command {
+func (a *App) newWebhookDeleteCmd() *cobra.Command {
 	webhookDeleteCmd := &cobra.Command{
 		Use:   "delete",
 		Short: "delete webhook",
@@ -179,15 +147,16 @@ func newWebhookDeleteCmd() *cobra.Command {
 $ pixela webhook delete <webhook hash>
 
 see official document (https://docs.pixe.la/#/delete-webhook) for more detail.`,
+		ValidArgsFunction: completePositional(a.completeWebhookHashes),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// check arguments
 			// TODO: add timezone option later
 			if len(args) != 1 {
-				return fmt.Errorf("argument error: `webhook delete` requires 1 arguments give %d arguments", len(args))
+				return usageErrorf("argument error: `webhook delete` requires 1 arguments give %d arguments", len(args))
 			}
 
 			// do request
-			client, err := pixela.New(viper.GetString("username"), viper.GetString("token"), viper.GetBool("verbose"))
+			client, err := a.newClient()
 
 			if err != nil {
 				return err
@@ -199,18 +168,9 @@ see official document (https://docs.pixe.la/#/delete-webhook) for more detail.`,
 				return errors.Wrap(err, "request error: ")
 			}
 
-			responseJSON, err := json.Marshal(response)
-
-			if err != nil {
-				return errors.Wrap(err, "response parse error: ")
-			}
+			a.clearCompletionCache()
 
-			// print result in verbose mode
-			if viper.GetBool("verbose") {
-				cui.Outputln(string(responseJSON))
-			}
-
-			return nil
+			return a.printResult(response)
 		},
 	}
 
@@ -2,6 +2,8 @@ package main
 
 import (
 	"os"
+	// tz database for systems without it (such as Windows or minimal container)
+	_ "time/tzdata"
 
 	"github.com/goark/gocli/rwi"
 
@@ -0,0 +1,326 @@
+package pixela
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// BackupVersion is version of backup archive format written by WriteBackup
+const BackupVersion = 1
+
+// Backup is archive of all graphs, pixels and webhooks of user
+type Backup struct {
+	Version   int           `json:"version"`
+	CreatedAt time.Time     `json:"createdAt"`
+	Username  string        `json:"username"`
+	Graphs    []GraphBackup `json:"graphs"`
+	Webhooks  []Webhook     `json:"webhooks"`
+	// Profile is client settings (such as config profile) which are not stored in pixe.la
+	Profile map[string]string `json:"profile,omitempty"`
+}
+
+// GraphBackup is graph definition with all pixels
+type GraphBackup struct {
+	Graph
+	Pixels []PixelRecord `json:"pixels"`
+}
+
+// RestoreOptions is options for Restore
+type RestoreOptions struct {
+	// GraphIDMap maps graph ID in backup onto graph ID to restore (same ID when it is not mapped)
+	GraphIDMap map[string]string
+	// Mode is behavior for pixels which already exist in graph (ImportSkipExisting by default)
+	Mode ImportMode
+}
+
+// RestoreReport is result of Restore
+type RestoreReport struct {
+	Graphs   int `json:"graphs"`
+	Pixels   int `json:"pixels"`
+	Webhooks int `json:"webhooks"`
+	// WebhookHashes maps webhook hash in backup onto new webhook hash
+	WebhookHashes map[string]string `json:"webhookHashes,omitempty"`
+	// Issues are what could not be restored (or restored with changes)
+	Issues []RestoreIssue `json:"issues,omitempty"`
+}
+
+// RestoreIssue is item which could not be restored as it is
+type RestoreIssue struct {
+	// Kind is `graph`, `pixels` or `webhook`
+	Kind   string `json:"kind"`
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+	// Failed is true when item is not restored (false when it is restored with changes)
+	Failed bool `json:"failed"`
+}
+
+// CreateBackup is method for `backup` subcommand
+func (pixela *Pixela) CreateBackup() (*Backup, error) {
+	definitions, err := pixela.GetGraphDefinition()
+
+	if err != nil {
+		return nil, errors.Wrap(err, "`backup`: can not get graphs")
+	}
+
+	backup := &Backup{
+		Version:   BackupVersion,
+		CreatedAt: time.Now().UTC(),
+		Username:  pixela.Username,
+		Graphs:    make([]GraphBackup, 0, len(definitions.Graphs)),
+	}
+
+	for _, graph := range definitions.Graphs {
+		pixels, err := pixela.GetAllGraphPixels(graph.ID)
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "`backup`: can not get pixels of `%s`", graph.ID)
+		}
+
+		backup.Graphs = append(backup.Graphs, GraphBackup{Graph: graph, Pixels: pixels})
+	}
+
+	webhooks, err := pixela.GetWebhookDefinitions()
+
+	if err != nil {
+		return nil, errors.Wrap(err, "`backup`: can not get webhooks")
+	}
+
+	backup.Webhooks = webhooks.Webhooks
+
+	return backup, nil
+}
+
+// CreateGraphBackup returns backup of one graph (with its pixels and webhooks)
+func (pixela *Pixela) CreateGraphBackup(graphID string) (*Backup, error) {
+	graph, err := pixela.LookupGraph(graphID)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "`backup`: can not get graph")
+	}
+
+	pixels, err := pixela.GetAllGraphPixels(graphID)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "`backup`: can not get pixels of `%s`", graphID)
+	}
+
+	webhooks, err := pixela.graphWebhooks(graphID)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "`backup`: can not get webhooks")
+	}
+
+	return &Backup{
+		Version:   BackupVersion,
+		CreatedAt: time.Now().UTC(),
+		Username:  pixela.Username,
+		Graphs:    []GraphBackup{{Graph: graph, Pixels: pixels}},
+		Webhooks:  webhooks,
+	}, nil
+}
+
+// webhooks of the graph
+func (pixela *Pixela) graphWebhooks(graphID string) ([]Webhook, error) {
+	definitions, err := pixela.GetWebhookDefinitions()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var webhooks []Webhook
+
+	for _, webhook := range definitions.Webhooks {
+		if webhook.GraphID == graphID {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+
+	return webhooks, nil
+}
+
+// WriteBackup writes backup as JSON (compressed by gzip when compress is true)
+func WriteBackup(w io.Writer, backup *Backup, compress bool) error {
+	if compress {
+		zw := gzip.NewWriter(w)
+
+		if err := json.NewEncoder(zw).Encode(backup); err != nil {
+			return err
+		}
+
+		return zw.Close()
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(backup)
+}
+
+// ReadBackup reads backup written by WriteBackup (gzip is detected automatically)
+func ReadBackup(r io.Reader) (*Backup, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(2)
+
+	var reader io.Reader = br
+
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+
+		if err != nil {
+			return nil, errors.Wrap(err, "can not read backup")
+		}
+
+		defer zr.Close()
+
+		reader = zr
+	}
+
+	backup := &Backup{}
+
+	if err := json.NewDecoder(reader).Decode(backup); err != nil {
+		return nil, errors.Wrap(err, "can not read backup")
+	}
+
+	if backup.Version < 1 || backup.Version > BackupVersion {
+		return nil, fmt.Errorf("unsupported backup version %d (supported up to %d)", backup.Version, BackupVersion)
+	}
+
+	return backup, nil
+}
+
+// Restore is method for `restore` subcommand.
+// graphs are created unless they exist, pixels are imported and webhooks are created (with new hashes).
+// restore continues on errors and they are reported as RestoreReport.Issues.
+func (pixela *Pixela) Restore(backup *Backup, opts RestoreOptions) (*RestoreReport, error) {
+	if opts.Mode == "" {
+		opts.Mode = ImportSkipExisting
+	}
+
+	definitions, err := pixela.GetGraphDefinition()
+
+	if err != nil {
+		return nil, errors.Wrap(err, "`restore`: can not get graphs")
+	}
+
+	existingGraphs := map[string]bool{}
+
+	for _, graph := range definitions.Graphs {
+		existingGraphs[graph.ID] = true
+	}
+
+	report := &RestoreReport{WebhookHashes: map[string]string{}}
+	restoredGraphs := map[string]string{}
+
+	for _, graph := range backup.Graphs {
+		graphID := restoreGraphID(graph.ID, opts)
+
+		if existingGraphs[graphID] {
+			report.Issues = append(report.Issues, RestoreIssue{"graph", graphID, "graph already exists (definition is not restored, pixels are merged)", false})
+		} else {
+			_, err := pixela.CreateGraphWithRequest(CreateGraphRequest{
+				ID:             graphID,
+				Name:           graph.Name,
+				Unit:           graph.Unit,
+				Type:           NumType(graph.Type),
+				Color:          Color(graph.Color),
+				Timezone:       graph.Timezone,
+				SelfSufficient: SelfSufficient(graph.SelfSufficient),
+			})
+
+			if err != nil {
+				report.Issues = append(report.Issues, RestoreIssue{"graph", graphID, err.Error(), true})
+				continue
+			}
+
+			report.Graphs++
+		}
+
+		restoredGraphs[graph.ID] = graphID
+
+		if err := pixela.restorePixels(graphID, graph.Pixels, existingGraphs[graphID], opts.Mode, report); err != nil {
+			report.Issues = append(report.Issues, RestoreIssue{"pixels", graphID, err.Error(), true})
+		}
+	}
+
+	for _, webhook := range backup.Webhooks {
+		graphID, ok := restoredGraphs[webhook.GraphID]
+
+		if !ok {
+			report.Issues = append(report.Issues, RestoreIssue{"webhook", webhook.WebhookHash, fmt.Sprintf("graph `%s` is not restored", webhook.GraphID), true})
+			continue
+		}
+
+		response, err := pixela.CreateWebhookWithRequest(CreateWebhookRequest{GraphID: graphID, Type: WebhookType(webhook.Type)})
+
+		if err != nil {
+			report.Issues = append(report.Issues, RestoreIssue{"webhook", webhook.WebhookHash, err.Error(), true})
+			continue
+		}
+
+		report.Webhooks++
+		report.WebhookHashes[webhook.WebhookHash] = response.WebhookHash
+		report.Issues = append(report.Issues, RestoreIssue{"webhook", webhook.WebhookHash, fmt.Sprintf("hash is changed to `%s` (update callers)", response.WebhookHash), false})
+	}
+
+	return report, nil
+}
+
+// Failures returns number of items which are not restored
+func (r *RestoreReport) Failures() int {
+	failures := 0
+
+	for _, issue := range r.Issues {
+		if issue.Failed {
+			failures++
+		}
+	}
+
+	return failures
+}
+
+func (pixela *Pixela) restorePixels(graphID string, pixels []PixelRecord, graphExisted bool, mode ImportMode, report *RestoreReport) error {
+	if len(pixels) == 0 {
+		return nil
+	}
+
+	var existing []PixelRecord
+
+	if graphExisted {
+		var err error
+
+		dates := make([]string, 0, len(pixels))
+
+		for _, p := range pixels {
+			dates = append(dates, p.Date)
+		}
+
+		if existing, err = pixela.GetGraphPixelsOfDates(graphID, dates); err != nil {
+			return err
+		}
+	}
+
+	changes, err := PlanImport(pixels, existing, mode)
+
+	if err != nil {
+		return err
+	}
+
+	return pixela.ApplyImport(graphID, changes, func(ImportChange) error {
+		report.Pixels++
+		return nil
+	})
+}
+
+func restoreGraphID(graphID string, opts RestoreOptions) string {
+	if mapped, ok := opts.GraphIDMap[graphID]; ok {
+		return mapped
+	}
+
+	return graphID
+}
@@ -0,0 +1,177 @@
+package pixela
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testBackup = &Backup{
+	Version:   BackupVersion,
+	CreatedAt: time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC),
+	Username:  username,
+	Graphs: []GraphBackup{
+		{Graph{ID: "graph1", Name: "graph 1", Unit: "commits", Type: "int", Color: "shibafu"}, []PixelRecord{{"20190101", "1", `{"a":1}`}, {"20190102", "2", ""}}},
+		{Graph{ID: "graph2", Name: "graph 2", Unit: "km", Type: "float", Color: "sora", Timezone: "Asia/Tokyo"}, []PixelRecord{{"20190101", "1.5", ""}}},
+	},
+	Webhooks: []Webhook{{"hash1", "graph1", "increment"}, {"hash2", "graph3", "decrement"}},
+	Profile:  map[string]string{"name": "personal", "username": username},
+}
+
+func TestWriteBackup(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		buf := &bytes.Buffer{}
+
+		if err := WriteBackup(buf, testBackup, compress); err != nil {
+			t.Fatalf("want nil, but %#v", err)
+		}
+
+		if gzipped := bytes.HasPrefix(buf.Bytes(), []byte{0x1f, 0x8b}); gzipped != compress {
+			t.Fatalf("want gzip %t, but %t", compress, gzipped)
+		}
+
+		got, err := ReadBackup(buf)
+
+		if err != nil {
+			t.Fatalf("want nil, but %#v", err)
+		}
+
+		if !reflect.DeepEqual(got, testBackup) {
+			t.Fatalf("want %#v, but %#v", testBackup, got)
+		}
+	}
+}
+
+func TestReadBackup_Version(t *testing.T) {
+	for _, input := range []string{`{"version":0}`, `{"version":2}`, `not json`} {
+		if _, err := ReadBackup(strings.NewReader(input)); err == nil {
+			t.Fatalf("%s: want error, but nil", input)
+		}
+	}
+}
+
+func TestPixela_CreateBackup(t *testing.T) {
+	// pixels older than one year are fetched by windows
+	pixels := pixelsResponder(t, testBackup.Graphs[0].Pixels, nil)
+
+	c := NewTestClient(func(req *http.Request) *http.Response {
+		var body string
+
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/graphs"):
+			body = `{"graphs":[{"id":"graph1","name":"graph 1","unit":"commits","type":"int","color":"shibafu","timezone":"","purgeCacheURLs":null}]}`
+		case strings.HasSuffix(req.URL.Path, "/graph1/pixels"), strings.HasSuffix(req.URL.Path, "/graph1/stats"):
+			return pixels(req)
+		case strings.HasSuffix(req.URL.Path, "/webhooks"):
+			body = `{"webhooks":[{"webhookHash":"hash1","graphID":"graph1","type":"increment"}]}`
+		default:
+			t.Fatalf("unexpected request: %s", req.URL)
+		}
+
+		return &http.Response{StatusCode: sucStatus, Body: ioutil.NopCloser(bytes.NewBufferString(body)), Header: make(http.Header)}
+	})
+
+	pixela, err := New(username, token, debug, OptionHTTPClient(c))
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	got, err := pixela.CreateBackup()
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	if got.Version != BackupVersion || got.Username != username || got.CreatedAt.IsZero() {
+		t.Fatalf("unexpected backup header: %#v", got)
+	}
+
+	want := []GraphBackup{testBackup.Graphs[0]}
+	want[0].Graph.Timezone = ""
+
+	if !reflect.DeepEqual(got.Graphs, want) || !reflect.DeepEqual(got.Webhooks, testBackup.Webhooks[:1]) {
+		t.Fatalf("want %#v, but %#v", want, got)
+	}
+}
+
+func TestPixela_Restore(t *testing.T) {
+	var requests []string
+	graphs := `{"graphs":[{"id":"restored2","name":"graph 2","unit":"km","type":"float","color":"sora"}]}`
+
+	c := NewTestClient(func(req *http.Request) *http.Response {
+		var body []byte
+
+		if req.Body != nil {
+			body, _ = ioutil.ReadAll(req.Body)
+		}
+
+		requests = append(requests, strings.TrimSpace(req.Method+" "+strings.TrimPrefix(req.URL.Path, "/v1/users/"+username)+" "+string(body)))
+
+		response := `{"message":"Success.","isSuccess":true}`
+
+		switch {
+		case req.Method == http.MethodGet && strings.HasSuffix(req.URL.Path, "/graphs"):
+			response = graphs
+		case req.Method == http.MethodGet:
+			response = `{"pixels":[{"date":"20190101","quantity":"1.5"}]}`
+		case strings.HasSuffix(req.URL.Path, "/graphs"):
+			graphs = `{"graphs":[{"id":"graph1","type":"int"},{"id":"restored2","name":"graph 2","unit":"km","type":"float","color":"sora"}]}`
+		case strings.HasSuffix(req.URL.Path, "/webhooks"):
+			response = `{"message":"Success.","webhookHash":"newhash1","isSuccess":true}`
+		}
+
+		return &http.Response{StatusCode: sucStatus, Body: ioutil.NopCloser(bytes.NewBufferString(response)), Header: make(http.Header)}
+	})
+
+	pixela, err := New(username, token, debug, OptionHTTPClient(c))
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	report, err := pixela.Restore(testBackup, RestoreOptions{GraphIDMap: map[string]string{"graph2": "restored2"}})
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	wantRequests := []string{
+		"GET /graphs",
+		`POST /graphs {"id":"graph1","name":"graph 1","unit":"commits","type":"int","color":"shibafu"}`,
+		// graph type of created graph is looked up to check quantities
+		"GET /graphs",
+		`POST /graphs/graph1 {"date":"20190101","quantity":"1","optionalData":"{\"a\":1}"}`,
+		`POST /graphs/graph1 {"date":"20190102","quantity":"2"}`,
+		"GET /graphs/restored2/pixels",
+		`POST /webhooks {"graphID":"graph1","type":"increment"}`,
+	}
+
+	if !reflect.DeepEqual(requests, wantRequests) {
+		t.Fatalf("want\n%s\nbut\n%s", strings.Join(wantRequests, "\n"), strings.Join(requests, "\n"))
+	}
+
+	wantReport := &RestoreReport{
+		Graphs:        1,
+		Pixels:        2,
+		Webhooks:      1,
+		WebhookHashes: map[string]string{"hash1": "newhash1"},
+		Issues: []RestoreIssue{
+			{"graph", "restored2", "graph already exists (definition is not restored, pixels are merged)", false},
+			{"webhook", "hash1", "hash is changed to `newhash1` (update callers)", false},
+			{"webhook", "hash2", "graph `graph3` is not restored", true},
+		},
+	}
+
+	if !reflect.DeepEqual(report, wantReport) {
+		t.Fatalf("want %#v, but %#v", wantReport, report)
+	}
+
+	if report.Failures() != 1 {
+		t.Fatalf("want 1, but %d", report.Failures())
+	}
+}
@@ -0,0 +1,255 @@
+package pixela
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// QuantityConverter converts quantity of pixel (such as unit or type conversion)
+type QuantityConverter func(quantity string) (string, error)
+
+// CloneOptions is options for CloneGraph
+type CloneOptions struct {
+	// Target is client of destination user (same user when it is nil)
+	Target *Pixela
+	// Definition overrides source graph definition (empty fields are copied from source)
+	Definition CreateGraphRequest
+	// Convert converts quantity of each pixel (ConvertQuantity to graph type when it is nil)
+	Convert QuantityConverter
+	// Concurrency is number of parallel requests copying pixels (1 when it is not positive)
+	Concurrency int
+	// KeepOnError keeps destination graph when copy fails (it is deleted by default)
+	KeepOnError bool
+	// Pixels are copied instead of pixels of source graph (every pixel is fetched when it is nil)
+	Pixels []PixelRecord
+}
+
+// CloneResult is result of CloneGraph
+type CloneResult struct {
+	Graph  CreateGraphRequest `json:"graph"`
+	Pixels int                `json:"pixels"`
+}
+
+// CloneGraph is method for `graph clone` subcommand.
+// destination graph is created with source definition and all pixels are copied.
+// destination graph is deleted when copy fails midway (unless KeepOnError).
+func (pixela *Pixela) CloneGraph(srcID, dstID string, opts CloneOptions) (CloneResult, error) {
+	target := opts.Target
+
+	if target == nil {
+		target = pixela
+	}
+
+	src, err := pixela.LookupGraph(srcID)
+
+	if err != nil {
+		return CloneResult{}, errors.Wrap(err, "`graph clone`: can not get source graph")
+	}
+
+	// never roll back graph which exists before clone
+	if _, err := target.LookupGraph(dstID); err == nil {
+		return CloneResult{}, fmt.Errorf("`graph clone`: graph `%s` already exists", dstID)
+	} else if !isNotFound(err) {
+		return CloneResult{}, errors.Wrap(err, "`graph clone`: can not get destination graph")
+	}
+
+	definition := cloneDefinition(src, dstID, opts.Definition)
+
+	convert := opts.Convert
+
+	if convert == nil {
+		convert = ConvertQuantity(definition.Type)
+	}
+
+	pixels := append([]PixelRecord(nil), opts.Pixels...)
+
+	if opts.Pixels == nil {
+		if pixels, err = pixela.GetAllGraphPixels(srcID); err != nil {
+			return CloneResult{}, errors.Wrap(err, "`graph clone`: can not get pixels")
+		}
+	}
+
+	// convert all pixels before creating graph
+	for i := range pixels {
+		if pixels[i].Quantity, err = convert(pixels[i].Quantity); err != nil {
+			return CloneResult{}, errors.Wrapf(err, "`graph clone`: can not convert quantity of %s", pixels[i].Date)
+		}
+	}
+
+	if _, err := target.CreateGraphWithRequest(definition); err != nil {
+		return CloneResult{}, errors.Wrap(err, "`graph clone`: can not create graph")
+	}
+
+	copied, err := target.postPixels(dstID, pixels, opts.Concurrency)
+	result := CloneResult{Graph: definition, Pixels: copied}
+
+	if err == nil {
+		return result, nil
+	}
+
+	err = errors.Wrapf(err, "`graph clone`: %d of %d pixels are copied", copied, len(pixels))
+
+	if opts.KeepOnError {
+		return result, err
+	}
+
+	if _, rollbackErr := target.DeleteGraph(dstID); rollbackErr != nil {
+		return result, errors.Wrapf(err, "rollback failed (delete graph `%s` manually: %s)", dstID, rollbackErr)
+	}
+
+	result.Pixels = 0
+
+	return result, errors.Wrapf(err, "graph `%s` is deleted", dstID)
+}
+
+func cloneDefinition(src Graph, dstID string, override CreateGraphRequest) CreateGraphRequest {
+	definition := CreateGraphRequest{
+		ID:             dstID,
+		Name:           src.Name,
+		Unit:           src.Unit,
+		Type:           NumType(src.Type),
+		Color:          Color(src.Color),
+		Timezone:       src.Timezone,
+		SelfSufficient: SelfSufficient(src.SelfSufficient),
+	}
+
+	if override.Name != "" {
+		definition.Name = override.Name
+	}
+
+	if override.Unit != "" {
+		definition.Unit = override.Unit
+	}
+
+	if override.Type != "" {
+		definition.Type = override.Type
+	}
+
+	if override.Color != "" {
+		definition.Color = override.Color
+	}
+
+	if override.Timezone != "" {
+		definition.Timezone = override.Timezone
+	}
+
+	if override.SelfSufficient != "" {
+		definition.SelfSufficient = override.SelfSufficient
+	}
+
+	return definition
+}
+
+// post pixels in parallel. no more pixels are posted after the first error.
+func (pixela *Pixela) postPixels(graphID string, pixels []PixelRecord, concurrency int) (int, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	copied := 0
+
+	jobs := make(chan PixelRecord)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for p := range jobs {
+				mu.Lock()
+				failed := firstErr != nil
+				mu.Unlock()
+
+				if failed {
+					continue
+				}
+
+				_, err := pixela.PostPixelWithRequest(PixelRequest{
+					GraphID:      graphID,
+					Date:         p.Date,
+					Quantity:     p.Quantity,
+					OptionalData: p.OptionalData,
+				})
+
+				mu.Lock()
+
+				if err == nil {
+					copied++
+				} else if firstErr == nil {
+					firstErr = errors.Wrapf(err, "can not copy %s", p.Date)
+				}
+
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, p := range pixels {
+		jobs <- p
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	return copied, firstErr
+}
+
+// ConvertQuantity returns QuantityConverter formatting quantity for graph type (int quantity is rounded)
+func ConvertQuantity(numType NumType) QuantityConverter {
+	converter, _ := ScaleQuantity("1", numType)
+
+	return converter
+}
+
+// ScaleQuantity returns QuantityConverter which multiplies quantity by factor (such as `60`, `0.5` or `1/60`)
+// and formats it for graph type. int quantity is rounded half away from zero.
+func ScaleQuantity(factor string, numType NumType) (QuantityConverter, error) {
+	f, ok := new(big.Rat).SetString(factor)
+
+	if !ok {
+		return nil, fmt.Errorf("invalid scale factor `%s`", factor)
+	}
+
+	return func(quantity string) (string, error) {
+		q, ok := new(big.Rat).SetString(quantity)
+
+		if !ok {
+			return "", fmt.Errorf("quantity `%s` is not a number", quantity)
+		}
+
+		q.Mul(q, f)
+
+		if numType == NumTypeInt {
+			return roundRat(q).String(), nil
+		}
+
+		return formatDecimal(q), nil
+	}, nil
+}
+
+// round half away from zero
+func roundRat(r *big.Rat) *big.Int {
+	doubled := new(big.Int).Mul(r.Num(), big.NewInt(2))
+	doubled.Add(doubled, new(big.Int).Mul(r.Denom(), big.NewInt(int64(r.Sign()))))
+
+	return doubled.Quo(doubled, new(big.Int).Mul(r.Denom(), big.NewInt(2)))
+}
+
+// decimal without trailing zeros (at least one fraction digit)
+func formatDecimal(r *big.Rat) string {
+	s := strings.TrimRight(r.FloatString(10), "0")
+
+	if strings.HasSuffix(s, ".") {
+		s += "0"
+	}
+
+	return s
+}
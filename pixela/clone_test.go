@@ -0,0 +1,300 @@
+package pixela
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// fake pixe.la server for clone tests (created graph is added to graphs)
+type cloneTestServer struct {
+	mu       sync.Mutex
+	graphs   string
+	failOn   string
+	requests []string
+}
+
+func (s *cloneTestServer) client(t *testing.T, user string) *Pixela {
+	c := NewTestClient(func(req *http.Request) *http.Response {
+		var body []byte
+
+		if req.Body != nil {
+			body, _ = ioutil.ReadAll(req.Body)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.requests = append(s.requests, strings.TrimSpace(req.Method+" "+req.URL.Path+" "+string(body)))
+		status, response := sucStatus, `{"message":"Success.","isSuccess":true}`
+
+		switch {
+		case req.Method == http.MethodGet && strings.HasSuffix(req.URL.Path, "/graphs"):
+			response = s.graphs
+		case req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/graphs"):
+			var definitions GraphDefinitions
+			var graph Graph
+			json.Unmarshal([]byte(s.graphs), &definitions)
+			json.Unmarshal(body, &graph)
+			data, _ := json.Marshal(GraphDefinitions{Graphs: append(definitions.Graphs, graph)})
+			s.graphs = string(data)
+		case req.Method == http.MethodGet && strings.HasSuffix(req.URL.Path, "/stats"):
+			response = `{"totalPixelsCount":3}`
+		case req.Method == http.MethodGet:
+			response = `{"pixels":[{"date":"20190101","quantity":"1.5","optionalData":"{\"a\":1}"},{"date":"20190102","quantity":"2.4"},{"date":"20190103","quantity":"-2.5"}]}`
+		case s.failOn != "" && strings.Contains(string(body), s.failOn):
+			status, response = errStatus, `{"message":"errorMessage","isSuccess":false}`
+		}
+
+		return &http.Response{StatusCode: status, Body: ioutil.NopCloser(bytes.NewBufferString(response)), Header: make(http.Header)}
+	})
+
+	pixela, err := New(user, token, debug, OptionHTTPClient(c))
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	return pixela
+}
+
+func (s *cloneTestServer) sortedRequests() []string {
+	requests := append([]string{}, s.requests...)
+	sort.Strings(requests)
+
+	return requests
+}
+
+func TestPixela_CloneGraph(t *testing.T) {
+	server := &cloneTestServer{graphs: `{"graphs":[{"id":"src","name":"source","unit":"km","type":"float","color":"sora","timezone":"Asia/Tokyo","selfSufficient":"increment"}]}`}
+	pixela := server.client(t, username)
+
+	result, err := pixela.CloneGraph("src", "dst", CloneOptions{
+		Definition:  CreateGraphRequest{Name: "destination", Type: NumTypeInt},
+		Concurrency: 2,
+	})
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	if result.Pixels != 3 || result.Graph.Name != "destination" || result.Graph.Unit != "km" {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+
+	want := []string{
+		"GET /v1/users/testuser/graphs",
+		"GET /v1/users/testuser/graphs",
+		"GET /v1/users/testuser/graphs/src/pixels",
+		"GET /v1/users/testuser/graphs/src/stats",
+		`POST /v1/users/testuser/graphs {"id":"dst","name":"destination","unit":"km","type":"int","color":"sora","timezone":"Asia/Tokyo","selfSufficient":"increment"}`,
+		`POST /v1/users/testuser/graphs/dst {"date":"20190101","quantity":"2","optionalData":"{\"a\":1}"}`,
+		`POST /v1/users/testuser/graphs/dst {"date":"20190102","quantity":"2"}`,
+		`POST /v1/users/testuser/graphs/dst {"date":"20190103","quantity":"-3"}`,
+	}
+
+	if got := server.sortedRequests(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("want\n%s\nbut\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestPixela_CloneGraphOldPixels(t *testing.T) {
+	// pixel older than one year (pe.la returns pixels of last 365 days without range)
+	pixels := pixelsResponder(t, []PixelRecord{{Date: "20150101", Quantity: "1"}, {Date: "20190101", Quantity: "2"}}, nil)
+	var posted []string
+	graphs := `{"graphs":[{"id":"src","type":"int"}]}`
+
+	c := NewTestClient(func(req *http.Request) *http.Response {
+		switch {
+		case req.Method == http.MethodGet && strings.HasSuffix(req.URL.Path, "/graphs"):
+			return &http.Response{StatusCode: sucStatus, Body: ioutil.NopCloser(bytes.NewBufferString(graphs)), Header: make(http.Header)}
+		case strings.HasSuffix(req.URL.Path, "/graphs"):
+			graphs = `{"graphs":[{"id":"src","type":"int"},{"id":"dst","type":"int"}]}`
+		case req.Method == http.MethodGet:
+			return pixels(req)
+		}
+
+		body, _ := ioutil.ReadAll(req.Body)
+		posted = append(posted, string(body))
+
+		return &http.Response{StatusCode: sucStatus, Body: ioutil.NopCloser(bytes.NewBufferString(`{"message":"Success.","isSuccess":true}`)), Header: make(http.Header)}
+	})
+
+	pixela, err := New(username, token, debug, OptionHTTPClient(c))
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	result, err := pixela.CloneGraph("src", "dst", CloneOptions{})
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	want := []string{
+		`{"id":"dst","name":"","unit":"","type":"int","color":""}`,
+		`{"date":"20150101","quantity":"1"}`,
+		`{"date":"20190101","quantity":"2"}`,
+	}
+
+	if result.Pixels != 2 || !reflect.DeepEqual(posted, want) {
+		t.Fatalf("want %v, but %v", want, posted)
+	}
+}
+
+func TestPixela_CloneGraphToOtherUser(t *testing.T) {
+	source := &cloneTestServer{graphs: `{"graphs":[{"id":"src","name":"source","unit":"km","type":"float","color":"sora"}]}`}
+	target := &cloneTestServer{graphs: `{"graphs":[]}`}
+
+	result, err := source.client(t, username).CloneGraph("src", "src", CloneOptions{Target: target.client(t, "team")})
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	if result.Pixels != 3 {
+		t.Fatalf("want 3, but %d", result.Pixels)
+	}
+
+	for _, req := range target.requests {
+		if strings.HasPrefix(req, "POST") && !strings.HasPrefix(req, "POST /v1/users/team/graphs") {
+			t.Fatalf("unexpected request to target: %s", req)
+		}
+	}
+
+	for _, req := range source.requests {
+		if strings.HasPrefix(req, "POST") {
+			t.Fatalf("unexpected request to source: %s", req)
+		}
+	}
+}
+
+func TestPixela_CloneGraphRollback(t *testing.T) {
+	tests := []struct {
+		name        string
+		keepOnError bool
+		wantDelete  bool
+	}{
+		{"rollback", false, true},
+		{"keep on error", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &cloneTestServer{
+				graphs: `{"graphs":[{"id":"src","name":"source","unit":"km","type":"float","color":"sora"}]}`,
+				failOn: "20190102",
+			}
+
+			_, err := server.client(t, username).CloneGraph("src", "dst", CloneOptions{KeepOnError: tt.keepOnError})
+
+			if err == nil {
+				t.Fatalf("want error, but nil")
+			}
+
+			deleted := false
+
+			for _, req := range server.requests {
+				if req == "DELETE /v1/users/testuser/graphs/dst" {
+					deleted = true
+				}
+
+				if strings.Contains(req, "20190103") {
+					t.Fatalf("want no request after error, but %s", req)
+				}
+			}
+
+			if deleted != tt.wantDelete {
+				t.Fatalf("want deleted %t, but %t: %s", tt.wantDelete, deleted, err)
+			}
+		})
+	}
+}
+
+func TestPixela_CloneGraphExisting(t *testing.T) {
+	server := &cloneTestServer{graphs: `{"graphs":[{"id":"src","type":"int"},{"id":"dst","type":"int"}]}`}
+
+	if _, err := server.client(t, username).CloneGraph("src", "dst", CloneOptions{}); err == nil {
+		t.Fatalf("want error, but nil")
+	}
+
+	for _, req := range server.requests {
+		if !strings.HasPrefix(req, "GET") {
+			t.Fatalf("want no change, but %s", req)
+		}
+	}
+}
+
+func TestPixela_CloneGraphDestinationError(t *testing.T) {
+	source := &cloneTestServer{graphs: `{"graphs":[{"id":"src","type":"int"}]}`}
+	var requests []string
+
+	// graphs of destination user can not be got (such as wrong token)
+	c := NewTestClient(func(req *http.Request) *http.Response {
+		requests = append(requests, req.Method+" "+req.URL.Path)
+
+		return &http.Response{StatusCode: http.StatusUnauthorized, Body: ioutil.NopCloser(bytes.NewBufferString(`{"message":"User team does not exist or the token is wrong.","isSuccess":false}`)), Header: make(http.Header)}
+	})
+
+	target, err := New("team", token, debug, OptionHTTPClient(c))
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	_, err = source.client(t, username).CloneGraph("src", "dst", CloneOptions{Target: target})
+
+	if apiErr, ok := errors.Cause(err).(*APIError); !ok || !apiErr.Unauthorized() {
+		t.Fatalf("want unauthorized error, but %#v", err)
+	}
+
+	if want := []string{"GET /v1/users/team/graphs"}; !reflect.DeepEqual(requests, want) {
+		t.Fatalf("want %v, but %v", want, requests)
+	}
+}
+
+func TestScaleQuantity(t *testing.T) {
+	tests := []struct {
+		factor   string
+		numType  NumType
+		quantity string
+		want     string
+		wantErr  bool
+	}{
+		{"1", NumTypeInt, "2.5", "3", false},
+		{"1", NumTypeInt, "-2.5", "-3", false},
+		{"1", NumTypeInt, "2.4", "2", false},
+		{"1", NumTypeFloat, "2", "2.0", false},
+		{"60", NumTypeInt, "1.5", "90", false},
+		{"1/60", NumTypeFloat, "90", "1.5", false},
+		{"0.5", NumTypeFloat, "3", "1.5", false},
+		{"1", NumTypeInt, "one", "", true},
+	}
+
+	for _, tt := range tests {
+		convert, err := ScaleQuantity(tt.factor, tt.numType)
+
+		if err != nil {
+			t.Fatalf("want nil, but %#v", err)
+		}
+
+		got, err := convert(tt.quantity)
+
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Fatalf("%s * %s (%s): want %s (error %t), but %s (%v)", tt.quantity, tt.factor, tt.numType, tt.want, tt.wantErr, got, err)
+		}
+	}
+
+	if _, err := ScaleQuantity("x", NumTypeInt); err == nil {
+		t.Fatalf("want error, but nil")
+	}
+}
@@ -0,0 +1,125 @@
+package pixela
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateFormat is date format of pixe.la API (yyyyMMdd)
+const DateFormat = "20060102"
+
+var (
+	relativeDatePattern = regexp.MustCompile(`^([+-]?)(\d+)([dw])$`)
+	agoDatePattern      = regexp.MustCompile(`^(\d+) (day|days|week|weeks) ago$`)
+	absoluteDateLayouts = []string{DateFormat, "2006-01-02", "2006/01/02"}
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+	"sun":       time.Sunday,
+	"mon":       time.Monday,
+	"tue":       time.Tuesday,
+	"wed":       time.Wednesday,
+	"thu":       time.Thursday,
+	"fri":       time.Friday,
+	"sat":       time.Saturday,
+}
+
+// IsAbsoluteDate returns true when expression does not depend on current date (such as `20190101` or `2019-01-01`)
+func IsAbsoluteDate(expr string) bool {
+	for _, layout := range absoluteDateLayouts {
+		if _, err := time.Parse(layout, strings.TrimSpace(expr)); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ResolveDate converts date expression into `yyyyMMdd` format.
+// Relative expressions are resolved from now in the location.
+//
+// Supported expressions are:
+// `yyyyMMdd`, `yyyy-MM-dd`, `yyyy/MM/dd`, empty (today), `today`, `yesterday`, `tomorrow`,
+// `-3d`, `+1d`, `-2w`, `3 days ago`, `1 week ago`, `last friday` and `friday` (this or last friday).
+func ResolveDate(expr string, now time.Time, loc *time.Location) (string, error) {
+	e := strings.ToLower(strings.Join(strings.Fields(expr), " "))
+
+	for _, layout := range absoluteDateLayouts {
+		if t, err := time.Parse(layout, e); err == nil {
+			return t.Format(DateFormat), nil
+		}
+	}
+
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	today := now.In(loc)
+
+	addDays := func(days int) (string, error) {
+		return today.AddDate(0, 0, days).Format(DateFormat), nil
+	}
+
+	switch e {
+	case "", "today", "now":
+		return addDays(0)
+	case "yesterday":
+		return addDays(-1)
+	case "tomorrow":
+		return addDays(1)
+	}
+
+	if m := relativeDatePattern.FindStringSubmatch(e); m != nil {
+		n, _ := strconv.Atoi(m[2])
+
+		if m[3] == "w" {
+			n *= 7
+		}
+
+		if m[1] == "-" {
+			n = -n
+		}
+
+		return addDays(n)
+	}
+
+	if m := agoDatePattern.FindStringSubmatch(e); m != nil {
+		n, _ := strconv.Atoi(m[1])
+
+		if strings.HasPrefix(m[2], "week") {
+			n *= 7
+		}
+
+		return addDays(-n)
+	}
+
+	name := strings.TrimPrefix(e, "this ")
+	last := strings.HasPrefix(e, "last ")
+
+	if last {
+		name = strings.TrimPrefix(e, "last ")
+	}
+
+	if weekday, ok := weekdays[name]; ok {
+		days := (int(today.Weekday()) - int(weekday) + 7) % 7
+
+		// `last friday` on friday means a week ago
+		if last && days == 0 {
+			days = 7
+		}
+
+		return addDays(-days)
+	}
+
+	return "", fmt.Errorf("unknown date `%s`: allows `yyyyMMdd`, `yyyy-MM-dd`, `today`, `yesterday`, `-3d`, `3 days ago` or `last friday`", expr)
+}
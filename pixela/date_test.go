@@ -0,0 +1,73 @@
+package pixela
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveDate(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+
+	// Sunday 2026-10-18 20:00 UTC is Monday 2026-10-19 05:00 in Tokyo
+	now := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		expr    string
+		loc     *time.Location
+		want    string
+		wantErr bool
+	}{
+		{"Absolute", "20190131", nil, "20190131", false},
+		{"ISO", "2026-10-18", tokyo, "20261018", false},
+		{"Slash", "2026/10/18", nil, "20261018", false},
+		{"Empty is today", "", nil, "20261018", false},
+		{"Today in UTC", "today", time.UTC, "20261018", false},
+		{"Today in Tokyo", "today", tokyo, "20261019", false},
+		{"Yesterday", "Yesterday", nil, "20261017", false},
+		{"Tomorrow", "tomorrow", nil, "20261019", false},
+		{"Days before", "-3d", nil, "20261015", false},
+		{"Days after", "+1d", nil, "20261019", false},
+		{"Weeks before", "-2w", nil, "20261004", false},
+		{"Days ago", "3 days ago", nil, "20261015", false},
+		{"Week ago", "1 week ago", nil, "20261011", false},
+		{"Last friday", "last friday", nil, "20261016", false},
+		{"Last sunday on sunday", "last sunday", nil, "20261011", false},
+		{"This sunday on sunday", "sunday", nil, "20261018", false},
+		{"Last friday in Tokyo", "last fri", tokyo, "20261016", false},
+		{"Invalid date", "20191399", nil, "", true},
+		{"Unknown expression", "someday", nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveDate(tt.expr, now, tt.loc)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error %#v, but %#v", tt.wantErr, err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("want %#v, but %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestIsAbsoluteDate(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"20190131", true},
+		{"2019-01-31", true},
+		{"today", false},
+		{"-1d", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsAbsoluteDate(tt.expr); got != tt.want {
+			t.Fatalf("IsAbsoluteDate(%#v): want %#v, but %#v", tt.expr, tt.want, got)
+		}
+	}
+}
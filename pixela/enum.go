@@ -0,0 +1,138 @@
+package pixela
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Color is graph color
+type Color string
+
+// colors pixe.la accepts
+const (
+	ColorShibafu Color = "shibafu"
+	ColorMomiji  Color = "momiji"
+	ColorSora    Color = "sora"
+	ColorIchou   Color = "ichou"
+	ColorAjisai  Color = "ajisai"
+	ColorKuro    Color = "kuro"
+)
+
+// NumType is graph number type
+type NumType string
+
+// number types pixe.la accepts
+const (
+	NumTypeInt   NumType = "int"
+	NumTypeFloat NumType = "float"
+)
+
+// SelfSufficient is graph self sufficient behavior
+type SelfSufficient string
+
+// self sufficient behaviors pixe.la accepts
+const (
+	SelfSufficientNone      SelfSufficient = "none"
+	SelfSufficientIncrement SelfSufficient = "increment"
+	SelfSufficientDecrement SelfSufficient = "decrement"
+)
+
+// WebhookType is webhook type
+type WebhookType string
+
+// webhook types pixe.la accepts
+const (
+	WebhookTypeIncrement WebhookType = "increment"
+	WebhookTypeDecrement WebhookType = "decrement"
+)
+
+// Colors returns all colors
+func Colors() []Color {
+	return []Color{ColorShibafu, ColorMomiji, ColorSora, ColorIchou, ColorAjisai, ColorKuro}
+}
+
+// NumTypes returns all number types
+func NumTypes() []NumType {
+	return []NumType{NumTypeInt, NumTypeFloat}
+}
+
+// SelfSufficients returns all self sufficient behaviors
+func SelfSufficients() []SelfSufficient {
+	return []SelfSufficient{SelfSufficientNone, SelfSufficientIncrement, SelfSufficientDecrement}
+}
+
+// WebhookTypes returns all webhook types
+func WebhookTypes() []WebhookType {
+	return []WebhookType{WebhookTypeIncrement, WebhookTypeDecrement}
+}
+
+func (c Color) String() string {
+	return string(c)
+}
+
+func (n NumType) String() string {
+	return string(n)
+}
+
+func (s SelfSufficient) String() string {
+	return string(s)
+}
+
+func (w WebhookType) String() string {
+	return string(w)
+}
+
+// ParseColor converts string to Color
+func ParseColor(s string) (Color, error) {
+	for _, c := range Colors() {
+		if string(c) == s {
+			return c, nil
+		}
+	}
+
+	return "", EnumParseError("color", s, Colors())
+}
+
+// ParseNumType converts string to NumType
+func ParseNumType(s string) (NumType, error) {
+	for _, n := range NumTypes() {
+		if string(n) == s {
+			return n, nil
+		}
+	}
+
+	return "", EnumParseError("type", s, NumTypes())
+}
+
+// ParseSelfSufficient converts string to SelfSufficient
+func ParseSelfSufficient(s string) (SelfSufficient, error) {
+	for _, ss := range SelfSufficients() {
+		if string(ss) == s {
+			return ss, nil
+		}
+	}
+
+	return "", EnumParseError("selfSufficient", s, SelfSufficients())
+}
+
+// ParseWebhookType converts string to WebhookType
+func ParseWebhookType(s string) (WebhookType, error) {
+	for _, w := range WebhookTypes() {
+		if string(w) == s {
+			return w, nil
+		}
+	}
+
+	return "", EnumParseError("webhook type", s, WebhookTypes())
+}
+
+// EnumParseError returns error of string which is not one of enum values (such as `ParseColor`)
+func EnumParseError[T fmt.Stringer](kind, s string, values []T) error {
+	allowed := make([]string, 0, len(values))
+
+	for _, v := range values {
+		allowed = append(allowed, "`"+v.String()+"`")
+	}
+
+	return fmt.Errorf("invalid %s `%s`: allows %s", kind, s, strings.Join(allowed, ", "))
+}
@@ -0,0 +1,113 @@
+package pixela
+
+import (
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		want    Color
+		wantErr bool
+	}{
+		{"Normal case", "shibafu", ColorShibafu, false},
+		{"Empty", "", "", true},
+		{"Unknown color", "red", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseColor(tt.target)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error %#v, but %#v", tt.wantErr, err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("want %#v, but %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseNumType(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		want    NumType
+		wantErr bool
+	}{
+		{"Int", "int", NumTypeInt, false},
+		{"Float", "float", NumTypeFloat, false},
+		{"Unknown type", "string", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNumType(tt.target)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error %#v, but %#v", tt.wantErr, err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("want %#v, but %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseSelfSufficient(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		want    SelfSufficient
+		wantErr bool
+	}{
+		{"None", "none", SelfSufficientNone, false},
+		{"Increment", "increment", SelfSufficientIncrement, false},
+		{"Unknown value", "stopwatch", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSelfSufficient(tt.target)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error %#v, but %#v", tt.wantErr, err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("want %#v, but %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseWebhookType(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		want    WebhookType
+		wantErr bool
+	}{
+		{"Increment", "increment", WebhookTypeIncrement, false},
+		{"Decrement", "decrement", WebhookTypeDecrement, false},
+		{"Unknown type", "none", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWebhookType(tt.target)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error %#v, but %#v", tt.wantErr, err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("want %#v, but %#v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package pixela
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// APIError is error response of pixe.la API
+type APIError struct {
+	Method     string
+	StatusCode int
+	Message    string
+}
+
+func newAPIError(method string, statusCode int, message string) *APIError {
+	// response which is not JSON (such as error page of load balancer)
+	if message == "" {
+		message = http.StatusText(statusCode)
+	}
+
+	return &APIError{
+		Method:     method,
+		StatusCode: statusCode,
+		Message:    message,
+	}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s request failed: %s", e.Method, e.Message)
+}
+
+// Unauthorized returns true when username or token is wrong
+func (e *APIError) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// NotFound returns true when user, graph, pixel or webhook is not found
+func (e *APIError) NotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// Retryable returns true when same request may succeed later (server error or rate limit)
+func (e *APIError) Retryable() bool {
+	return e.StatusCode >= http.StatusInternalServerError || e.StatusCode == http.StatusTooManyRequests
+}
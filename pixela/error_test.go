@@ -0,0 +1,64 @@
+package pixela
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		response      []byte
+		wantMessage   string
+		wantNotFound  bool
+		wantAuth      bool
+		wantRetryable bool
+	}{
+		{"Not found", http.StatusNotFound, []byte(`{"message":"Specified pixel not found.","isSuccess":false}`), "get request failed: Specified pixel not found.", true, false, false},
+		{"Unauthorized", http.StatusUnauthorized, []byte(`{"message":"User token is invalid.","isSuccess":false}`), "get request failed: User token is invalid.", false, true, false},
+		{"Rate limit", http.StatusServiceUnavailable, []byte(`{"message":"Please retry this request.","isSuccess":false}`), "get request failed: Please retry this request.", false, false, true},
+		{"Not JSON", http.StatusBadGateway, []byte(`<html>bad gateway</html>`), "get request failed: Bad Gateway", false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewTestClient(func(req *http.Request) *http.Response {
+				return &http.Response{
+					StatusCode: tt.statusCode,
+					Body:       ioutil.NopCloser(bytes.NewBuffer(tt.response)),
+					Header:     make(http.Header),
+				}
+			})
+
+			pixela, err := New(username, token, debug, OptionHTTPClient(c))
+
+			if err != nil {
+				t.Fatalf("want nil, but %#v", err)
+			}
+
+			_, err = pixela.GetPixel(graphID, dateStr)
+
+			var apiErr *APIError
+
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("want APIError, but %#v", err)
+			}
+
+			if apiErr.StatusCode != tt.statusCode {
+				t.Fatalf("want %d, but %d", tt.statusCode, apiErr.StatusCode)
+			}
+
+			if apiErr.Error() != tt.wantMessage {
+				t.Fatalf("want %#v, but %#v", tt.wantMessage, apiErr.Error())
+			}
+
+			if apiErr.NotFound() != tt.wantNotFound || apiErr.Unauthorized() != tt.wantAuth || apiErr.Retryable() != tt.wantRetryable {
+				t.Fatalf("unexpected classification: %#v", apiErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,430 @@
+package pixela
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PixelRecord is pixel with date (element of graph export and import)
+type PixelRecord struct {
+	Date         string `json:"date"`
+	Quantity     string `json:"quantity"`
+	OptionalData string `json:"optionalData,omitempty"`
+}
+
+// ExportFormat is file format of exported pixels
+type ExportFormat string
+
+// export formats
+const (
+	ExportCSV    ExportFormat = "csv"
+	ExportJSON   ExportFormat = "json"
+	ExportNDJSON ExportFormat = "ndjson"
+)
+
+// ExportFormats returns all export formats
+func ExportFormats() []ExportFormat {
+	return []ExportFormat{ExportCSV, ExportJSON, ExportNDJSON}
+}
+
+func (f ExportFormat) String() string {
+	return string(f)
+}
+
+// ParseExportFormat converts string into ExportFormat
+func ParseExportFormat(s string) (ExportFormat, error) {
+	for _, f := range ExportFormats() {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+
+	return "", EnumParseError("export format", s, ExportFormats())
+}
+
+// ExportOptions is options for WritePixels
+type ExportOptions struct {
+	Format ExportFormat
+	// FlattenOptionalData expands optionalData keys into `optionalData.<key>` columns (fields)
+	FlattenOptionalData bool
+	// Graph is written as header (CSV comment lines) or metadata when it is not nil
+	Graph *Graph
+}
+
+// optionalData column (field) prefix of flattened records
+const flattenPrefix = "optionalData."
+
+// GetGraphPixels is method for `graph export` subcommand.
+// pixels are fetched with quantity and optionalData by one request (`withBody`).
+// when pixe.la returns only dates, each pixel is fetched one by one.
+func (pixela *Pixela) GetGraphPixels(req GraphPixelsRequest) ([]PixelRecord, error) {
+	// argument validation
+	vf := validateField{
+		GraphID: req.GraphID,
+		From:    req.From,
+		To:      req.To,
+	}
+
+	err := pixela.Validator.Validate(vf)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "`graph export`: wrong arguments")
+	}
+
+	// build request url
+	u, _ := url.Parse(baseURL)
+	u.Path = path.Join(u.Path, "v1", "users", pixela.Username, "graphs", req.GraphID, "pixels")
+
+	q := u.Query()
+	q.Set("withBody", "true")
+
+	if len(req.From) != 0 {
+		q.Set("from", req.From)
+	}
+
+	if len(req.To) != 0 {
+		q.Set("to", req.To)
+	}
+
+	u.RawQuery = q.Encode()
+
+	// do request
+	responseBody, err := pixela.get(u.String())
+
+	if err != nil {
+		return nil, errors.Wrap(err, "`graph export`: http request failed")
+	}
+
+	var list struct {
+		Pixels []json.RawMessage `json:"pixels"`
+	}
+
+	if err := json.Unmarshal(responseBody, &list); err != nil {
+		return nil, errors.Wrap(err, "`graph export`: http response parse failed")
+	}
+
+	records := make([]PixelRecord, 0, len(list.Pixels))
+
+	for _, raw := range list.Pixels {
+		record, err := pixela.parsePixelRecord(req.GraphID, raw)
+
+		if err != nil {
+			return nil, errors.Wrap(err, "`graph export`: http response parse failed")
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// element of pixels list is object (with body) or date string (without body)
+func (pixela *Pixela) parsePixelRecord(graphID string, raw json.RawMessage) (PixelRecord, error) {
+	var date string
+
+	if err := json.Unmarshal(raw, &date); err == nil {
+		pixel, err := pixela.GetPixel(graphID, date)
+
+		if err != nil {
+			return PixelRecord{}, err
+		}
+
+		return PixelRecord{Date: date, Quantity: pixel.Quantity, OptionalData: pixel.OptionalData}, nil
+	}
+
+	var body struct {
+		Date         string          `json:"date"`
+		Quantity     string          `json:"quantity"`
+		OptionalData json.RawMessage `json:"optionalData"`
+	}
+
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return PixelRecord{}, err
+	}
+
+	record := PixelRecord{Date: body.Date, Quantity: body.Quantity}
+
+	// optionalData is JSON string (or JSON object)
+	if len(body.OptionalData) != 0 && string(body.OptionalData) != "null" {
+		if err := json.Unmarshal(body.OptionalData, &record.OptionalData); err != nil {
+			record.OptionalData = string(body.OptionalData)
+		}
+	}
+
+	return record, nil
+}
+
+// WritePixels writes pixels in the format
+func WritePixels(w io.Writer, pixels []PixelRecord, opts ExportOptions) error {
+	switch opts.Format {
+	case ExportCSV:
+		return writePixelsCSV(w, pixels, opts)
+	case ExportJSON:
+		return writePixelsJSON(w, pixels, opts)
+	case ExportNDJSON:
+		return writePixelsNDJSON(w, pixels, opts)
+	}
+
+	return fmt.Errorf("unknown export format `%s`", opts.Format)
+}
+
+// CSV has graph definition as comment lines (`# key: value`)
+func writePixelsCSV(w io.Writer, pixels []PixelRecord, opts ExportOptions) error {
+	if opts.Graph != nil {
+		for _, field := range graphMetadata(*opts.Graph) {
+			if _, err := fmt.Fprintf(w, "# %s: %s\n", field[0], field[1]); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	header := []string{"date", "quantity"}
+
+	if !opts.FlattenOptionalData {
+		header = append(header, "optionalData")
+
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+
+		for _, p := range pixels {
+			if err := cw.Write([]string{p.Date, p.Quantity, p.OptionalData}); err != nil {
+				return err
+			}
+		}
+
+		cw.Flush()
+
+		return cw.Error()
+	}
+
+	flattened, keys, err := flattenPixels(pixels)
+
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		header = append(header, flattenPrefix+key)
+	}
+
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for i, p := range pixels {
+		row := []string{p.Date, p.Quantity}
+
+		for _, key := range keys {
+			row = append(row, csvCell(flattened[i][key]))
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// JSON is one object which has `graph` (optional) and `pixels`
+func writePixelsJSON(w io.Writer, pixels []PixelRecord, opts ExportOptions) error {
+	objects, err := pixelObjects(pixels, opts.FlattenOptionalData)
+
+	if err != nil {
+		return err
+	}
+
+	document := struct {
+		Graph  *Graph        `json:"graph,omitempty"`
+		Pixels []interface{} `json:"pixels"`
+	}{opts.Graph, objects}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(document)
+}
+
+// NDJSON has `{"graph": ...}` line (optional) followed by one pixel per line
+func writePixelsNDJSON(w io.Writer, pixels []PixelRecord, opts ExportOptions) error {
+	objects, err := pixelObjects(pixels, opts.FlattenOptionalData)
+
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+
+	if opts.Graph != nil {
+		if err := encoder.Encode(struct {
+			Graph *Graph `json:"graph"`
+		}{opts.Graph}); err != nil {
+			return err
+		}
+	}
+
+	for _, object := range objects {
+		if err := encoder.Encode(object); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pixels as JSON objects. optionalData is embedded as JSON (or flattened into `optionalData.<key>` fields).
+func pixelObjects(pixels []PixelRecord, flatten bool) ([]interface{}, error) {
+	objects := make([]interface{}, 0, len(pixels))
+
+	if flatten {
+		flattened, keys, err := flattenPixels(pixels)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for i, p := range pixels {
+			object := orderedObject{{"date", p.Date}, {"quantity", p.Quantity}}
+
+			for _, key := range keys {
+				if value, ok := flattened[i][key]; ok {
+					object = append(object, objectField{flattenPrefix + key, value})
+				}
+			}
+
+			objects = append(objects, object)
+		}
+
+		return objects, nil
+	}
+
+	for _, p := range pixels {
+		object := orderedObject{{"date", p.Date}, {"quantity", p.Quantity}}
+
+		if p.OptionalData != "" {
+			if !json.Valid([]byte(p.OptionalData)) {
+				return nil, fmt.Errorf("optionalData of %s is not JSON", p.Date)
+			}
+
+			object = append(object, objectField{"optionalData", json.RawMessage(p.OptionalData)})
+		}
+
+		objects = append(objects, object)
+	}
+
+	return objects, nil
+}
+
+// flatten optionalData objects. keys are union of all top level keys (sorted).
+func flattenPixels(pixels []PixelRecord) ([]map[string]interface{}, []string, error) {
+	flattened := make([]map[string]interface{}, len(pixels))
+	keySet := map[string]bool{}
+
+	for i, p := range pixels {
+		flattened[i] = map[string]interface{}{}
+
+		if p.OptionalData == "" {
+			continue
+		}
+
+		decoder := json.NewDecoder(strings.NewReader(p.OptionalData))
+		decoder.UseNumber()
+
+		if err := decoder.Decode(&flattened[i]); err != nil {
+			return nil, nil, errors.Wrapf(err, "optionalData of %s is not JSON object", p.Date)
+		}
+
+		for key := range flattened[i] {
+			keySet[key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(keySet))
+
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return flattened, keys, nil
+}
+
+// flattened value in CSV cell. string is written as it is and others are written as JSON.
+func csvCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+
+	out, _ := json.Marshal(value)
+
+	return string(out)
+}
+
+func graphMetadata(graph Graph) [][2]string {
+	return [][2]string{
+		{"id", graph.ID},
+		{"name", graph.Name},
+		{"unit", graph.Unit},
+		{"type", graph.Type},
+		{"color", graph.Color},
+		{"timezone", graph.Timezone},
+	}
+}
+
+// JSON object keeping field order
+type orderedObject []objectField
+
+type objectField struct {
+	key   string
+	value interface{}
+}
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+
+	for i, field := range o {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(field.key)
+
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(field.value)
+
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
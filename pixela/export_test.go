@@ -0,0 +1,175 @@
+package pixela
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPixela_GetGraphPixels(t *testing.T) {
+	tests := []struct {
+		name     string
+		response map[string]string
+		want     []PixelRecord
+	}{
+		{
+			"with body",
+			map[string]string{
+				"/pixels": `{"pixels":[{"date":"20190101","quantity":"1","optionalData":"{\"key\":\"value\"}"},{"date":"20190102","quantity":"2"}]}`,
+			},
+			[]PixelRecord{{"20190101", "1", `{"key":"value"}`}, {"20190102", "2", ""}},
+		},
+		{
+			"optionalData object",
+			map[string]string{
+				"/pixels": `{"pixels":[{"date":"20190101","quantity":"1","optionalData":{"key":"value"}}]}`,
+			},
+			[]PixelRecord{{"20190101", "1", `{"key":"value"}`}},
+		},
+		{
+			"dates only",
+			map[string]string{
+				"/pixels":   `{"pixels":["20190101","20190102"]}`,
+				"/20190101": `{"quantity":"1","optionalData":"{\"key\":\"value\"}"}`,
+				"/20190102": `{"quantity":"2"}`,
+			},
+			[]PixelRecord{{"20190101", "1", `{"key":"value"}`}, {"20190102", "2", ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewTestClient(func(req *http.Request) *http.Response {
+				if strings.HasSuffix(req.URL.Path, "/pixels") && req.URL.Query().Get("withBody") != "true" {
+					t.Fatalf("want withBody query, but %s", req.URL)
+				}
+
+				for suffix, body := range tt.response {
+					if strings.HasSuffix(req.URL.Path, suffix) {
+						return &http.Response{
+							StatusCode: sucStatus,
+							Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+							Header:     make(http.Header),
+						}
+					}
+				}
+
+				t.Fatalf("unexpected request: %s", req.URL)
+
+				return nil
+			})
+
+			pixela, err := New(username, token, debug, OptionHTTPClient(c))
+
+			if err != nil {
+				t.Fatalf("want nil, but %#v", err)
+			}
+
+			got, err := pixela.GetGraphPixels(GraphPixelsRequest{GraphID: graphID, From: "20190101", To: "20190102"})
+
+			if err != nil {
+				t.Fatalf("want nil, but %#v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("want %#v, but %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWritePixels(t *testing.T) {
+	pixels := []PixelRecord{
+		{"20190101", "1", `{"b":2,"a":"x"}`},
+		{"20190102", "2", ""},
+		{"20190103", "3", `{"c":[1,2]}`},
+	}
+	graph := &Graph{ID: graphID, Name: graphName, Unit: graphUnit, Type: numType, Color: validColor, Timezone: "Asia/Tokyo"}
+
+	tests := []struct {
+		name string
+		opts ExportOptions
+		want string
+	}{
+		{
+			"csv",
+			ExportOptions{Format: ExportCSV},
+			"date,quantity,optionalData\n" +
+				"20190101,1,\"{\"\"b\"\":2,\"\"a\"\":\"\"x\"\"}\"\n" +
+				"20190102,2,\n" +
+				"20190103,3,\"{\"\"c\"\":[1,2]}\"\n",
+		},
+		{
+			"csv flatten with definition",
+			ExportOptions{Format: ExportCSV, FlattenOptionalData: true, Graph: graph},
+			"# id: testgraphid\n# name: testgraphname\n# unit: testunit\n# type: int\n# color: shibafu\n# timezone: Asia/Tokyo\n" +
+				"date,quantity,optionalData.a,optionalData.b,optionalData.c\n" +
+				"20190101,1,x,2,\n" +
+				"20190102,2,,,\n" +
+				"20190103,3,,,\"[1,2]\"\n",
+		},
+		{
+			"ndjson",
+			ExportOptions{Format: ExportNDJSON},
+			`{"date":"20190101","quantity":"1","optionalData":{"b":2,"a":"x"}}` + "\n" +
+				`{"date":"20190102","quantity":"2"}` + "\n" +
+				`{"date":"20190103","quantity":"3","optionalData":{"c":[1,2]}}` + "\n",
+		},
+		{
+			"ndjson flatten with definition",
+			ExportOptions{Format: ExportNDJSON, FlattenOptionalData: true, Graph: graph},
+			`{"graph":{"id":"testgraphid","name":"testgraphname","unit":"testunit","type":"int","color":"shibafu","timezone":"Asia/Tokyo","purgeCacheURLs":null}}` + "\n" +
+				`{"date":"20190101","quantity":"1","optionalData.a":"x","optionalData.b":2}` + "\n" +
+				`{"date":"20190102","quantity":"2"}` + "\n" +
+				`{"date":"20190103","quantity":"3","optionalData.c":[1,2]}` + "\n",
+		},
+		{
+			"json",
+			ExportOptions{Format: ExportJSON},
+			"{\n" +
+				"  \"pixels\": [\n" +
+				"    {\n      \"date\": \"20190101\",\n      \"quantity\": \"1\",\n      \"optionalData\": {\n        \"b\": 2,\n        \"a\": \"x\"\n      }\n    },\n" +
+				"    {\n      \"date\": \"20190102\",\n      \"quantity\": \"2\"\n    },\n" +
+				"    {\n      \"date\": \"20190103\",\n      \"quantity\": \"3\",\n      \"optionalData\": {\n        \"c\": [\n          1,\n          2\n        ]\n      }\n    }\n" +
+				"  ]\n" +
+				"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+
+			if err := WritePixels(buf, pixels, tt.opts); err != nil {
+				t.Fatalf("want nil, but %#v", err)
+			}
+
+			if buf.String() != tt.want {
+				t.Fatalf("want\n%s\nbut\n%s", tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestWritePixels_InvalidOptionalData(t *testing.T) {
+	pixels := []PixelRecord{{"20190101", "1", "not json"}}
+
+	for _, opts := range []ExportOptions{{Format: ExportJSON}, {Format: ExportCSV, FlattenOptionalData: true}} {
+		if err := WritePixels(&bytes.Buffer{}, pixels, opts); err == nil {
+			t.Fatalf("want error, but nil: %#v", opts)
+		}
+	}
+}
+
+func TestParseExportFormat(t *testing.T) {
+	if got, err := ParseExportFormat("ndjson"); err != nil || got != ExportNDJSON {
+		t.Fatalf("want ndjson, but %#v, %#v", got, err)
+	}
+
+	if _, err := ParseExportFormat("xml"); err == nil {
+		t.Fatalf("want error, but nil")
+	}
+}
@@ -0,0 +1,69 @@
+//go:build ignore
+
+// gen_timezones.go generates timezone_names.go (built-in zone names) from zoneinfo.zip of Go distribution.
+//
+//	$ go generate ./pixela
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func main() {
+	goroot, err := exec.Command("go", "env", "GOROOT").Output()
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	r, err := zip.OpenReader(filepath.Join(strings.TrimSpace(string(goroot)), "lib", "time", "zoneinfo.zip"))
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defer r.Close()
+
+	var names []string
+
+	for _, f := range r.File {
+		if !strings.HasSuffix(f.Name, "/") && !strings.Contains(f.Name, ".") && f.Name != "Factory" {
+			names = append(names, f.Name)
+		}
+	}
+
+	sort.Strings(names)
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintln(buf, "// Code generated by gen_timezones.go; DO NOT EDIT.")
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, "package pixela")
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, "// builtinTimezoneNames are zone names of tz database used when it is not installed in the system")
+	fmt.Fprintln(buf, "var builtinTimezoneNames = []string{")
+
+	for _, name := range names {
+		fmt.Fprintf(buf, "\t%q,\n", name)
+	}
+
+	fmt.Fprintln(buf, "}")
+
+	src, err := format.Source(buf.Bytes())
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile("timezone_names.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
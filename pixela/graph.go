@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
 	"path"
+	"sync"
 
 	"github.com/pkg/errors"
 )
@@ -21,6 +23,41 @@ type CreateGraphPayload struct {
 	SelfSufficient string `json:"selfSufficient,omitempty"`
 }
 
+// CreateGraphRequest is request for CreateGraphWithRequest
+type CreateGraphRequest struct {
+	ID             string
+	Name           string
+	Unit           string
+	Type           NumType
+	Color          Color
+	Timezone       string
+	SelfSufficient SelfSufficient
+}
+
+// UpdateGraphRequest is request for UpdateGraphWithRequest
+type UpdateGraphRequest struct {
+	GraphID        string
+	Name           string
+	Unit           string
+	Color          Color
+	Timezone       string
+	PurgeCacheURLs []string
+}
+
+// GraphSvgRequest is request for GetGraphSvgWithRequest
+type GraphSvgRequest struct {
+	GraphID string
+	Date    string
+	Mode    string
+}
+
+// GraphPixelsRequest is request for GetGraphPixelsDateListWithRequest
+type GraphPixelsRequest struct {
+	GraphID string
+	From    string
+	To      string
+}
+
 // GraphDefinitions is response for `graph def` subcommand
 type GraphDefinitions struct {
 	Graphs []Graph `json:"graphs"`
@@ -35,6 +72,7 @@ type Graph struct {
 	Color          string   `json:"color"`
 	Timezone       string   `json:"timezone"`
 	PurgeCacheURLs []string `json:"purgeCacheURLs"`
+	SelfSufficient string   `json:"selfSufficient,omitempty"`
 }
 
 // UpdateGraphPayload is payload for `graph update` subcommand
@@ -63,29 +101,43 @@ type GraphStat struct {
 
 // CreateGraph is method for `graph create` subcommand
 func (pixela *Pixela) CreateGraph(id, name, unit, numType, color, timezone, selfSufficient string) (NoneGetResponseBody, error) {
+	return pixela.CreateGraphWithRequest(CreateGraphRequest{
+		ID:             id,
+		Name:           name,
+		Unit:           unit,
+		Type:           NumType(numType),
+		Color:          Color(color),
+		Timezone:       timezone,
+		SelfSufficient: SelfSufficient(selfSufficient),
+	})
+}
+
+// CreateGraphWithRequest is method for `graph create` subcommand
+func (pixela *Pixela) CreateGraphWithRequest(req CreateGraphRequest) (NoneGetResponseBody, error) {
 	// create payload
 	pl := CreateGraphPayload{
-		ID:      id,
-		Name:    name,
-		Unit:    unit,
-		NumType: numType,
-		Color:   color,
+		ID:      req.ID,
+		Name:    req.Name,
+		Unit:    req.Unit,
+		NumType: req.Type.String(),
+		Color:   req.Color.String(),
 	}
 
 	// argument validation
 	vf := validateField{
-		GraphID:  id,
-		UnitType: numType,
-		Color:    color,
+		GraphID:  req.ID,
+		UnitType: req.Type.String(),
+		Color:    req.Color.String(),
 	}
 
-	if len(timezone) != 0 {
-		pl.Timezone = timezone
+	if len(req.Timezone) != 0 {
+		pl.Timezone = pixela.normalizeTimezone(req.Timezone)
+		vf.Timezone = req.Timezone
 	}
 
-	if len(selfSufficient) != 0 {
-		pl.SelfSufficient = selfSufficient
-		vf.SelfSufficient = selfSufficient
+	if len(req.SelfSufficient) != 0 {
+		pl.SelfSufficient = req.SelfSufficient.String()
+		vf.SelfSufficient = req.SelfSufficient.String()
 	}
 
 	err := pixela.Validator.Validate(vf)
@@ -111,6 +163,9 @@ func (pixela *Pixela) CreateGraph(id, name, unit, numType, color, timezone, self
 		return NoneGetResponseBody{}, errors.Wrap(err, "`graph create`: http request failed")
 	}
 
+	// graph definitions are changed
+	pixela.graphCache.reset()
+
 	postResponseBody := NoneGetResponseBody{}
 	err = json.Unmarshal(responseBody, &postResponseBody)
 
@@ -121,6 +176,19 @@ func (pixela *Pixela) CreateGraph(id, name, unit, numType, color, timezone, self
 	return postResponseBody, nil
 }
 
+// canonicalize timezone unless validation is off (then send as it is)
+func (pixela *Pixela) normalizeTimezone(timezone string) string {
+	if len(timezone) == 0 || pixela.Validator.Mode() == ValidationOff {
+		return timezone
+	}
+
+	if tz, err := NormalizeTimezone(timezone); err == nil {
+		return tz
+	}
+
+	return timezone
+}
+
 // GetGraphDefinition is method for `graph get` subcommand
 func (pixela *Pixela) GetGraphDefinition() (GraphDefinitions, error) {
 	// build request url
@@ -142,14 +210,84 @@ func (pixela *Pixela) GetGraphDefinition() (GraphDefinitions, error) {
 		return GraphDefinitions{}, errors.Wrap(err, "`graph get`: http response parse failed")
 	}
 
+	pixela.graphCache.set(graphDefinitions)
+
 	return graphDefinitions, nil
 }
 
+// graph definitions cache to resolve graph properties (such as type) without requests
+type graphDefinitionCache struct {
+	mu     sync.Mutex
+	loaded bool
+	graphs map[string]Graph
+}
+
+func (c *graphDefinitionCache) set(definitions GraphDefinitions) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.graphs = make(map[string]Graph, len(definitions.Graphs))
+
+	for _, g := range definitions.Graphs {
+		c.graphs[g.ID] = g
+	}
+
+	c.loaded = true
+}
+
+func (c *graphDefinitionCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.graphs, c.loaded = nil, false
+}
+
+func (c *graphDefinitionCache) get(graphID string) (Graph, bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	g, ok := c.graphs[graphID]
+
+	return g, ok, c.loaded
+}
+
+// LookupGraph returns graph definition from cache.
+// Graph definitions are fetched by GetGraphDefinition only when cache is empty.
+func (pixela *Pixela) LookupGraph(graphID string) (Graph, error) {
+	graph, found, loaded := pixela.graphCache.get(graphID)
+
+	if !loaded {
+		_, err := pixela.GetGraphDefinition()
+
+		if err != nil {
+			return Graph{}, err
+		}
+
+		graph, found, _ = pixela.graphCache.get(graphID)
+	}
+
+	// same as response of pe.la for missing graph, so caller can tell it from failed request
+	if !found {
+		return Graph{}, newAPIError(http.MethodGet, http.StatusNotFound, fmt.Sprintf("graph `%s` is not found", graphID))
+	}
+
+	return graph, nil
+}
+
 // GetGraphSvg is method for `graph svg` subcommand
 func (pixela *Pixela) GetGraphSvg(graphID, date, mode string) ([]byte, error) {
+	return pixela.GetGraphSvgWithRequest(GraphSvgRequest{
+		GraphID: graphID,
+		Date:    date,
+		Mode:    mode,
+	})
+}
+
+// GetGraphSvgWithRequest is method for `graph svg` subcommand
+func (pixela *Pixela) GetGraphSvgWithRequest(req GraphSvgRequest) ([]byte, error) {
 	// argument validation
 	vf := validateField{
-		GraphID: graphID,
+		GraphID: req.GraphID,
 	}
 
 	err := pixela.Validator.Validate(vf)
@@ -160,18 +298,18 @@ func (pixela *Pixela) GetGraphSvg(graphID, date, mode string) ([]byte, error) {
 
 	// build request url
 	u, _ := url.Parse(baseURL)
-	u.Path = path.Join(u.Path, "v1", "users", pixela.Username, "graphs", graphID)
+	u.Path = path.Join(u.Path, "v1", "users", pixela.Username, "graphs", req.GraphID)
 
 	// set query
-	if len(date) != 0 || len(mode) != 0 {
+	if len(req.Date) != 0 || len(req.Mode) != 0 {
 		q := u.Query()
 
-		if len(date) != 0 {
-			q.Set("date", date)
+		if len(req.Date) != 0 {
+			q.Set("date", req.Date)
 		}
 
-		if len(mode) != 0 {
-			q.Set("mode", mode)
+		if len(req.Mode) != 0 {
+			q.Set("mode", req.Mode)
 		}
 		u.RawQuery = q.Encode()
 	}
@@ -190,9 +328,22 @@ func (pixela *Pixela) GetGraphSvg(graphID, date, mode string) ([]byte, error) {
 
 // UpdateGraph is method for `graph update` subcommand
 func (pixela *Pixela) UpdateGraph(graphID string, payload UpdateGraphPayload) (NoneGetResponseBody, error) {
+	return pixela.UpdateGraphWithRequest(UpdateGraphRequest{
+		GraphID:        graphID,
+		Name:           payload.Name,
+		Unit:           payload.Unit,
+		Color:          Color(payload.Color),
+		Timezone:       payload.Timezone,
+		PurgeCacheURLs: payload.PurgeCacheURLs,
+	})
+}
+
+// UpdateGraphWithRequest is method for `graph update` subcommand
+func (pixela *Pixela) UpdateGraphWithRequest(req UpdateGraphRequest) (NoneGetResponseBody, error) {
 	// argument validation
 	vf := validateField{
-		GraphID: graphID,
+		GraphID:  req.GraphID,
+		Timezone: req.Timezone,
 	}
 
 	err := pixela.Validator.Validate(vf)
@@ -204,9 +355,18 @@ func (pixela *Pixela) UpdateGraph(graphID string, payload UpdateGraphPayload) (N
 	// build request url
 	// TODO: rewrite by url package
 	requestURL := fmt.Sprintf(
-		"%s/v1/users/%s/graphs/%s", baseURL, pixela.Username, graphID)
+		"%s/v1/users/%s/graphs/%s", baseURL, pixela.Username, req.GraphID)
+
+	// create payload
+	pl := UpdateGraphPayload{
+		Name:           req.Name,
+		Unit:           req.Unit,
+		Color:          req.Color.String(),
+		Timezone:       pixela.normalizeTimezone(req.Timezone),
+		PurgeCacheURLs: req.PurgeCacheURLs,
+	}
 
-	plJSON, err := json.Marshal(payload)
+	plJSON, err := json.Marshal(pl)
 
 	if err != nil {
 		return NoneGetResponseBody{}, errors.Wrap(err, "`graph update`: can not marshal request payload")
@@ -219,6 +379,9 @@ func (pixela *Pixela) UpdateGraph(graphID string, payload UpdateGraphPayload) (N
 		return NoneGetResponseBody{}, errors.Wrap(err, "`graph update`: http request failed")
 	}
 
+	// graph definitions are changed
+	pixela.graphCache.reset()
+
 	postResponseBody := NoneGetResponseBody{}
 	err = json.Unmarshal(responseBody, &postResponseBody)
 
@@ -254,6 +417,9 @@ func (pixela *Pixela) DeleteGraph(graphID string) (NoneGetResponseBody, error) {
 		return NoneGetResponseBody{}, errors.Wrap(err, "`graph delete`: http request failed")
 	}
 
+	// graph definitions are changed
+	pixela.graphCache.reset()
+
 	postResponseBody := NoneGetResponseBody{}
 	err = json.Unmarshal(responseBody, &postResponseBody)
 
@@ -266,11 +432,20 @@ func (pixela *Pixela) DeleteGraph(graphID string) (NoneGetResponseBody, error) {
 
 // GetGraphPixelsDateList is method for `graph pixels` subcommand
 func (pixela *Pixela) GetGraphPixelsDateList(graphID, from, to string) (PixelsDateList, error) {
-	// argument validation
-	vf := validateField{
+	return pixela.GetGraphPixelsDateListWithRequest(GraphPixelsRequest{
 		GraphID: graphID,
 		From:    from,
 		To:      to,
+	})
+}
+
+// GetGraphPixelsDateListWithRequest is method for `graph pixels` subcommand
+func (pixela *Pixela) GetGraphPixelsDateListWithRequest(req GraphPixelsRequest) (PixelsDateList, error) {
+	// argument validation
+	vf := validateField{
+		GraphID: req.GraphID,
+		From:    req.From,
+		To:      req.To,
 	}
 
 	err := pixela.Validator.Validate(vf)
@@ -281,18 +456,18 @@ func (pixela *Pixela) GetGraphPixelsDateList(graphID, from, to string) (PixelsDa
 
 	// build request url
 	u, _ := url.Parse(baseURL)
-	u.Path = path.Join(u.Path, "v1", "users", pixela.Username, "graphs", graphID, "pixels")
+	u.Path = path.Join(u.Path, "v1", "users", pixela.Username, "graphs", req.GraphID, "pixels")
 
 	// set query
-	if len(from) != 0 || len(to) != 0 {
+	if len(req.From) != 0 || len(req.To) != 0 {
 		q := u.Query()
 
-		if len(from) != 0 {
-			q.Set("from", from)
+		if len(req.From) != 0 {
+			q.Set("from", req.From)
 		}
 
-		if len(to) != 0 {
-			q.Set("to", to)
+		if len(req.To) != 0 {
+			q.Set("to", req.To)
 		}
 		u.RawQuery = q.Encode()
 	}
@@ -12,6 +12,7 @@ func TestPixela_CreateGraph(t *testing.T) {
 	ivNumTypeErr := newCommandError(graphCreate, "wrong arguments: "+validationErrorMessages["UnitType"])
 	ivColorErr := newCommandError(graphCreate, "wrong arguments: "+validationErrorMessages["Color"])
 	ivSelfSufficientErr := newCommandError(graphCreate, "wrong arguments: "+validationErrorMessages["SelfSufficient"])
+	ivTimezoneErr := newCommandError(graphCreate, "wrong arguments: "+validationErrorMessages["Timezone"])
 	respDataErr := newCommandError(graphCreate, "http request failed: post request failed: errorMessage")
 
 	tests := testCases{
@@ -22,6 +23,8 @@ func TestPixela_CreateGraph(t *testing.T) {
 		{"invalid number type", 0, nil, ivNumTypeErr, []string{graphID, graphName, graphUnit, "string", validColor, "", ""}},
 		{"invalid color", 0, nil, ivColorErr, []string{graphID, graphName, graphUnit, numType, "invalid color", "", ""}},
 		{"invalid self sufficient", 0, nil, ivSelfSufficientErr, []string{graphID, graphName, graphUnit, numType, validColor, "", "invalid ss"}},
+		{"invalid timezone", 0, nil, ivTimezoneErr, []string{graphID, graphName, graphUnit, numType, validColor, "Asia/Tokio", ""}},
+		{"timezone alias", sucStatus, scResp, nil, []string{graphID, graphName, graphUnit, numType, validColor, "Japan", ""}},
 		{"invalid response status", errStatus, errResp, respDataErr, []string{graphID, graphName, graphUnit, numType, validColor, "", ""}},
 		{"invalid response data", sucStatus, errResp, respDataErr, []string{graphID, graphName, graphUnit, numType, validColor, "", ""}},
 	}
@@ -33,6 +36,7 @@ func TestPixela_UpdateGraph(t *testing.T) {
 	graphUpdateURL := fmt.Sprintf("%s/v1/users/%s/graphs/%s", baseURL, username, graphID)
 
 	ivGraphIDErr := newCommandError(graphUpdate, "wrong arguments: "+validationErrorMessages["GraphID"])
+	ivTimezoneErr := newCommandError(graphUpdate, "wrong arguments: "+validationErrorMessages["Timezone"])
 	respDataErr := newCommandError(graphUpdate, "http request failed: put request failed: errorMessage")
 
 	tests := testCases{
@@ -40,6 +44,7 @@ func TestPixela_UpdateGraph(t *testing.T) {
 		{"normal case wo self sufficient", sucStatus, scResp, nil, []string{graphID, graphName, graphUnit, numType, validColor, "Asia/Tokyo", ""}},
 		{"normal case wo timezone and self sufficient", sucStatus, scResp, nil, []string{graphID, graphName, graphUnit, numType, validColor, "", ""}},
 		{"invalid graph id", 0, nil, ivGraphIDErr, []string{"0000", graphName, graphUnit, numType, validColor, "", ""}},
+		{"invalid timezone", 0, nil, ivTimezoneErr, []string{graphID, graphName, graphUnit, numType, validColor, "Tokyo", ""}},
 		{"invalid response status", errStatus, errResp, respDataErr, []string{graphID, graphName, graphUnit, numType, validColor, "", ""}},
 		{"invalid response data", sucStatus, errResp, respDataErr, []string{graphID, graphName, graphUnit, numType, validColor, "", ""}},
 	}
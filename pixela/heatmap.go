@@ -0,0 +1,170 @@
+package pixela
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// HeatmapLevels is number of color levels of pixels which have quantity (level 0 is empty)
+const HeatmapLevels = 4
+
+// RGB is 24 bit color
+type RGB struct {
+	R, G, B uint8
+}
+
+// Hex returns `#rrggbb` representation
+func (c RGB) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// blend colors (ratio 0 is c and 1 is other)
+func (c RGB) blend(other RGB, ratio float64) RGB {
+	mix := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*ratio))
+	}
+
+	return RGB{mix(c.R, other.R), mix(c.G, other.G), mix(c.B, other.B)}
+}
+
+// base colors of graph colors
+var colorBases = map[Color]RGB{
+	ColorShibafu: {0x39, 0xa8, 0x1d},
+	ColorMomiji:  {0xd5, 0x2a, 0x1f},
+	ColorSora:    {0x1e, 0x7f, 0xd8},
+	ColorIchou:   {0xf0, 0xb4, 0x00},
+	ColorAjisai:  {0x89, 0x3f, 0xc0},
+	ColorKuro:    {0x1f, 0x1f, 0x1f},
+}
+
+// EmptyPixelColor is color of pixel which has no quantity
+var EmptyPixelColor = RGB{0xee, 0xee, 0xee}
+
+// BaseColor returns color of highest level of graph color. unknown color is treated as shibafu.
+func BaseColor(color Color) RGB {
+	if base, ok := colorBases[color]; ok {
+		return base
+	}
+
+	return colorBases[ColorShibafu]
+}
+
+// Palette returns colors of heatmap levels (index 0 is empty pixel) for graph color.
+// unknown color is treated as shibafu.
+func Palette(color Color) []RGB {
+	return PaletteFrom(BaseColor(color), EmptyPixelColor)
+}
+
+// PaletteFrom returns colors of heatmap levels blending empty pixel color into base color
+func PaletteFrom(base, empty RGB) []RGB {
+	palette := []RGB{empty}
+
+	for level := 1; level <= HeatmapLevels; level++ {
+		palette = append(palette, empty.blend(base, float64(level)/HeatmapLevels))
+	}
+
+	return palette
+}
+
+// HeatmapCell is one day of heatmap
+type HeatmapCell struct {
+	Date     time.Time
+	Quantity float64
+	// HasPixel is false when the day has no pixel
+	HasPixel bool
+	// Level is color level (0 to HeatmapLevels)
+	Level int
+	// InRange is false for days after end of heatmap (padding of last week)
+	InRange bool
+}
+
+// Heatmap is weekly grid of pixels (columns are weeks from Sunday to Saturday)
+type Heatmap struct {
+	Weeks [][7]HeatmapCell
+	// Max is max quantity in heatmap (level 4)
+	Max float64
+	// Total is sum of quantities in heatmap
+	Total float64
+}
+
+// HeatmapStart returns first day (Sunday) of heatmap which has weeks ending at end date
+func HeatmapStart(end time.Time, weeks int) time.Time {
+	if weeks < 1 {
+		weeks = 1
+	}
+
+	end = truncateDay(end)
+
+	return end.AddDate(0, 0, -int(end.Weekday())-7*(weeks-1))
+}
+
+// NewHeatmap creates heatmap of weeks ending at end date.
+// level of pixel is ratio of quantity to max quantity in heatmap (pixel which is not positive is level 0).
+func NewHeatmap(pixels []PixelRecord, end time.Time, weeks int) (Heatmap, error) {
+	quantities := map[string]float64{}
+
+	for _, p := range pixels {
+		q, err := strconv.ParseFloat(p.Quantity, 64)
+
+		if err != nil {
+			return Heatmap{}, fmt.Errorf("quantity `%s` of %s is not a number", p.Quantity, p.Date)
+		}
+
+		quantities[p.Date] = q
+	}
+
+	end = truncateDay(end)
+	day := HeatmapStart(end, weeks)
+	heatmap := Heatmap{}
+
+	for !day.After(end) {
+		var week [7]HeatmapCell
+
+		for i := range week {
+			q, ok := quantities[day.Format(DateFormat)]
+			week[i] = HeatmapCell{Date: day, Quantity: q, HasPixel: ok, InRange: !day.After(end)}
+
+			if ok && week[i].InRange {
+				heatmap.Total += q
+				heatmap.Max = math.Max(heatmap.Max, q)
+			}
+
+			day = day.AddDate(0, 0, 1)
+		}
+
+		heatmap.Weeks = append(heatmap.Weeks, week)
+	}
+
+	for w := range heatmap.Weeks {
+		for d := range heatmap.Weeks[w] {
+			cell := &heatmap.Weeks[w][d]
+
+			if cell.InRange && cell.HasPixel {
+				cell.Level = HeatmapLevel(cell.Quantity, heatmap.Max)
+			}
+		}
+	}
+
+	return heatmap, nil
+}
+
+// HeatmapLevel returns color level of quantity for max quantity
+func HeatmapLevel(quantity, max float64) int {
+	if quantity <= 0 || max <= 0 {
+		return 0
+	}
+
+	level := int(math.Ceil(quantity / max * HeatmapLevels))
+
+	if level > HeatmapLevels {
+		return HeatmapLevels
+	}
+
+	return level
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,105 @@
+package pixela
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewHeatmap(t *testing.T) {
+	// 2019-01-09 is Wednesday
+	end := time.Date(2019, 1, 9, 15, 0, 0, 0, time.UTC)
+	pixels := []PixelRecord{
+		{Date: "20181230", Quantity: "8"},
+		{Date: "20190101", Quantity: "2"},
+		{Date: "20190109", Quantity: "5.5"},
+		{Date: "20190110", Quantity: "100"},
+	}
+
+	heatmap, err := NewHeatmap(pixels, end, 2)
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	if len(heatmap.Weeks) != 2 {
+		t.Fatalf("want 2 weeks, but %d", len(heatmap.Weeks))
+	}
+
+	if got := heatmap.Weeks[0][0].Date.Format(DateFormat); got != "20181230" {
+		t.Fatalf("want 20181230, but %s", got)
+	}
+
+	if heatmap.Max != 8 || heatmap.Total != 15.5 {
+		t.Fatalf("want max 8 and total 15.5, but %v and %v", heatmap.Max, heatmap.Total)
+	}
+
+	var levels, inRange []int
+
+	for _, week := range heatmap.Weeks {
+		for _, day := range week {
+			levels = append(levels, day.Level)
+
+			if day.InRange {
+				inRange = append(inRange, day.Date.Day())
+			}
+		}
+	}
+
+	wantLevels := []int{4, 0, 1, 0, 0, 0, 0, 0, 0, 0, 3, 0, 0, 0}
+
+	if !reflect.DeepEqual(levels, wantLevels) {
+		t.Fatalf("want %v, but %v", wantLevels, levels)
+	}
+
+	wantInRange := []int{30, 31, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+
+	if !reflect.DeepEqual(inRange, wantInRange) {
+		t.Fatalf("want %v, but %v", wantInRange, inRange)
+	}
+
+	if _, err := NewHeatmap([]PixelRecord{{Date: "20190101", Quantity: "one"}}, end, 1); err == nil {
+		t.Fatalf("want error, but nil")
+	}
+}
+
+func TestHeatmapLevel(t *testing.T) {
+	tests := []struct {
+		quantity float64
+		max      float64
+		want     int
+	}{
+		{0, 10, 0},
+		{-1, 10, 0},
+		{1, 10, 1},
+		{2.5, 10, 1},
+		{2.6, 10, 2},
+		{7.5, 10, 3},
+		{10, 10, 4},
+		{1, 0, 0},
+	}
+
+	for _, tt := range tests {
+		if got := HeatmapLevel(tt.quantity, tt.max); got != tt.want {
+			t.Fatalf("%v/%v: want %d, but %d", tt.quantity, tt.max, tt.want, got)
+		}
+	}
+}
+
+func TestPalette(t *testing.T) {
+	for _, color := range Colors() {
+		palette := Palette(color)
+
+		if len(palette) != HeatmapLevels+1 || palette[0] != EmptyPixelColor || palette[HeatmapLevels] != colorBases[color] {
+			t.Fatalf("unexpected palette of %s: %v", color, palette)
+		}
+	}
+
+	if !reflect.DeepEqual(Palette("unknown"), Palette(ColorShibafu)) {
+		t.Fatalf("want shibafu palette for unknown color")
+	}
+
+	if got := (RGB{0x39, 0xa8, 0x1d}).Hex(); got != "#39a81d" {
+		t.Fatalf("want #39a81d, but %s", got)
+	}
+}
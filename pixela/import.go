@@ -0,0 +1,580 @@
+package pixela
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ImportMode is behavior for pixels which already exist
+type ImportMode string
+
+// import modes
+const (
+	// ImportSkipExisting keeps existing pixels
+	ImportSkipExisting ImportMode = "skip-existing"
+	// ImportOverwrite replaces quantity and optionalData of existing pixels
+	ImportOverwrite ImportMode = "overwrite"
+	// ImportAdd adds quantity to existing pixels
+	ImportAdd ImportMode = "add"
+)
+
+// ImportModes returns all import modes
+func ImportModes() []ImportMode {
+	return []ImportMode{ImportSkipExisting, ImportOverwrite, ImportAdd}
+}
+
+func (m ImportMode) String() string {
+	return string(m)
+}
+
+// ParseImportMode converts string into ImportMode
+func ParseImportMode(s string) (ImportMode, error) {
+	for _, m := range ImportModes() {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+
+	return "", EnumParseError("import mode", s, ImportModes())
+}
+
+// ImportMapping is column names (CSV header or JSON keys) of pixel fields
+type ImportMapping struct {
+	Date         string
+	Quantity     string
+	OptionalData string
+}
+
+// DefaultImportMapping returns mapping of files written by WritePixels
+func DefaultImportMapping() ImportMapping {
+	return ImportMapping{Date: "date", Quantity: "quantity", OptionalData: "optionalData"}
+}
+
+// RowError is error of one row (record) in imported file
+type RowError struct {
+	// Row is 1 origin record number (header and comment lines are not counted)
+	Row  int
+	Date string
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	if e.Date != "" {
+		return fmt.Sprintf("row %d (%s): %s", e.Row, e.Date, e.Err)
+	}
+
+	return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// ImportError is errors of all wrong rows in imported file
+type ImportError struct {
+	Errors []*RowError
+}
+
+func (e *ImportError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+
+	for _, re := range e.Errors {
+		messages = append(messages, re.Error())
+	}
+
+	return fmt.Sprintf("%d invalid rows:\n%s", len(e.Errors), strings.Join(messages, "\n"))
+}
+
+// Unwrap returns row errors (errors.As finds ValidationError of rows)
+func (e *ImportError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+
+	for _, re := range e.Errors {
+		errs = append(errs, re)
+	}
+
+	return errs
+}
+
+// ReadPixels reads pixels from file written in format.
+// files written by WritePixels (including `--flatten` and `--with-definition`) can be read by DefaultImportMapping.
+// date accepts yyyyMMdd, yyyy-MM-dd and yyyy/MM/dd.
+func ReadPixels(r io.Reader, format ExportFormat, mapping ImportMapping) ([]PixelRecord, error) {
+	var rows []map[string]interface{}
+	var err error
+
+	switch format {
+	case ExportCSV:
+		rows, err = readCSVRows(r)
+	case ExportJSON:
+		rows, err = readJSONRows(r)
+	case ExportNDJSON:
+		rows, err = readNDJSONRows(r)
+	default:
+		err = fmt.Errorf("unknown import format `%s`", format)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	pixels := make([]PixelRecord, 0, len(rows))
+	importErr := &ImportError{}
+	seen := map[string]int{}
+
+	for i, row := range rows {
+		pixel, err := rowToPixel(row, mapping)
+
+		if err == nil {
+			if first, ok := seen[pixel.Date]; ok {
+				err = fmt.Errorf("duplicated date (first at row %d)", first)
+			}
+
+			seen[pixel.Date] = i + 1
+		}
+
+		if err != nil {
+			importErr.Errors = append(importErr.Errors, &RowError{Row: i + 1, Date: pixel.Date, Err: err})
+			continue
+		}
+
+		pixels = append(pixels, pixel)
+	}
+
+	if len(importErr.Errors) != 0 {
+		return nil, importErr
+	}
+
+	return pixels, nil
+}
+
+// ReadGraphDefinition reads graph definition written by WritePixels (`--with-definition`).
+// it returns nil when the file has no graph definition.
+func ReadGraphDefinition(r io.Reader, format ExportFormat) (*Graph, error) {
+	switch format {
+	case ExportCSV:
+		fields := map[string]string{}
+		scanner := bufio.NewScanner(r)
+
+		for scanner.Scan() {
+			line := scanner.Text()
+
+			if !strings.HasPrefix(line, "#") {
+				break
+			}
+
+			if kv := strings.SplitN(strings.TrimSpace(strings.TrimPrefix(line, "#")), ":", 2); len(kv) == 2 {
+				fields[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			return nil, errors.Wrap(err, "can not read CSV")
+		}
+
+		if len(fields) == 0 {
+			return nil, nil
+		}
+
+		return &Graph{
+			ID:       fields["id"],
+			Name:     fields["name"],
+			Unit:     fields["unit"],
+			Type:     fields["type"],
+			Color:    fields["color"],
+			Timezone: fields["timezone"],
+		}, nil
+	case ExportJSON, ExportNDJSON:
+		var document struct {
+			Graph *Graph `json:"graph"`
+		}
+
+		// graph is field of JSON object or first line of NDJSON
+		decoder := json.NewDecoder(r)
+		var first json.RawMessage
+
+		if err := decoder.Decode(&first); err == io.EOF {
+			return nil, nil
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "can not read %s", strings.ToUpper(format.String()))
+		}
+
+		if trimmed := bytes.TrimSpace(first); len(trimmed) == 0 || trimmed[0] != '{' {
+			return nil, nil
+		}
+
+		if err := json.Unmarshal(first, &document); err != nil {
+			return nil, errors.Wrapf(err, "can not read %s", strings.ToUpper(format.String()))
+		}
+
+		return document.Graph, nil
+	}
+
+	return nil, fmt.Errorf("unknown import format `%s`", format)
+}
+
+// CSV rows. lines starting with `#` (graph definition written by WritePixels) are skipped.
+func readCSVRows(r io.Reader) ([]map[string]interface{}, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	// short rows are reported as missing columns
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+
+	if err == io.EOF {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "can not read CSV header")
+	}
+
+	var rows []map[string]interface{}
+
+	for {
+		record, err := cr.Read()
+
+		if err == io.EOF {
+			return rows, nil
+		}
+
+		if err != nil {
+			return nil, errors.Wrap(err, "can not read CSV")
+		}
+
+		row := map[string]interface{}{}
+
+		for i, column := range header {
+			if i < len(record) && record[i] != "" {
+				row[column] = record[i]
+			}
+		}
+
+		rows = append(rows, row)
+	}
+}
+
+// JSON rows. file is array of pixels or object which has `pixels` (written by WritePixels).
+func readJSONRows(r io.Reader) ([]map[string]interface{}, error) {
+	data, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []map[string]interface{}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) != 0 && trimmed[0] == '[' {
+		err = decodeJSON(trimmed, &rows)
+	} else {
+		var document struct {
+			Pixels []map[string]interface{} `json:"pixels"`
+		}
+
+		err = decodeJSON(trimmed, &document)
+		rows = document.Pixels
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "can not read JSON")
+	}
+
+	return rows, nil
+}
+
+// NDJSON rows. `{"graph": ...}` line (written by WritePixels) and empty lines are skipped.
+func readNDJSONRows(r io.Reader) ([]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+
+		if len(text) == 0 {
+			continue
+		}
+
+		row := map[string]interface{}{}
+
+		if err := decodeJSON(text, &row); err != nil {
+			return nil, errors.Wrapf(err, "can not read NDJSON line %d", line)
+		}
+
+		if _, ok := row["graph"]; ok && len(row) == 1 {
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "can not read NDJSON")
+	}
+
+	return rows, nil
+}
+
+func decodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(v)
+}
+
+func rowToPixel(row map[string]interface{}, mapping ImportMapping) (PixelRecord, error) {
+	var pixel PixelRecord
+
+	date, ok := row[mapping.Date]
+
+	if !ok {
+		return pixel, fmt.Errorf("`%s` is missing", mapping.Date)
+	}
+
+	dateStr := fmt.Sprint(date)
+
+	if !IsAbsoluteDate(dateStr) {
+		return pixel, fmt.Errorf("invalid date `%s`", dateStr)
+	}
+
+	pixel.Date, _ = ResolveDate(dateStr, time.Time{}, time.UTC)
+
+	quantity, ok := row[mapping.Quantity]
+
+	if !ok {
+		return pixel, fmt.Errorf("`%s` is missing", mapping.Quantity)
+	}
+
+	pixel.Quantity = fmt.Sprint(quantity)
+
+	optionalData, err := rowOptionalData(row, mapping.OptionalData)
+
+	if err != nil {
+		return pixel, err
+	}
+
+	pixel.OptionalData = optionalData
+
+	return pixel, nil
+}
+
+// optionalData is JSON string, JSON object or flattened `<column>.<key>` columns
+func rowOptionalData(row map[string]interface{}, column string) (string, error) {
+	if value, ok := row[column]; ok {
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case nil:
+			return "", nil
+		}
+
+		out, err := json.Marshal(value)
+
+		return string(out), err
+	}
+
+	flattened := map[string]interface{}{}
+	prefix := column + "."
+
+	for key, value := range row {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		// CSV cells which are not string are written as JSON by WritePixels
+		if s, ok := value.(string); ok {
+			var decoded interface{}
+
+			if decodeJSON([]byte(s), &decoded) == nil {
+				if _, isString := decoded.(string); !isString {
+					value = decoded
+				}
+			}
+		}
+
+		flattened[strings.TrimPrefix(key, prefix)] = value
+	}
+
+	if len(flattened) == 0 {
+		return "", nil
+	}
+
+	out, err := json.Marshal(flattened)
+
+	return string(out), err
+}
+
+// ValidatePixels validates all pixels before import and returns ImportError which has all wrong rows
+func (pixela *Pixela) ValidatePixels(graphID string, pixels []PixelRecord) error {
+	importErr := &ImportError{}
+
+	for i, p := range pixels {
+		vf := validateField{
+			GraphID:      graphID,
+			Date:         p.Date,
+			Quantity:     p.Quantity,
+			OptionalData: p.OptionalData,
+		}
+
+		err := pixela.Validator.Validate(vf)
+
+		if err == nil {
+			err = pixela.Validator.ValidateOptionalDataSchema(pixela.optionalDataSchema(graphID), p.OptionalData)
+		}
+
+		if err == nil {
+			err = pixela.validateQuantityType(graphID, p.Quantity)
+		}
+
+		if err != nil {
+			importErr.Errors = append(importErr.Errors, &RowError{Row: i + 1, Date: p.Date, Err: err})
+		}
+	}
+
+	if len(importErr.Errors) != 0 {
+		return importErr
+	}
+
+	return nil
+}
+
+// ImportAction is what import does for one pixel
+type ImportAction string
+
+// import actions
+const (
+	ImportCreate ImportAction = "create"
+	ImportUpdate ImportAction = "update"
+	ImportSkip   ImportAction = "skip"
+)
+
+// ImportChange is planned change of one pixel
+type ImportChange struct {
+	Action ImportAction `json:"action"`
+	// Old is existing pixel (nil when pixel does not exist)
+	Old *PixelRecord `json:"old,omitempty"`
+	// New is pixel after import (same as Old when it is skipped)
+	New PixelRecord `json:"new"`
+}
+
+// PlanImport compares imported pixels with existing pixels and returns changes sorted by date
+func PlanImport(pixels, existing []PixelRecord, mode ImportMode) ([]ImportChange, error) {
+	existingMap := make(map[string]PixelRecord, len(existing))
+
+	for _, p := range existing {
+		existingMap[p.Date] = p
+	}
+
+	changes := make([]ImportChange, 0, len(pixels))
+
+	for _, p := range pixels {
+		old, ok := existingMap[p.Date]
+
+		if !ok {
+			changes = append(changes, ImportChange{Action: ImportCreate, New: p})
+			continue
+		}
+
+		change := ImportChange{Action: ImportUpdate, Old: &old, New: p}
+
+		switch mode {
+		case ImportSkipExisting:
+			change.Action, change.New = ImportSkip, old
+		case ImportAdd:
+			quantity, err := AddPixelQuantity(old.Quantity, p.Quantity)
+
+			if err != nil {
+				return nil, errors.Wrapf(err, "can not add quantity of %s", p.Date)
+			}
+
+			change.New.Quantity = quantity
+
+			if change.New.OptionalData == "" {
+				change.New.OptionalData = old.OptionalData
+			}
+		case ImportOverwrite:
+		default:
+			return nil, fmt.Errorf("unknown import mode `%s`", mode)
+		}
+
+		if change.Action == ImportUpdate && change.New == old {
+			change.Action = ImportSkip
+		}
+
+		changes = append(changes, change)
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].New.Date < changes[j].New.Date
+	})
+
+	return changes, nil
+}
+
+// AddPixelQuantity returns sum of quantities.
+// sum of integers is integer and sum including decimal is decimal (without floating point error).
+func AddPixelQuantity(a, b string) (string, error) {
+	x, okX := new(big.Rat).SetString(a)
+	y, okY := new(big.Rat).SetString(b)
+
+	if !okX || !okY {
+		return "", fmt.Errorf("quantity `%s` or `%s` is not a number", a, b)
+	}
+
+	sum := new(big.Rat).Add(x, y)
+
+	if sum.IsInt() && !strings.Contains(a+b, ".") {
+		return sum.Num().String(), nil
+	}
+
+	return formatDecimal(sum), nil
+}
+
+// ApplyImport applies changes to graph (skip is ignored). done is called after each change is applied.
+func (pixela *Pixela) ApplyImport(graphID string, changes []ImportChange, done func(ImportChange) error) error {
+	for _, change := range changes {
+		req := PixelRequest{
+			GraphID:      graphID,
+			Date:         change.New.Date,
+			Quantity:     change.New.Quantity,
+			OptionalData: change.New.OptionalData,
+		}
+
+		var err error
+
+		switch change.Action {
+		case ImportCreate:
+			_, err = pixela.PostPixelWithRequest(req)
+		case ImportUpdate:
+			_, err = pixela.UpdatePixelWithRequest(req)
+		default:
+			continue
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "`graph import`: %s %s failed", change.Action, change.New.Date)
+		}
+
+		if done != nil {
+			if err := done(change); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
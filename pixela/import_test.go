@@ -0,0 +1,300 @@
+package pixela
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadPixels(t *testing.T) {
+	want := []PixelRecord{
+		{"20190101", "1", `{"a":"x","b":2}`},
+		{"20190102", "2", ""},
+	}
+
+	tests := []struct {
+		name    string
+		format  ExportFormat
+		mapping ImportMapping
+		input   string
+		want    []PixelRecord
+	}{
+		{
+			"csv",
+			ExportCSV,
+			DefaultImportMapping(),
+			"date,quantity,optionalData\n20190101,1,\"{\"\"a\"\":\"\"x\"\",\"\"b\"\":2}\"\n20190102,2,\n",
+			want,
+		},
+		{
+			"csv written with flatten and definition",
+			ExportCSV,
+			DefaultImportMapping(),
+			"# id: testgraphid\ndate,quantity,optionalData.a,optionalData.b\n20190101,1,x,2\n20190102,2,,\n",
+			want,
+		},
+		{
+			"csv with mapping",
+			ExportCSV,
+			ImportMapping{Date: "day", Quantity: "count", OptionalData: "note"},
+			"day,count,note,other\n2019-01-01,1,\"{\"\"a\"\":\"\"x\"\",\"\"b\"\":2}\",foo\n2019/01/02,2,,bar\n",
+			want,
+		},
+		{
+			"json document",
+			ExportJSON,
+			DefaultImportMapping(),
+			`{"graph":{"id":"testgraphid"},"pixels":[{"date":"20190101","quantity":"1","optionalData":{"a":"x","b":2}},{"date":"20190102","quantity":"2"}]}`,
+			want,
+		},
+		{
+			"json array with number",
+			ExportJSON,
+			DefaultImportMapping(),
+			`[{"date":"20190101","quantity":1,"optionalData":"{\"a\":\"x\",\"b\":2}"},{"date":20190102,"quantity":2}]`,
+			want,
+		},
+		{
+			"ndjson with definition",
+			ExportNDJSON,
+			DefaultImportMapping(),
+			`{"graph":{"id":"testgraphid"}}` + "\n" + `{"date":"20190101","quantity":"1","optionalData.a":"x","optionalData.b":2}` + "\n\n" + `{"date":"20190102","quantity":"2"}` + "\n",
+			want,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadPixels(strings.NewReader(tt.input), tt.format, tt.mapping)
+
+			if err != nil {
+				t.Fatalf("want nil, but %#v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("want %#v, but %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestReadPixels_RoundTrip(t *testing.T) {
+	pixels := []PixelRecord{
+		{"20190101", "1", `{"a":"x","b":2,"c":[1,2]}`},
+		{"20190102", "2.5", ""},
+	}
+
+	for _, format := range ExportFormats() {
+		for _, flatten := range []bool{false, true} {
+			buf := &bytes.Buffer{}
+			opts := ExportOptions{Format: format, FlattenOptionalData: flatten, Graph: &Graph{ID: graphID}}
+
+			if err := WritePixels(buf, pixels, opts); err != nil {
+				t.Fatalf("want nil, but %#v", err)
+			}
+
+			got, err := ReadPixels(buf, format, DefaultImportMapping())
+
+			if err != nil {
+				t.Fatalf("%s (flatten %t): want nil, but %#v", format, flatten, err)
+			}
+
+			if !reflect.DeepEqual(got, pixels) {
+				t.Fatalf("%s (flatten %t): want %#v, but %#v", format, flatten, pixels, got)
+			}
+		}
+	}
+}
+
+func TestReadGraphDefinition(t *testing.T) {
+	graph := Graph{ID: "reading", Name: "reading", Unit: "pages", Type: "int", Color: "sora", Timezone: "Asia/Tokyo"}
+	pixels := []PixelRecord{{"20190101", "1", ""}}
+
+	for _, format := range ExportFormats() {
+		for _, withDefinition := range []bool{true, false} {
+			opts := ExportOptions{Format: format}
+
+			if withDefinition {
+				opts.Graph = &graph
+			}
+
+			buf := &bytes.Buffer{}
+
+			if err := WritePixels(buf, pixels, opts); err != nil {
+				t.Fatalf("want nil, but %#v", err)
+			}
+
+			got, err := ReadGraphDefinition(buf, format)
+
+			if err != nil {
+				t.Fatalf("%s: want nil, but %#v", format, err)
+			}
+
+			if !reflect.DeepEqual(got, opts.Graph) {
+				t.Fatalf("%s: want %#v, but %#v", format, opts.Graph, got)
+			}
+		}
+	}
+
+	// JSON array has no definition
+	if got, err := ReadGraphDefinition(strings.NewReader(`[{"date":"20190101","quantity":"1"}]`), ExportJSON); got != nil || err != nil {
+		t.Fatalf("want nil, but %#v (%v)", got, err)
+	}
+}
+
+func TestReadPixels_InvalidRows(t *testing.T) {
+	input := "date,quantity\n20190101,1\nsomeday,2\n20190101,3\n20190104\n"
+
+	_, err := ReadPixels(strings.NewReader(input), ExportCSV, DefaultImportMapping())
+
+	var importErr *ImportError
+
+	if !errors.As(err, &importErr) {
+		t.Fatalf("want ImportError, but %#v", err)
+	}
+
+	var rows []int
+
+	for _, re := range importErr.Errors {
+		rows = append(rows, re.Row)
+	}
+
+	if !reflect.DeepEqual(rows, []int{2, 3, 4}) {
+		t.Fatalf("want rows [2 3 4], but %v: %s", rows, err)
+	}
+}
+
+func TestPixela_ValidatePixels(t *testing.T) {
+	c := NewTestClient(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: sucStatus, Body: ioutil.NopCloser(bytes.NewBuffer(graphDefResp)), Header: make(http.Header)}
+	})
+
+	pixela, err := New(username, token, debug, OptionHTTPClient(c))
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	// quantity type is checked in strict mode
+	pixels := []PixelRecord{
+		{"20190101", "1", ""},
+		{"20190102", "one", ""},
+		{"20190103", "3", "not json"},
+		{"20190104", "1.5", ""},
+	}
+
+	err = pixela.ValidatePixels(graphID, pixels)
+
+	var importErr *ImportError
+
+	if !errors.As(err, &importErr) || len(importErr.Errors) != 3 {
+		t.Fatalf("want ImportError of 3 rows, but %#v", err)
+	}
+
+	var validationErr *ValidationError
+
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("want ValidationError, but %#v", err)
+	}
+
+	if err := pixela.ValidatePixels(graphID, pixels[:1]); err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+}
+
+func TestPlanImport(t *testing.T) {
+	existing := []PixelRecord{
+		{"20190102", "2", `{"a":1}`},
+		{"20190103", "3", ""},
+	}
+	pixels := []PixelRecord{
+		{"20190103", "3", ""},
+		{"20190102", "5", ""},
+		{"20190101", "1", ""},
+	}
+
+	tests := []struct {
+		mode ImportMode
+		want []ImportChange
+	}{
+		{
+			ImportSkipExisting,
+			[]ImportChange{
+				{ImportCreate, nil, PixelRecord{"20190101", "1", ""}},
+				{ImportSkip, &existing[0], existing[0]},
+				{ImportSkip, &existing[1], existing[1]},
+			},
+		},
+		{
+			ImportOverwrite,
+			[]ImportChange{
+				{ImportCreate, nil, PixelRecord{"20190101", "1", ""}},
+				{ImportUpdate, &existing[0], PixelRecord{"20190102", "5", ""}},
+				{ImportSkip, &existing[1], existing[1]},
+			},
+		},
+		{
+			ImportAdd,
+			[]ImportChange{
+				{ImportCreate, nil, PixelRecord{"20190101", "1", ""}},
+				{ImportUpdate, &existing[0], PixelRecord{"20190102", "7", `{"a":1}`}},
+				{ImportUpdate, &existing[1], PixelRecord{"20190103", "6", ""}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			got, err := PlanImport(pixels, existing, tt.mode)
+
+			if err != nil {
+				t.Fatalf("want nil, but %#v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("want %+v, but %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAddPixelQuantity(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		want    string
+		wantErr bool
+	}{
+		{"1", "2", "3", false},
+		{"-1", "1", "0", false},
+		{"0.1", "0.2", "0.3", false},
+		{"1.5", "1.5", "3.0", false},
+		{"1", "0.25", "1.25", false},
+		{"1", "one", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := AddPixelQuantity(tt.a, tt.b)
+
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s + %s: want error %t, but %#v", tt.a, tt.b, tt.wantErr, err)
+		}
+
+		if got != tt.want {
+			t.Fatalf("%s + %s: want %s, but %s", tt.a, tt.b, tt.want, got)
+		}
+	}
+}
+
+func TestParseImportMode(t *testing.T) {
+	if got, err := ParseImportMode("add"); err != nil || got != ImportAdd {
+		t.Fatalf("want add, but %#v, %#v", got, err)
+	}
+
+	if _, err := ParseImportMode("merge"); err == nil {
+		t.Fatalf("want error, but nil")
+	}
+}
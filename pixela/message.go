@@ -0,0 +1,152 @@
+package pixela
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MessageCatalog builds validation error messages from rule set.
+// Keys of returned map are message keys such as `GraphID`, `Color` or `QuantityInt`.
+// `OptionalDataSchema` message is format string which takes JSON path and detail.
+type MessageCatalog func(rules ValidationRules) map[string]string
+
+// DefaultLocale is locale used when requested locale has no catalog
+const DefaultLocale = "en"
+
+var (
+	catalogsMu sync.RWMutex
+	catalogs   = map[string]MessageCatalog{
+		"en": englishMessages,
+		"ja": japaneseMessages,
+	}
+)
+
+// RegisterMessageCatalog registers (or replaces) message catalog of the locale
+func RegisterMessageCatalog(locale string, catalog MessageCatalog) {
+	catalogsMu.Lock()
+	defer catalogsMu.Unlock()
+
+	catalogs[normalizeLocale(locale)] = catalog
+}
+
+// Locales returns locales which have message catalog
+func Locales() []string {
+	catalogsMu.RLock()
+	defer catalogsMu.RUnlock()
+
+	locales := make([]string, 0, len(catalogs))
+
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+
+	return locales
+}
+
+// normalize locale such as `ja_JP.UTF-8` to `ja`
+func normalizeLocale(locale string) string {
+	locale = strings.ToLower(locale)
+
+	if i := strings.IndexAny(locale, "_-."); i >= 0 {
+		locale = locale[:i]
+	}
+
+	return locale
+}
+
+// build messages of the locale. missing keys fall back to default locale.
+func localizedMessages(locale string, rules ValidationRules) map[string]string {
+	catalogsMu.RLock()
+	catalog, ok := catalogs[normalizeLocale(locale)]
+	fallback := catalogs[DefaultLocale]
+	catalogsMu.RUnlock()
+
+	messages := fallback(rules)
+
+	if !ok {
+		return messages
+	}
+
+	for key, message := range catalog(rules) {
+		messages[key] = message
+	}
+
+	return messages
+}
+
+// format values such as "`a`, `b` or `c`"
+func joinValues(values []string, sep, lastSep string) string {
+	quoted := make([]string, 0, len(values))
+
+	for _, v := range values {
+		quoted = append(quoted, "`"+v+"`")
+	}
+
+	if len(quoted) <= 1 {
+		return strings.Join(quoted, "")
+	}
+
+	return strings.Join(quoted[:len(quoted)-1], sep) + lastSep + quoted[len(quoted)-1]
+}
+
+// quotedPattern is pattern of active validation rules (overridden rules may differ from built-in lengths)
+func quotedPattern(p string) string {
+	return "`" + p + "`"
+}
+
+// english (default) messages
+func englishMessages(rules ValidationRules) map[string]string {
+	allowed := func(values []string) string {
+		return joinValues(values, ", ", " or ")
+	}
+
+	return map[string]string{
+		"Username":            "`username` must match " + quotedPattern(rules.UsernamePattern) + " (such as `pixela-user`).",
+		"Token":               "`token` must match " + quotedPattern(rules.TokenPattern) + ".",
+		"AgreeTermsOfService": "`agreeTermsOfService` allows " + allowed(rules.YesNo) + ".",
+		"NotMinor":            "`notMinor` allows " + allowed(rules.YesNo) + ".",
+		"NewToken":            "`newToken` must match " + quotedPattern(rules.TokenPattern) + ".",
+		"GraphID":             "`graphID` must match " + quotedPattern(rules.GraphIDPattern) + " (such as `my-graph`).",
+		"UnitType":            "`unit` allows " + allowed(rules.NumTypes) + ".",
+		"Color":               "`color` allows " + allowed(rules.Colors) + ".",
+		"Date":                "`date` format is `yyyyMMdd`.",
+		"From":                "`from` format is `yyyyMMdd`.",
+		"To":                  "`to` format is `yyyyMMdd`.",
+		"Quantity":            "`quantity` allows value of int or float.",
+		"QuantityInt":         "`quantity` of int graph allows only integer value (such as `-1`, `0` or `10`).",
+		"WebhookType":         "`type` allows " + allowed(rules.WebhookTypes) + ".",
+		"OptionalData":        fmt.Sprintf("`optionalData` is under %dk JSON string.", rules.OptionalDataMaxBytes/1024),
+		"OptionalDataSchema":  "`optionalData` does not match schema at `%s`: %s.",
+		"SelfSufficient":      "`selfSufficient` allows " + allowed(rules.SelfSufficients) + ".",
+		"Timezone":            "`timezone` allows zone name of tz database (such as `Asia/Tokyo` or `UTC`).",
+	}
+}
+
+// japanese messages
+func japaneseMessages(rules ValidationRules) map[string]string {
+	allowed := func(values []string) string {
+		return joinValues(values, "、", "または")
+	}
+
+	return map[string]string{
+		"Username":            "`username` は " + quotedPattern(rules.UsernamePattern) + " に一致する値（`pixela-user` など）で指定してください。",
+		"Token":               "`token` は " + quotedPattern(rules.TokenPattern) + " に一致する値で指定してください。",
+		"AgreeTermsOfService": "`agreeTermsOfService` は " + allowed(rules.YesNo) + " を指定してください。",
+		"NotMinor":            "`notMinor` は " + allowed(rules.YesNo) + " を指定してください。",
+		"NewToken":            "`newToken` は " + quotedPattern(rules.TokenPattern) + " に一致する値で指定してください。",
+		"GraphID":             "`graphID` は " + quotedPattern(rules.GraphIDPattern) + " に一致する値（`my-graph` など）で指定してください。",
+		"UnitType":            "`unit` は " + allowed(rules.NumTypes) + " を指定してください。",
+		"Color":               "`color` は " + allowed(rules.Colors) + " を指定してください。",
+		"Date":                "`date` は `yyyyMMdd` 形式で指定してください。",
+		"From":                "`from` は `yyyyMMdd` 形式で指定してください。",
+		"To":                  "`to` は `yyyyMMdd` 形式で指定してください。",
+		"Quantity":            "`quantity` は整数または小数で指定してください。",
+		"QuantityInt":         "int のグラフの `quantity` は整数（`-1`、`0`、`10` など）で指定してください。",
+		"WebhookType":         "`type` は " + allowed(rules.WebhookTypes) + " を指定してください。",
+		"OptionalData":        fmt.Sprintf("`optionalData` は %dk 以下の JSON 文字列で指定してください。", rules.OptionalDataMaxBytes/1024),
+		"OptionalDataSchema":  "`optionalData` の `%s` がスキーマに一致しません: %s。",
+		"SelfSufficient":      "`selfSufficient` は " + allowed(rules.SelfSufficients) + " を指定してください。",
+		"Timezone":            "`timezone` は tz database のタイムゾーン名（`Asia/Tokyo`、`UTC` など）で指定してください。",
+	}
+}
@@ -0,0 +1,54 @@
+package pixela
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLocalizedMessages(t *testing.T) {
+	RegisterMessageCatalog("xx", func(rules ValidationRules) map[string]string {
+		return map[string]string{"GraphID": "custom graphID message"}
+	})
+
+	tests := []struct {
+		name   string
+		locale string
+		key    string
+		want   string
+	}{
+		{"English", "en", "GraphID", validationErrorMessages["GraphID"]},
+		{"Japanese", "ja", "Date", "`date` は `yyyyMMdd` 形式で指定してください。"},
+		{"Japanese with region and encoding", "ja_JP.UTF-8", "Date", "`date` は `yyyyMMdd` 形式で指定してください。"},
+		{"Unknown locale falls back to English", "fr", "Date", validationErrorMessages["Date"]},
+		{"Custom catalog", "xx", "GraphID", "custom graphID message"},
+		{"Missing key in custom catalog falls back to English", "xx", "Date", validationErrorMessages["Date"]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := localizedMessages(tt.locale, DefaultValidationRules())[tt.key]
+
+			if got != tt.want {
+				t.Fatalf("want %#v, but %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLocalizedMessages_rules(t *testing.T) {
+	rules := DefaultValidationRules()
+	rules.Colors = []string{"shibafu", "red"}
+	rules.GraphIDPattern = `^[a-z][a-z0-9-]{1,32}$`
+
+	for _, locale := range []string{"en", "ja"} {
+		messages := localizedMessages(locale, rules)
+
+		if got := messages["Color"]; !strings.Contains(got, "`red`") {
+			t.Fatalf("want message contains `red`, but %#v", got)
+		}
+
+		if got := messages["GraphID"]; !strings.Contains(got, "{1,32}") {
+			t.Fatalf("want message contains pattern, but %#v", got)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package pixela
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// max length of graph ID (`^[a-z][a-z0-9-]{1,16}$`)
+const maxGraphIDLength = 17
+
+// MigrateOptions is options for MigrateGraphType
+type MigrateOptions struct {
+	// TempID is graph ID used during migration (TempGraphID by default)
+	TempID string
+	// Convert converts quantity of each pixel (ConvertQuantity to new type when it is nil)
+	Convert QuantityConverter
+	// Concurrency is number of parallel requests copying pixels
+	Concurrency int
+	// Progress is called before each step
+	Progress func(step string)
+}
+
+// MigrateResult is result of MigrateGraphType
+type MigrateResult struct {
+	Graph  CreateGraphRequest `json:"graph"`
+	Pixels int                `json:"pixels"`
+	// WebhookHashes maps old webhook hash onto new webhook hash
+	WebhookHashes map[string]string `json:"webhookHashes,omitempty"`
+}
+
+// TempGraphID returns graph ID used during migration of the graph
+func TempGraphID(graphID string) string {
+	const suffix = "-tmp"
+
+	if len(graphID)+len(suffix) > maxGraphIDLength {
+		graphID = graphID[:maxGraphIDLength-len(suffix)]
+	}
+
+	return graphID + suffix
+}
+
+// MigrateGraphType is method for `graph migrate-type` subcommand.
+// pixe.la can not change type of graph, so the graph is cloned into temporary graph with new type,
+// deleted, cloned back from temporary graph and its webhooks are recreated (with new hashes).
+// pixels are kept in temporary graph when migration fails after the graph is deleted.
+func (pixela *Pixela) MigrateGraphType(graphID string, numType NumType, opts MigrateOptions) (MigrateResult, error) {
+	progress := opts.Progress
+
+	if progress == nil {
+		progress = func(string) {}
+	}
+
+	tempID := opts.TempID
+
+	if tempID == "" {
+		tempID = TempGraphID(graphID)
+	}
+
+	graph, err := pixela.LookupGraph(graphID)
+
+	if err != nil {
+		return MigrateResult{}, errors.Wrap(err, "`graph migrate-type`: can not get graph")
+	}
+
+	if NumType(graph.Type) == numType {
+		return MigrateResult{}, fmt.Errorf("`graph migrate-type`: type of graph `%s` is already %s", graphID, numType)
+	}
+
+	webhooks, err := pixela.graphWebhooks(graphID)
+
+	if err != nil {
+		return MigrateResult{}, errors.Wrap(err, "`graph migrate-type`: can not get webhooks")
+	}
+
+	// every pixel (not only pixels of last 365 days) is fetched before any graph is created
+	pixels, err := pixela.GetAllGraphPixels(graphID)
+
+	if err != nil {
+		return MigrateResult{}, errors.Wrap(err, "`graph migrate-type`: can not get pixels")
+	}
+
+	// 1. clone into temporary graph with new type (graph is not changed when it fails)
+	progress(fmt.Sprintf("clone `%s` into temporary graph `%s` (%s)", graphID, tempID, numType))
+
+	_, err = pixela.CloneGraph(graphID, tempID, CloneOptions{
+		Definition:  CreateGraphRequest{Type: numType},
+		Convert:     opts.Convert,
+		Concurrency: opts.Concurrency,
+		Pixels:      pixels,
+	})
+
+	if err != nil {
+		return MigrateResult{}, errors.Wrap(err, "`graph migrate-type`: graph is not changed")
+	}
+
+	// 2. delete webhooks and graph
+	progress(fmt.Sprintf("delete `%s`", graphID))
+
+	for _, webhook := range webhooks {
+		if _, err := pixela.DeleteWebhook(webhook.WebhookHash); err != nil && !isNotFound(err) {
+			return MigrateResult{}, errors.Wrapf(err, "`graph migrate-type`: can not delete webhook (pixels are copied into `%s`)", tempID)
+		}
+	}
+
+	if _, err := pixela.DeleteGraph(graphID); err != nil {
+		return MigrateResult{}, errors.Wrapf(err, "`graph migrate-type`: can not delete graph (pixels are copied into `%s`)", tempID)
+	}
+
+	// 3. clone back from temporary graph
+	progress(fmt.Sprintf("clone temporary graph `%s` into `%s`", tempID, graphID))
+
+	cloned, err := pixela.CloneGraph(tempID, graphID, CloneOptions{
+		Definition:  CreateGraphRequest{Name: graph.Name},
+		Convert:     func(quantity string) (string, error) { return quantity, nil },
+		Concurrency: opts.Concurrency,
+	})
+
+	if err != nil {
+		return MigrateResult{}, errors.Wrapf(err, "`graph migrate-type`: graph `%s` is deleted and pixels are kept in `%s`", graphID, tempID)
+	}
+
+	result := MigrateResult{Graph: cloned.Graph, Pixels: cloned.Pixels, WebhookHashes: map[string]string{}}
+
+	// 4. recreate webhooks
+	if len(webhooks) != 0 {
+		progress(fmt.Sprintf("recreate %d webhooks", len(webhooks)))
+	}
+
+	for _, webhook := range webhooks {
+		response, err := pixela.CreateWebhookWithRequest(CreateWebhookRequest{GraphID: graphID, Type: WebhookType(webhook.Type)})
+
+		if err != nil {
+			return result, errors.Wrapf(err, "`graph migrate-type`: can not recreate %s webhook", webhook.Type)
+		}
+
+		result.WebhookHashes[webhook.WebhookHash] = response.WebhookHash
+	}
+
+	// 5. delete temporary graph
+	progress(fmt.Sprintf("delete temporary graph `%s`", tempID))
+
+	if _, err := pixela.DeleteGraph(tempID); err != nil {
+		return result, errors.Wrapf(err, "`graph migrate-type`: can not delete temporary graph `%s`", tempID)
+	}
+
+	return result, nil
+}
+
+func isNotFound(err error) bool {
+	var apiErr *APIError
+
+	return errors.As(err, &apiErr) && apiErr.NotFound()
+}
@@ -0,0 +1,249 @@
+package pixela
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// in-memory pixe.la for migration tests
+type fakePixela struct {
+	graphs   map[string]Graph
+	pixels   map[string][]PixelRecord
+	webhooks []Webhook
+	failOn   string
+	steps    []string
+	hashSeq  int
+}
+
+func (f *fakePixela) roundTrip(req *http.Request) *http.Response {
+	var body []byte
+
+	if req.Body != nil {
+		body, _ = ioutil.ReadAll(req.Body)
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/v1/users/"+username)
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	key := req.Method + " " + path
+
+	respond := func(status int, v interface{}) *http.Response {
+		out, _ := json.Marshal(v)
+		return &http.Response{StatusCode: status, Body: ioutil.NopCloser(bytes.NewBuffer(out)), Header: make(http.Header)}
+	}
+	success := NoneGetResponseBody{Message: "Success.", IsSuccess: true}
+
+	if key == f.failOn {
+		return respond(errStatus, NoneGetResponseBody{Message: "errorMessage"})
+	}
+
+	if req.Method != http.MethodGet {
+		f.steps = append(f.steps, key)
+	}
+
+	switch {
+	case key == "GET /graphs":
+		definitions := GraphDefinitions{Graphs: []Graph{}}
+
+		for _, g := range f.graphs {
+			definitions.Graphs = append(definitions.Graphs, g)
+		}
+
+		return respond(sucStatus, definitions)
+	case key == "POST /graphs":
+		var pl CreateGraphPayload
+		json.Unmarshal(body, &pl)
+		f.graphs[pl.ID] = Graph{ID: pl.ID, Name: pl.Name, Unit: pl.Unit, Type: pl.NumType, Color: pl.Color, Timezone: pl.Timezone, SelfSufficient: pl.SelfSufficient}
+
+		return respond(sucStatus, success)
+	case req.Method == http.MethodDelete && parts[0] == "graphs":
+		delete(f.graphs, parts[1])
+		delete(f.pixels, parts[1])
+
+		return respond(sucStatus, success)
+	case req.Method == http.MethodGet && len(parts) == 3 && parts[2] == "stats":
+		return respond(sucStatus, map[string]int{"totalPixelsCount": len(f.pixels[parts[1]])})
+	case req.Method == http.MethodGet && len(parts) == 3 && parts[2] == "pixels":
+		from, to := req.URL.Query().Get("from"), req.URL.Query().Get("to")
+		pixels := []PixelRecord{}
+
+		for _, p := range f.pixels[parts[1]] {
+			if (from == "" || from <= p.Date) && (to == "" || p.Date <= to) {
+				pixels = append(pixels, p)
+			}
+		}
+
+		return respond(sucStatus, map[string][]PixelRecord{"pixels": pixels})
+	case req.Method == http.MethodPost && parts[0] == "graphs":
+		var p PixelRecord
+		json.Unmarshal(body, &p)
+		f.pixels[parts[1]] = append(f.pixels[parts[1]], p)
+
+		return respond(sucStatus, success)
+	case key == "GET /webhooks":
+		return respond(sucStatus, WebhookDefinitions{Webhooks: f.webhooks})
+	case key == "POST /webhooks":
+		var pl CreateWebhookPayload
+		json.Unmarshal(body, &pl)
+		f.hashSeq++
+		hash := fmt.Sprintf("newhash%d", f.hashSeq)
+		f.webhooks = append(f.webhooks, Webhook{hash, pl.GraphID, pl.Type})
+
+		return respond(sucStatus, NoneGetResponseBody{Message: "Success.", IsSuccess: true, WebhookHash: hash})
+	case req.Method == http.MethodDelete && parts[0] == "webhooks":
+		for i, w := range f.webhooks {
+			if w.WebhookHash == parts[1] {
+				f.webhooks = append(f.webhooks[:i], f.webhooks[i+1:]...)
+				return respond(sucStatus, success)
+			}
+		}
+
+		return respond(http.StatusNotFound, NoneGetResponseBody{Message: "Specified webhook not found."})
+	}
+
+	return respond(http.StatusNotFound, NoneGetResponseBody{Message: "unexpected request " + key})
+}
+
+func newFakePixela() *fakePixela {
+	return &fakePixela{
+		graphs: map[string]Graph{
+			"reading": {ID: "reading", Name: "reading", Unit: "pages", Type: "int", Color: "shibafu", Timezone: "Asia/Tokyo"},
+		},
+		pixels: map[string][]PixelRecord{
+			"reading": {{"20190101", "10", `{"book":"a"}`}, {"20190102", "20", ""}},
+		},
+		webhooks: []Webhook{{"hash1", "reading", "increment"}, {"hash2", "other", "increment"}},
+	}
+}
+
+func TestPixela_MigrateGraphType(t *testing.T) {
+	fake := newFakePixela()
+	pixela, err := New(username, token, debug, OptionHTTPClient(NewTestClient(fake.roundTrip)))
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	var progress []string
+
+	// pixel older than one year survives migration
+	fake.pixels["reading"] = append(fake.pixels["reading"], PixelRecord{"20150101", "5", ""})
+
+	result, err := pixela.MigrateGraphType("reading", NumTypeFloat, MigrateOptions{Progress: func(step string) {
+		progress = append(progress, step)
+	}})
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	wantGraph := Graph{ID: "reading", Name: "reading", Unit: "pages", Type: "float", Color: "shibafu", Timezone: "Asia/Tokyo"}
+
+	if !reflect.DeepEqual(fake.graphs, map[string]Graph{"reading": wantGraph}) {
+		t.Fatalf("want %#v, but %#v", wantGraph, fake.graphs)
+	}
+
+	wantPixels := []PixelRecord{{"20150101", "5.0", ""}, {"20190101", "10.0", `{"book":"a"}`}, {"20190102", "20.0", ""}}
+	gotPixels := fake.pixels["reading"]
+	sort.Slice(gotPixels, func(i, j int) bool { return gotPixels[i].Date < gotPixels[j].Date })
+
+	if !reflect.DeepEqual(gotPixels, wantPixels) {
+		t.Fatalf("want %#v, but %#v", wantPixels, gotPixels)
+	}
+
+	wantWebhooks := []Webhook{{"hash2", "other", "increment"}, {"newhash1", "reading", "increment"}}
+
+	if !reflect.DeepEqual(fake.webhooks, wantWebhooks) {
+		t.Fatalf("want %#v, but %#v", wantWebhooks, fake.webhooks)
+	}
+
+	if !reflect.DeepEqual(result.WebhookHashes, map[string]string{"hash1": "newhash1"}) || result.Pixels != 3 {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+
+	if len(progress) != 5 {
+		t.Fatalf("want 5 steps, but %v", progress)
+	}
+}
+
+func TestPixela_MigrateGraphTypeFailure(t *testing.T) {
+	tests := []struct {
+		name       string
+		failOn     string
+		wantGraphs []string
+	}{
+		{"clone into temporary graph", "POST /graphs/reading-tmp", []string{"reading"}},
+		{"delete graph", "DELETE /graphs/reading", []string{"reading", "reading-tmp"}},
+		{"clone back", "POST /graphs/reading", []string{"reading-tmp"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakePixela()
+			fake.failOn = tt.failOn
+
+			pixela, err := New(username, token, debug, OptionHTTPClient(NewTestClient(fake.roundTrip)))
+
+			if err != nil {
+				t.Fatalf("want nil, but %#v", err)
+			}
+
+			if _, err := pixela.MigrateGraphType("reading", NumTypeFloat, MigrateOptions{}); err == nil {
+				t.Fatalf("want error, but nil")
+			}
+
+			var graphs []string
+
+			for id := range fake.graphs {
+				graphs = append(graphs, id)
+			}
+
+			sort.Strings(graphs)
+
+			if !reflect.DeepEqual(graphs, tt.wantGraphs) {
+				t.Fatalf("want %v, but %v (%v)", tt.wantGraphs, graphs, fake.steps)
+			}
+
+			// pixels are kept in one of graphs
+			for _, id := range graphs {
+				if len(fake.pixels[id]) == 2 {
+					return
+				}
+			}
+
+			t.Fatalf("pixels are lost: %#v", fake.pixels)
+		})
+	}
+}
+
+func TestPixela_MigrateGraphTypeSameType(t *testing.T) {
+	fake := newFakePixela()
+	pixela, _ := New(username, token, debug, OptionHTTPClient(NewTestClient(fake.roundTrip)))
+
+	if _, err := pixela.MigrateGraphType("reading", NumTypeInt, MigrateOptions{}); err == nil {
+		t.Fatalf("want error, but nil")
+	}
+
+	if len(fake.steps) != 0 {
+		t.Fatalf("want no change, but %v", fake.steps)
+	}
+}
+
+func TestTempGraphID(t *testing.T) {
+	tests := map[string]string{
+		"reading":           "reading-tmp",
+		"abcdefghijklmnopq": "abcdefghijklm-tmp",
+	}
+
+	for graphID, want := range tests {
+		if got := TempGraphID(graphID); got != want {
+			t.Fatalf("want %s, but %s", want, got)
+		}
+	}
+}
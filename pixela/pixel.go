@@ -15,6 +15,14 @@ type CreatePixelPayload struct {
 	OptionalData string `json:"optionalData,omitempty"`
 }
 
+// PixelRequest is request for PostPixelWithRequest and UpdatePixelWithRequest
+type PixelRequest struct {
+	GraphID      string
+	Date         string
+	Quantity     string
+	OptionalData string
+}
+
 // GetPixelResponseBody is response for `pixel get` subcommand
 type GetPixelResponseBody struct {
 	Quantity     string `json:"quantity"`
@@ -23,12 +31,22 @@ type GetPixelResponseBody struct {
 
 // PostPixel is method for `pixel post` subcommand
 func (pixela *Pixela) PostPixel(graphID, date, quantity, optionalData string) (NoneGetResponseBody, error) {
-	// argument validation
-	vf := validateField{
+	return pixela.PostPixelWithRequest(PixelRequest{
 		GraphID:      graphID,
 		Date:         date,
 		Quantity:     quantity,
 		OptionalData: optionalData,
+	})
+}
+
+// PostPixelWithRequest is method for `pixel post` subcommand
+func (pixela *Pixela) PostPixelWithRequest(req PixelRequest) (NoneGetResponseBody, error) {
+	// argument validation
+	vf := validateField{
+		GraphID:      req.GraphID,
+		Date:         req.Date,
+		Quantity:     req.Quantity,
+		OptionalData: req.OptionalData,
 	}
 
 	err := pixela.Validator.Validate(vf)
@@ -37,15 +55,28 @@ func (pixela *Pixela) PostPixel(graphID, date, quantity, optionalData string) (N
 		return NoneGetResponseBody{}, errors.Wrap(err, "`pixel post`: wrong arguments")
 	}
 
+	err = pixela.Validator.ValidateOptionalDataSchema(pixela.optionalDataSchema(req.GraphID), req.OptionalData)
+
+	if err != nil {
+		return NoneGetResponseBody{}, errors.Wrap(err, "`pixel post`: wrong arguments")
+	}
+
+	// graph type is requested after local validation
+	err = pixela.validateQuantityType(req.GraphID, req.Quantity)
+
+	if err != nil {
+		return NoneGetResponseBody{}, errors.Wrap(err, "`pixel post`: wrong arguments")
+	}
+
 	// create payload
 	pl := CreatePixelPayload{
-		Date:     date,
-		Quantity: quantity,
+		Date:     req.Date,
+		Quantity: req.Quantity,
 	}
 
 	// set optionalData
-	if len(optionalData) != 0 {
-		pl.OptionalData = optionalData
+	if len(req.OptionalData) != 0 {
+		pl.OptionalData = req.OptionalData
 	}
 
 	plJSON, err := json.Marshal(pl)
@@ -57,7 +88,7 @@ func (pixela *Pixela) PostPixel(graphID, date, quantity, optionalData string) (N
 	// build request url
 	// TODO: rewrite by url package
 	requestURL := fmt.Sprintf(
-		"%s/v1/users/%s/graphs/%s", baseURL, pixela.Username, graphID)
+		"%s/v1/users/%s/graphs/%s", baseURL, pixela.Username, req.GraphID)
 
 	// do request
 	responseBody, err := pixela.post(requestURL, bytes.NewBuffer(plJSON))
@@ -114,12 +145,22 @@ func (pixela *Pixela) GetPixel(graphID string, date string) (GetPixelResponseBod
 
 // UpdatePixel is method for `pixel update` subcommand
 func (pixela *Pixela) UpdatePixel(graphID, date, quantity, optionalData string) (NoneGetResponseBody, error) {
-	// argument validation
-	vf := validateField{
+	return pixela.UpdatePixelWithRequest(PixelRequest{
 		GraphID:      graphID,
 		Date:         date,
 		Quantity:     quantity,
 		OptionalData: optionalData,
+	})
+}
+
+// UpdatePixelWithRequest is method for `pixel update` subcommand
+func (pixela *Pixela) UpdatePixelWithRequest(req PixelRequest) (NoneGetResponseBody, error) {
+	// argument validation
+	vf := validateField{
+		GraphID:      req.GraphID,
+		Date:         req.Date,
+		Quantity:     req.Quantity,
+		OptionalData: req.OptionalData,
 	}
 
 	err := pixela.Validator.Validate(vf)
@@ -128,14 +169,27 @@ func (pixela *Pixela) UpdatePixel(graphID, date, quantity, optionalData string)
 		return NoneGetResponseBody{}, errors.Wrap(err, "`pixel update`: wrong arguments")
 	}
 
+	err = pixela.Validator.ValidateOptionalDataSchema(pixela.optionalDataSchema(req.GraphID), req.OptionalData)
+
+	if err != nil {
+		return NoneGetResponseBody{}, errors.Wrap(err, "`pixel update`: wrong arguments")
+	}
+
+	// graph type is requested after local validation
+	err = pixela.validateQuantityType(req.GraphID, req.Quantity)
+
+	if err != nil {
+		return NoneGetResponseBody{}, errors.Wrap(err, "`pixel update`: wrong arguments")
+	}
+
 	// create payload
 	pl := CreatePixelPayload{
-		Quantity: quantity,
+		Quantity: req.Quantity,
 	}
 
 	// set optionalData
-	if len(optionalData) != 0 {
-		pl.OptionalData = optionalData
+	if len(req.OptionalData) != 0 {
+		pl.OptionalData = req.OptionalData
 	}
 
 	plJSON, err := json.Marshal(pl)
@@ -147,7 +201,7 @@ func (pixela *Pixela) UpdatePixel(graphID, date, quantity, optionalData string)
 	// build request url
 	// TODO: rewrite by url package
 	requestURL := fmt.Sprintf(
-		"%s/v1/users/%s/graphs/%s/%s", baseURL, pixela.Username, graphID, date)
+		"%s/v1/users/%s/graphs/%s/%s", baseURL, pixela.Username, req.GraphID, req.Date)
 
 	// do request
 	responseBody, err := pixela.put(requestURL, bytes.NewBuffer(plJSON))
@@ -271,3 +325,24 @@ func (pixela *Pixela) DeletePixel(graphID, date string) (NoneGetResponseBody, er
 
 	return deleteResponseBody, nil
 }
+
+// validate quantity against target graph type when OptionQuantityTypeCheck is enabled (strict mode by default)
+func (pixela *Pixela) validateQuantityType(graphID, quantity string) error {
+	enabled := pixela.Validator.Mode() == ValidationStrict
+
+	if pixela.quantityTypeCheck != nil {
+		enabled = *pixela.quantityTypeCheck
+	}
+
+	if !enabled || pixela.Validator.Mode() == ValidationOff {
+		return nil
+	}
+
+	graph, err := pixela.LookupGraph(graphID)
+
+	if err != nil {
+		return errors.Wrap(err, "can not resolve graph type")
+	}
+
+	return pixela.Validator.ValidateQuantityType(graph.Type, quantity)
+}
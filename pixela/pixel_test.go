@@ -1,7 +1,10 @@
 package pixela
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"testing"
 )
 
@@ -113,3 +116,153 @@ func TestPixela_UpdatePixel(t *testing.T) {
 
 	subCommandTestHelper(t, pixelUpdate, tests, pixelUpdateURL)
 }
+
+func TestPixela_PostPixelQuantityTypeCheck(t *testing.T) {
+	graphDefURL := fmt.Sprintf("%s/v1/users/%s/graphs", baseURL, username)
+	pixelCreateURL := fmt.Sprintf("%s/v1/users/%s/graphs/%s", baseURL, username, graphID)
+
+	ivQuantityErr := newCommandError(pixelPost, "wrong arguments: "+validationErrorMessages["QuantityInt"])
+	notFoundErr := newCommandError(pixelPost, "wrong arguments: can not resolve graph type: GET request failed: graph `unknown` is not found")
+
+	tests := []struct {
+		name     string
+		graphID  string
+		quantity string
+		wantErr  error
+	}{
+		{"int quantity for int graph", graphID, "-3", nil},
+		{"float quantity for int graph", graphID, "1.5", ivQuantityErr},
+		{"unknown graph", "unknown", "1", notFoundErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definitionRequests := 0
+
+			c := NewTestClient(func(req *http.Request) *http.Response {
+				body := scResp
+
+				switch req.URL.String() {
+				case graphDefURL:
+					definitionRequests++
+					body = graphDefResp
+				case pixelCreateURL:
+				default:
+					t.Fatalf("unexpected request %#v", req.URL.String())
+				}
+
+				return &http.Response{
+					StatusCode: sucStatus,
+					Body:       ioutil.NopCloser(bytes.NewBuffer(body)),
+					Header:     make(http.Header),
+				}
+			})
+
+			pixela, err := New(username, token, debug, OptionHTTPClient(c), OptionQuantityTypeCheck(true))
+
+			if err != nil {
+				t.Fatalf("got error when http client created %#v", err)
+			}
+
+			// second call uses cached graph definitions
+			for i := 0; i < 2; i++ {
+				_, err = pixela.PostPixel(tt.graphID, dateStr, tt.quantity, "")
+
+				if tt.wantErr == nil && err != nil {
+					t.Fatalf("want nil, but %#v", err)
+				}
+
+				if tt.wantErr != nil && (err == nil || err.Error() != tt.wantErr.Error()) {
+					t.Fatalf("want %#v, but %#v", tt.wantErr, err)
+				}
+			}
+
+			if definitionRequests != 1 {
+				t.Fatalf("want 1 graph definition request, but %d", definitionRequests)
+			}
+		})
+	}
+}
+
+func TestPixela_QuantityTypeCheckDefault(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{"strict", nil, true},
+		{"lenient", []Option{OptionValidationMode(ValidationLenient)}, false},
+		{"strict without check", []Option{OptionQuantityTypeCheck(false)}, false},
+		{"lenient with check", []Option{OptionValidationMode(ValidationLenient), OptionQuantityTypeCheck(true)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewTestClient(func(req *http.Request) *http.Response {
+				body := scResp
+
+				if req.Method == http.MethodGet {
+					body = graphDefResp
+				}
+
+				return &http.Response{StatusCode: sucStatus, Body: ioutil.NopCloser(bytes.NewBuffer(body)), Header: make(http.Header)}
+			})
+
+			pixela, err := New(username, token, debug, append([]Option{OptionHTTPClient(c)}, tt.opts...)...)
+
+			if err != nil {
+				t.Fatalf("got error when http client created %#v", err)
+			}
+
+			// float quantity for int graph
+			if _, err := pixela.PostPixel(graphID, dateStr, "1.5", ""); (err != nil) != tt.wantErr {
+				t.Fatalf("want error %t, but %#v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPixela_LookupGraphAfterCreateGraph(t *testing.T) {
+	graphDefURL := fmt.Sprintf("%s/v1/users/%s/graphs", baseURL, username)
+	created := false
+
+	c := NewTestClient(func(req *http.Request) *http.Response {
+		body := scResp
+
+		switch {
+		case req.URL.String() != graphDefURL:
+			t.Fatalf("unexpected request %#v", req.URL.String())
+		case req.Method == http.MethodPost:
+			created = true
+		case !created:
+			body = []byte(`{"graphs":[]}`)
+		default:
+			body = graphDefResp
+		}
+
+		return &http.Response{
+			StatusCode: sucStatus,
+			Body:       ioutil.NopCloser(bytes.NewBuffer(body)),
+			Header:     make(http.Header),
+		}
+	})
+
+	pixela, err := New(username, token, debug, OptionHTTPClient(c))
+
+	if err != nil {
+		t.Fatalf("got error when http client created %#v", err)
+	}
+
+	if _, err := pixela.LookupGraph(graphID); err == nil {
+		t.Fatalf("want not found error before graph is created")
+	}
+
+	if _, err := pixela.CreateGraph(graphID, graphName, graphUnit, numType, validColor, "", ""); err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	// cache is invalidated by CreateGraph
+	if _, err := pixela.LookupGraph(graphID); err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+}
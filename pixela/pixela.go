@@ -3,9 +3,9 @@ package pixela
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -21,6 +21,14 @@ type Pixela struct {
 	Validator  Validator
 	Token      string
 	Debug      bool
+
+	validationRules   ValidationRules
+	validationMode    ValidationMode
+	quantityTypeCheck *bool
+	locale            string
+	graphCache        *graphDefinitionCache
+	schemaMu          sync.RWMutex
+	schemas           map[string]*OptionalDataSchema
 }
 
 // Option is customize Pixela properties function
@@ -33,6 +41,42 @@ func OptionHTTPClient(c *http.Client) Option {
 	}
 }
 
+// OptionValidationRules - provide a custom validation rule set instead of built-in one
+func OptionValidationRules(rules ValidationRules) Option {
+	return func(pixela *Pixela) {
+		pixela.validationRules = rules
+	}
+}
+
+// OptionValidationMode - provide validation strictness (default is ValidationStrict)
+func OptionValidationMode(mode ValidationMode) Option {
+	return func(pixela *Pixela) {
+		pixela.validationMode = mode
+	}
+}
+
+// OptionLocale - provide locale of validation error messages (such as `en` or `ja`)
+func OptionLocale(locale string) Option {
+	return func(pixela *Pixela) {
+		pixela.locale = locale
+	}
+}
+
+// OptionQuantityTypeCheck - check quantity against target graph's type (int or float) before sending
+// (default is enabled in ValidationStrict mode). Graph type is resolved from graph definitions and cached on the instance.
+func OptionQuantityTypeCheck(enabled bool) Option {
+	return func(pixela *Pixela) {
+		pixela.quantityTypeCheck = &enabled
+	}
+}
+
+// OptionOptionalDataSchema - provide JSON Schema which optionalData of the graph must match
+func OptionOptionalDataSchema(graphID string, schema *OptionalDataSchema) Option {
+	return func(pixela *Pixela) {
+		pixela.RegisterOptionalDataSchema(graphID, schema)
+	}
+}
+
 // NoneGetResponseBody - pixe.la response body that post, put and delete method requested
 type NoneGetResponseBody struct {
 	Message     string `json:"message"`
@@ -42,36 +86,72 @@ type NoneGetResponseBody struct {
 
 // New creates pixe.la api client instance
 func New(username, token string, debug bool, opts ...Option) (*Pixela, error) {
+	// create instance
+	pixela := &Pixela{
+		HTTPClient: &http.Client{
+			Timeout: time.Duration(10) * time.Second,
+		},
+		URL:             baseURL,
+		Username:        username,
+		Token:           token,
+		Debug:           debug,
+		validationRules: DefaultValidationRules(),
+		validationMode:  ValidationStrict,
+		locale:          DefaultLocale,
+		graphCache:      &graphDefinitionCache{},
+	}
+
+	for _, opt := range opts {
+		opt(pixela)
+	}
+
+	validate, err := newValidatorWithRules(pixela.validationRules, pixela.validationMode, pixela.locale)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "initialization error")
+	}
+
+	pixela.Validator = validate
+
 	// validate arguments
 	vf := newInstanceValidateField{
 		Username: username,
 		Token:    token,
 	}
 
-	validate := newValidator()
-	err := validate.Validate(vf)
+	err = validate.Validate(vf)
 
 	if err != nil {
 		return nil, errors.Wrap(err, "initialization error")
 	}
 
-	// create instance
-	pixela := &Pixela{
-		HTTPClient: &http.Client{
-			Timeout: time.Duration(10) * time.Second,
-		},
-		URL:       baseURL,
-		Username:  username,
-		Token:     token,
-		Validator: validate,
-		Debug:     debug,
+	return pixela, nil
+}
+
+// RegisterOptionalDataSchema registers JSON Schema which optionalData of the graph must match.
+// nil schema removes registered one.
+func (pixela *Pixela) RegisterOptionalDataSchema(graphID string, schema *OptionalDataSchema) {
+	pixela.schemaMu.Lock()
+	defer pixela.schemaMu.Unlock()
+
+	if schema == nil {
+		delete(pixela.schemas, graphID)
+		return
 	}
 
-	for _, opt := range opts {
-		opt(pixela)
+	if pixela.schemas == nil {
+		pixela.schemas = make(map[string]*OptionalDataSchema)
 	}
 
-	return pixela, nil
+	pixela.schemas[graphID] = schema
+}
+
+// registered JSON Schema of the graph
+func (pixela *Pixela) optionalDataSchema(graphID string) *OptionalDataSchema {
+	pixela.schemaMu.RLock()
+	defer pixela.schemaMu.RUnlock()
+
+	return pixela.schemas[graphID]
 }
 
 // post request
@@ -114,12 +194,16 @@ func (pixela *Pixela) post(url string, payload *bytes.Buffer) ([]byte, error) {
 	err = json.Unmarshal(responseBodyJSON, &responseBody)
 
 	if err != nil {
+		if response.StatusCode != http.StatusOK {
+			return nil, newAPIError("post", response.StatusCode, "")
+		}
+
 		return nil, errors.Wrap(err, "post response body parse failed")
 	}
 
 	// check response body if request success
 	if response.StatusCode != http.StatusOK && !responseBody.IsSuccess {
-		return nil, fmt.Errorf("post request failed: %s", responseBody.Message)
+		return nil, newAPIError("post", response.StatusCode, responseBody.Message)
 	}
 
 	return responseBodyJSON, nil
@@ -154,13 +238,11 @@ func (pixela *Pixela) get(url string) ([]byte, error) {
 
 	if response.StatusCode != http.StatusOK {
 		responseBody := NoneGetResponseBody{}
-		err = json.Unmarshal(responseBodyJSON, &responseBody)
 
-		if err != nil {
-			return nil, errors.Wrap(err, "get response parse failed")
-		}
+		// message is empty when response is not JSON
+		_ = json.Unmarshal(responseBodyJSON, &responseBody)
 
-		return nil, fmt.Errorf("get request failed: %s", responseBody.Message)
+		return nil, newAPIError("get", response.StatusCode, responseBody.Message)
 	}
 
 	return responseBodyJSON, nil
@@ -206,12 +288,16 @@ func (pixela *Pixela) put(url string, payload *bytes.Buffer) ([]byte, error) {
 	err = json.Unmarshal(responseBodyJSON, &responseBody)
 
 	if err != nil {
+		if response.StatusCode != http.StatusOK {
+			return nil, newAPIError("put", response.StatusCode, "")
+		}
+
 		return nil, errors.Wrap(err, "put response body parse failed")
 	}
 
 	// check response body if request success
 	if response.StatusCode != http.StatusOK && !responseBody.IsSuccess {
-		return nil, fmt.Errorf("put request failed: %s", responseBody.Message)
+		return nil, newAPIError("put", response.StatusCode, responseBody.Message)
 	}
 
 	return responseBodyJSON, nil
@@ -248,12 +334,16 @@ func (pixela *Pixela) delete(url string) ([]byte, error) {
 	err = json.Unmarshal(responseBodyJSON, &responseBody)
 
 	if err != nil {
+		if response.StatusCode != http.StatusOK {
+			return nil, newAPIError("delete", response.StatusCode, "")
+		}
+
 		return nil, errors.Wrap(err, "delete response body parse failed")
 	}
 
 	// check response body if request success
 	if response.StatusCode != http.StatusOK && !responseBody.IsSuccess {
-		return nil, fmt.Errorf("delete request failed: %s", responseBody.Message)
+		return nil, newAPIError("delete", response.StatusCode, responseBody.Message)
 	}
 
 	return responseBodyJSON, nil
@@ -0,0 +1,99 @@
+package pixela
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// RecordPixels records pixels into graph (such as measured by timer or counted from log).
+// existing pixels of the dates are fetched by requests of 365 days at most and changes are planned by mode.
+// with ImportAdd, optionalData objects are merged (see MergeOptionalData).
+// done is called after each change is applied, so callers can resume partially recorded pixels.
+func (pixela *Pixela) RecordPixels(graphID string, pixels []PixelRecord, mode ImportMode, done func(ImportChange) error) ([]ImportChange, error) {
+	if len(pixels) == 0 {
+		return nil, nil
+	}
+
+	dates := make([]string, 0, len(pixels))
+
+	for _, p := range pixels {
+		dates = append(dates, p.Date)
+	}
+
+	existing, err := pixela.GetGraphPixelsOfDates(graphID, dates)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "can not get existing pixels")
+	}
+
+	changes, err := PlanImport(pixels, existing, mode)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == ImportAdd {
+		for i, change := range changes {
+			if change.Action != ImportUpdate {
+				continue
+			}
+
+			for _, p := range pixels {
+				if p.Date == change.New.Date {
+					if changes[i].New.OptionalData, err = MergeOptionalData(change.Old.OptionalData, p.OptionalData); err != nil {
+						return nil, errors.Wrapf(err, "can not merge optionalData of %s", p.Date)
+					}
+				}
+			}
+		}
+	}
+
+	if err := pixela.ApplyImport(graphID, changes, done); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// MergeOptionalData merges optionalData JSON objects.
+// fields of added object overwrite existing fields except arrays which are concatenated.
+// added optionalData replaces existing one when either of them is not object.
+func MergeOptionalData(existing, added string) (string, error) {
+	if added == "" {
+		return existing, nil
+	}
+
+	var old, add map[string]json.RawMessage
+
+	if json.Unmarshal([]byte(existing), &old) != nil || old == nil || json.Unmarshal([]byte(added), &add) != nil || add == nil {
+		return added, nil
+	}
+
+	for key, value := range add {
+		var oldArray, addArray []json.RawMessage
+
+		if json.Unmarshal(old[key], &oldArray) == nil && json.Unmarshal(value, &addArray) == nil && oldArray != nil && addArray != nil {
+			merged, err := json.Marshal(append(oldArray, addArray...))
+
+			if err != nil {
+				return "", err
+			}
+
+			value = merged
+		}
+
+		old[key] = value
+	}
+
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(old); err != nil {
+		return "", err
+	}
+
+	return string(bytes.TrimSpace(buf.Bytes())), nil
+}
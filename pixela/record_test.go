@@ -0,0 +1,124 @@
+package pixela
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPixela_RecordPixels(t *testing.T) {
+	var requests []string
+
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		if req.Method == http.MethodGet && strings.HasSuffix(req.URL.Path, "/graphs") {
+			return &http.Response{StatusCode: sucStatus, Body: ioutil.NopCloser(bytes.NewBufferString(`{"graphs":[{"id":"testgraphid","type":"int"}]}`)), Header: make(http.Header)}
+		}
+
+		if req.Method == http.MethodGet {
+			if got := req.URL.RawQuery; got != "from=20190101&to=20190102&withBody=true" {
+				t.Fatalf("unexpected query: %s", got)
+			}
+
+			return &http.Response{StatusCode: sucStatus, Body: ioutil.NopCloser(bytes.NewBufferString(
+				`{"pixels":[{"date":"20190101","quantity":"30","optionalData":"{\"notes\":[\"a\"],\"host\":\"x\"}"}]}`)), Header: make(http.Header)}
+		}
+
+		body, _ := ioutil.ReadAll(req.Body)
+		requests = append(requests, req.Method+" "+req.URL.Path+" "+string(body))
+
+		return &http.Response{StatusCode: sucStatus, Body: ioutil.NopCloser(bytes.NewBufferString(`{"message":"Success.","isSuccess":true}`)), Header: make(http.Header)}
+	})
+
+	pixela, err := New(username, token, debug, OptionHTTPClient(client))
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	_, err = pixela.RecordPixels(graphID, []PixelRecord{
+		{"20190102", "45", `{"notes":["c"]}`},
+		{"20190101", "15", `{"notes":["b"]}`},
+	}, ImportAdd, nil)
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	want := []string{
+		`PUT /v1/users/testuser/graphs/testgraphid/20190101 {"date":"","quantity":"45","optionalData":"{\"host\":\"x\",\"notes\":[\"a\",\"b\"]}"}`,
+		`POST /v1/users/testuser/graphs/testgraphid {"date":"20190102","quantity":"45","optionalData":"{\"notes\":[\"c\"]}"}`,
+	}
+
+	if !reflect.DeepEqual(requests, want) {
+		t.Fatalf("want\n%s\nbut\n%s", strings.Join(want, "\n"), strings.Join(requests, "\n"))
+	}
+}
+
+func TestPixela_RecordPixelsOverYears(t *testing.T) {
+	// existing pixels of dates over years (such as git backfill) are fetched by windows
+	var windows []string
+	var requests []string
+
+	pixels := pixelsResponder(t, []PixelRecord{{Date: "20150101", Quantity: "1"}, {Date: "20190101", Quantity: "2"}}, &windows)
+
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		if req.Method == http.MethodGet {
+			return pixels(req)
+		}
+
+		requests = append(requests, req.Method+" "+req.URL.Path)
+
+		return &http.Response{StatusCode: sucStatus, Body: ioutil.NopCloser(bytes.NewBufferString(`{"message":"Success.","isSuccess":true}`)), Header: make(http.Header)}
+	})
+
+	pixela, err := New(username, token, debug, OptionHTTPClient(client))
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	_, err = pixela.RecordPixels(graphID, []PixelRecord{{"20150101", "1", ""}, {"20190101", "1", ""}}, ImportAdd, nil)
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	if want := []string{"20150101-20150101", "20190101-20190101"}; !reflect.DeepEqual(windows, want) {
+		t.Fatalf("want windows %v, but %v", want, windows)
+	}
+
+	want := []string{
+		"PUT /v1/users/testuser/graphs/testgraphid/20150101",
+		"PUT /v1/users/testuser/graphs/testgraphid/20190101",
+	}
+
+	if !reflect.DeepEqual(requests, want) {
+		t.Fatalf("want %v, but %v", want, requests)
+	}
+}
+
+func TestMergeOptionalData(t *testing.T) {
+	tests := []struct {
+		existing string
+		added    string
+		want     string
+	}{
+		{`{"a":1}`, "", `{"a":1}`},
+		{"", `{"a":1}`, `{"a":1}`},
+		{`{"a":1,"b":[1]}`, `{"a":2,"b":[2],"c":"<x>"}`, `{"a":2,"b":[1,2],"c":"<x>"}`},
+		{`{"a":[1]}`, `{"a":2}`, `{"a":2}`},
+		{`"text"`, `{"a":1}`, `{"a":1}`},
+		{`{"a":1}`, `[1]`, `[1]`},
+	}
+
+	for _, tt := range tests {
+		got, err := MergeOptionalData(tt.existing, tt.added)
+
+		if err != nil || got != tt.want {
+			t.Fatalf("%s + %s: want %s, but %s (%v)", tt.existing, tt.added, tt.want, got, err)
+		}
+	}
+}
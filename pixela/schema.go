@@ -0,0 +1,514 @@
+package pixela
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// OptionalDataSchema is JSON Schema for optionalData of a graph.
+//
+// Supported keywords are subset of JSON Schema (draft 7):
+// `type`, `enum`, `const`, `properties`, `required`, `additionalProperties`, `items`,
+// `minItems`, `maxItems`, `minLength`, `maxLength`, `pattern`,
+// `minimum`, `maximum`, `exclusiveMinimum`, `exclusiveMaximum`, `anyOf` and `allOf`.
+// Other keywords (such as `$schema`, `title` or `description`) are ignored.
+type OptionalDataSchema struct {
+	root *schemaNode
+}
+
+// SchemaViolation is one mismatch between optionalData and schema
+type SchemaViolation struct {
+	// Path is JSON path of mismatched value such as `$.tags[0]`
+	Path string
+	// Message describes mismatch such as `expected number but got string`
+	Message string
+}
+
+type schemaNode struct {
+	types                []string
+	enum                 []interface{}
+	constValue           *interface{}
+	properties           map[string]*schemaNode
+	required             []string
+	additionalProperties *schemaNode
+	noAdditional         bool
+	items                *schemaNode
+	minItems             *int
+	maxItems             *int
+	minLength            *int
+	maxLength            *int
+	pattern              *regexp.Regexp
+	minimum              *float64
+	maximum              *float64
+	exclusiveMinimum     *float64
+	exclusiveMaximum     *float64
+	anyOf                []*schemaNode
+	allOf                []*schemaNode
+}
+
+// ParseOptionalDataSchema parses JSON Schema document
+func ParseOptionalDataSchema(data []byte) (*OptionalDataSchema, error) {
+	var raw interface{}
+
+	err := json.Unmarshal(data, &raw)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "schema is not valid JSON")
+	}
+
+	root, err := compileSchema(raw, "#")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &OptionalDataSchema{root: root}, nil
+}
+
+// LoadOptionalDataSchema reads JSON Schema file
+func LoadOptionalDataSchema(path string) (*OptionalDataSchema, error) {
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "can not read schema file")
+	}
+
+	schema, err := ParseOptionalDataSchema(data)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid schema file %s", path)
+	}
+
+	return schema, nil
+}
+
+func compileSchema(raw interface{}, location string) (*schemaNode, error) {
+	// boolean schema
+	if b, ok := raw.(bool); ok {
+		if b {
+			return &schemaNode{}, nil
+		}
+
+		return &schemaNode{anyOf: []*schemaNode{}}, nil
+	}
+
+	obj, ok := raw.(map[string]interface{})
+
+	if !ok {
+		return nil, fmt.Errorf("schema at `%s` must be object or boolean", location)
+	}
+
+	node := &schemaNode{}
+	var err error
+
+	if t, ok := obj["type"]; ok {
+		switch t := t.(type) {
+		case string:
+			node.types = []string{t}
+		case []interface{}:
+			for _, v := range t {
+				s, ok := v.(string)
+
+				if !ok {
+					return nil, fmt.Errorf("`type` at `%s` must be string or array of string", location)
+				}
+
+				node.types = append(node.types, s)
+			}
+		default:
+			return nil, fmt.Errorf("`type` at `%s` must be string or array of string", location)
+		}
+
+		for _, t := range node.types {
+			switch t {
+			case "null", "boolean", "object", "array", "number", "integer", "string":
+			default:
+				return nil, fmt.Errorf("unknown type `%s` at `%s`", t, location)
+			}
+		}
+	}
+
+	if e, ok := obj["enum"]; ok {
+		values, ok := e.([]interface{})
+
+		if !ok {
+			return nil, fmt.Errorf("`enum` at `%s` must be array", location)
+		}
+
+		node.enum = values
+	}
+
+	if c, ok := obj["const"]; ok {
+		node.constValue = &c
+	}
+
+	if p, ok := obj["properties"]; ok {
+		props, ok := p.(map[string]interface{})
+
+		if !ok {
+			return nil, fmt.Errorf("`properties` at `%s` must be object", location)
+		}
+
+		node.properties = make(map[string]*schemaNode, len(props))
+
+		for name, sub := range props {
+			node.properties[name], err = compileSchema(sub, location+"/properties/"+name)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if r, ok := obj["required"]; ok {
+		values, ok := r.([]interface{})
+
+		if !ok {
+			return nil, fmt.Errorf("`required` at `%s` must be array of string", location)
+		}
+
+		for _, v := range values {
+			s, ok := v.(string)
+
+			if !ok {
+				return nil, fmt.Errorf("`required` at `%s` must be array of string", location)
+			}
+
+			node.required = append(node.required, s)
+		}
+	}
+
+	if a, ok := obj["additionalProperties"]; ok {
+		if b, ok := a.(bool); ok {
+			node.noAdditional = !b
+		} else {
+			node.additionalProperties, err = compileSchema(a, location+"/additionalProperties")
+
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if i, ok := obj["items"]; ok {
+		node.items, err = compileSchema(i, location+"/items")
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for keyword, target := range map[string]**int{
+		"minItems":  &node.minItems,
+		"maxItems":  &node.maxItems,
+		"minLength": &node.minLength,
+		"maxLength": &node.maxLength,
+	} {
+		if v, ok := obj[keyword]; ok {
+			n, ok := v.(float64)
+
+			if !ok || n < 0 || n != math.Trunc(n) {
+				return nil, fmt.Errorf("`%s` at `%s` must be non-negative integer", keyword, location)
+			}
+
+			i := int(n)
+			*target = &i
+		}
+	}
+
+	for keyword, target := range map[string]**float64{
+		"minimum":          &node.minimum,
+		"maximum":          &node.maximum,
+		"exclusiveMinimum": &node.exclusiveMinimum,
+		"exclusiveMaximum": &node.exclusiveMaximum,
+	} {
+		if v, ok := obj[keyword]; ok {
+			n, ok := v.(float64)
+
+			if !ok {
+				return nil, fmt.Errorf("`%s` at `%s` must be number", keyword, location)
+			}
+
+			*target = &n
+		}
+	}
+
+	if p, ok := obj["pattern"]; ok {
+		s, ok := p.(string)
+
+		if !ok {
+			return nil, fmt.Errorf("`pattern` at `%s` must be string", location)
+		}
+
+		node.pattern, err = regexp.Compile(s)
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "`pattern` at `%s` is not valid regular expression", location)
+		}
+	}
+
+	for keyword, target := range map[string]*[]*schemaNode{
+		"anyOf": &node.anyOf,
+		"allOf": &node.allOf,
+	} {
+		if v, ok := obj[keyword]; ok {
+			subs, ok := v.([]interface{})
+
+			if !ok || len(subs) == 0 {
+				return nil, fmt.Errorf("`%s` at `%s` must be non-empty array", keyword, location)
+			}
+
+			for i, sub := range subs {
+				n, err := compileSchema(sub, fmt.Sprintf("%s/%s/%d", location, keyword, i))
+
+				if err != nil {
+					return nil, err
+				}
+
+				*target = append(*target, n)
+			}
+		}
+	}
+
+	return node, nil
+}
+
+// Validate checks optionalData (JSON string) against the schema
+func (s *OptionalDataSchema) Validate(optionalData string) []SchemaViolation {
+	decoder := json.NewDecoder(bytes.NewBufferString(optionalData))
+	decoder.UseNumber()
+
+	var value interface{}
+
+	if err := decoder.Decode(&value); err != nil {
+		return []SchemaViolation{{Path: "$", Message: "invalid JSON"}}
+	}
+
+	return s.root.validate(value, "$")
+}
+
+func (node *schemaNode) validate(value interface{}, path string) []SchemaViolation {
+	var violations []SchemaViolation
+
+	violation := func(format string, args ...interface{}) {
+		violations = append(violations, SchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(node.types) != 0 && !matchesAnyType(value, node.types) {
+		violation("expected %s but got %s", strings.Join(node.types, " or "), jsonType(value))
+		return violations
+	}
+
+	if node.enum != nil {
+		found := false
+
+		for _, e := range node.enum {
+			if jsonEqual(value, e) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			violation("value is not one of enum values")
+		}
+	}
+
+	if node.constValue != nil && !jsonEqual(value, *node.constValue) {
+		violation("value is not equal to const value")
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range node.required {
+			if _, ok := v[name]; !ok {
+				violation("required property `%s` is missing", name)
+			}
+		}
+
+		names := make([]string, 0, len(v))
+
+		for name := range v {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			childPath := path + "." + name
+
+			if sub, ok := node.properties[name]; ok {
+				violations = append(violations, sub.validate(v[name], childPath)...)
+			} else if node.noAdditional {
+				violation("additional property `%s` is not allowed", name)
+			} else if node.additionalProperties != nil {
+				violations = append(violations, node.additionalProperties.validate(v[name], childPath)...)
+			}
+		}
+	case []interface{}:
+		if node.minItems != nil && len(v) < *node.minItems {
+			violation("expected at least %d items but got %d", *node.minItems, len(v))
+		}
+
+		if node.maxItems != nil && len(v) > *node.maxItems {
+			violation("expected at most %d items but got %d", *node.maxItems, len(v))
+		}
+
+		if node.items != nil {
+			for i, item := range v {
+				violations = append(violations, node.items.validate(item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case string:
+		length := utf8.RuneCountInString(v)
+
+		if node.minLength != nil && length < *node.minLength {
+			violation("expected at least %d characters but got %d", *node.minLength, length)
+		}
+
+		if node.maxLength != nil && length > *node.maxLength {
+			violation("expected at most %d characters but got %d", *node.maxLength, length)
+		}
+
+		if node.pattern != nil && !node.pattern.MatchString(v) {
+			violation("value does not match pattern `%s`", node.pattern.String())
+		}
+	case json.Number:
+		n, _ := v.Float64()
+
+		if node.minimum != nil && n < *node.minimum {
+			violation("expected minimum %s but got %s", formatNumber(*node.minimum), v)
+		}
+
+		if node.maximum != nil && n > *node.maximum {
+			violation("expected maximum %s but got %s", formatNumber(*node.maximum), v)
+		}
+
+		if node.exclusiveMinimum != nil && n <= *node.exclusiveMinimum {
+			violation("expected greater than %s but got %s", formatNumber(*node.exclusiveMinimum), v)
+		}
+
+		if node.exclusiveMaximum != nil && n >= *node.exclusiveMaximum {
+			violation("expected less than %s but got %s", formatNumber(*node.exclusiveMaximum), v)
+		}
+	}
+
+	for _, sub := range node.allOf {
+		violations = append(violations, sub.validate(value, path)...)
+	}
+
+	if node.anyOf != nil {
+		matched := false
+
+		for _, sub := range node.anyOf {
+			if len(sub.validate(value, path)) == 0 {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			violation("value does not match any of `anyOf` schemas")
+		}
+	}
+
+	return violations
+}
+
+func matchesAnyType(value interface{}, types []string) bool {
+	actual := jsonType(value)
+
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// JSON Schema type name of decoded value
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return "integer"
+		}
+
+		if f, err := v.Float64(); err == nil && f == math.Trunc(f) && !strings.ContainsAny(v.String(), ".eE") {
+			return "integer"
+		}
+
+		return "number"
+	}
+
+	return "unknown"
+}
+
+// compare value decoded with UseNumber and value decoded without it
+func jsonEqual(a, b interface{}) bool {
+	if n, ok := a.(json.Number); ok {
+		f, err := n.Float64()
+		bf, isFloat := b.(float64)
+
+		return err == nil && isFloat && f == bf
+	}
+
+	switch av := a.(type) {
+	case []interface{}:
+		bv, ok := b.([]interface{})
+
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+
+		for i := range av {
+			if !jsonEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+
+		return true
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+
+		for k := range av {
+			if !jsonEqual(av[k], bv[k]) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
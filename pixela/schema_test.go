@@ -0,0 +1,120 @@
+package pixela
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+var testSchema = `{
+	"type": "object",
+	"required": ["mood", "tags"],
+	"additionalProperties": false,
+	"properties": {
+		"mood": {"type": "integer", "minimum": 1, "maximum": 5},
+		"note": {"type": "string", "maxLength": 10},
+		"tags": {"type": "array", "maxItems": 2, "items": {"type": "string", "pattern": "^[a-z]+$"}},
+		"place": {"enum": ["home", "office"]}
+	}
+}`
+
+func TestParseOptionalDataSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr bool
+	}{
+		{"Normal case", testSchema, false},
+		{"Boolean schema", `true`, false},
+		{"Invalid JSON", `{`, true},
+		{"Unknown type", `{"type": "date"}`, true},
+		{"Invalid pattern", `{"pattern": "["}`, true},
+		{"Invalid maxLength", `{"maxLength": -1}`, true},
+		{"Invalid nested schema", `{"properties": {"a": 1}}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseOptionalDataSchema([]byte(tt.schema))
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error %#v, but %#v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestOptionalDataSchema_Validate(t *testing.T) {
+	schema, err := ParseOptionalDataSchema([]byte(testSchema))
+
+	if err != nil {
+		t.Fatalf("got error when schema parsed %#v", err)
+	}
+
+	tests := []struct {
+		name         string
+		optionalData string
+		want         []SchemaViolation
+	}{
+		{"Normal case", `{"mood": 3, "tags": ["run"], "place": "home"}`, nil},
+		{"Missing required", `{"mood": 3}`, []SchemaViolation{{"$", "required property `tags` is missing"}}},
+		{"Wrong type", `{"mood": "good", "tags": []}`, []SchemaViolation{{"$.mood", "expected integer but got string"}}},
+		{"Decimal for integer", `{"mood": 2.5, "tags": []}`, []SchemaViolation{{"$.mood", "expected integer but got number"}}},
+		{"Out of range", `{"mood": 6, "tags": []}`, []SchemaViolation{{"$.mood", "expected maximum 5 but got 6"}}},
+		{"Nested array item", `{"mood": 1, "tags": ["ok", "NG"]}`, []SchemaViolation{{"$.tags[1]", "value does not match pattern `^[a-z]+$`"}}},
+		{"Too many items", `{"mood": 1, "tags": ["a", "b", "c"]}`, []SchemaViolation{{"$.tags", "expected at most 2 items but got 3"}}},
+		{"Too long string", `{"mood": 1, "tags": [], "note": "0123456789a"}`, []SchemaViolation{{"$.note", "expected at most 10 characters but got 11"}}},
+		{"Enum", `{"mood": 1, "tags": [], "place": "cafe"}`, []SchemaViolation{{"$.place", "value is not one of enum values"}}},
+		{"Additional property", `{"mood": 1, "tags": [], "extra": true}`, []SchemaViolation{{"$", "additional property `extra` is not allowed"}}},
+		{"Not object", `[1]`, []SchemaViolation{{"$", "expected object but got array"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := schema.Validate(tt.optionalData)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("want %#v, but %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPixela_PostPixelOptionalDataSchema(t *testing.T) {
+	schema, _ := ParseOptionalDataSchema([]byte(testSchema))
+	pixela, err := New(username, token, debug, OptionOptionalDataSchema(graphID, schema))
+
+	if err != nil {
+		t.Fatalf("got error when http client created %#v", err)
+	}
+
+	_, err = pixela.PostPixel(graphID, dateStr, quantityStr, `{"mood": 6, "tags": ["NG"]}`)
+
+	var validationError *ValidationError
+
+	if !errors.As(err, &validationError) {
+		t.Fatalf("want ValidationError, but %#v", err)
+	}
+
+	wantPaths := []string{"$.mood", "$.tags[0]"}
+
+	if len(validationError.Errors) != len(wantPaths) {
+		t.Fatalf("want %d errors, but %#v", len(wantPaths), validationError.Errors)
+	}
+
+	for i, path := range wantPaths {
+		if validationError.Errors[i].Path != path {
+			t.Fatalf("want %#v, but %#v", path, validationError.Errors[i].Path)
+		}
+	}
+
+	want := newCommandError(pixelPost, "wrong arguments: "+
+		fmt.Sprintf(validationErrorMessages["OptionalDataSchema"], "$.mood", "expected maximum 5 but got 6")+" and "+
+		fmt.Sprintf(validationErrorMessages["OptionalDataSchema"], "$.tags[0]", "value does not match pattern `^[a-z]+$`"))
+
+	if err.Error() != want.Error() {
+		t.Fatalf("want %#v, but %#v", want.Error(), err.Error())
+	}
+}
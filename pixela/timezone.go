@@ -0,0 +1,356 @@
+package pixela
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// timezoneAliases maps backward compatible (deprecated) tz database names to canonical names
+var timezoneAliases = map[string]string{
+	"GMT":                  "UTC",
+	"GMT+0":                "UTC",
+	"GMT-0":                "UTC",
+	"GMT0":                 "UTC",
+	"Greenwich":            "UTC",
+	"UCT":                  "UTC",
+	"Universal":            "UTC",
+	"Zulu":                 "UTC",
+	"Etc/GMT":              "UTC",
+	"Etc/UTC":              "UTC",
+	"Etc/UCT":              "UTC",
+	"Etc/Universal":        "UTC",
+	"Etc/Zulu":             "UTC",
+	"Japan":                "Asia/Tokyo",
+	"ROK":                  "Asia/Seoul",
+	"ROC":                  "Asia/Taipei",
+	"PRC":                  "Asia/Shanghai",
+	"Hongkong":             "Asia/Hong_Kong",
+	"Singapore":            "Asia/Singapore",
+	"Asia/Calcutta":        "Asia/Kolkata",
+	"Asia/Saigon":          "Asia/Ho_Chi_Minh",
+	"Asia/Katmandu":        "Asia/Kathmandu",
+	"Asia/Rangoon":         "Asia/Yangon",
+	"Israel":               "Asia/Jerusalem",
+	"Iran":                 "Asia/Tehran",
+	"Turkey":               "Europe/Istanbul",
+	"Egypt":                "Africa/Cairo",
+	"Libya":                "Africa/Tripoli",
+	"GB":                   "Europe/London",
+	"GB-Eire":              "Europe/London",
+	"Eire":                 "Europe/Dublin",
+	"Iceland":              "Atlantic/Reykjavik",
+	"Poland":               "Europe/Warsaw",
+	"Portugal":             "Europe/Lisbon",
+	"W-SU":                 "Europe/Moscow",
+	"NZ":                   "Pacific/Auckland",
+	"NZ-CHAT":              "Pacific/Chatham",
+	"Kwajalein":            "Pacific/Kwajalein",
+	"Jamaica":              "America/Jamaica",
+	"Cuba":                 "America/Havana",
+	"Navajo":               "America/Denver",
+	"US/Alaska":            "America/Anchorage",
+	"US/Arizona":           "America/Phoenix",
+	"US/Central":           "America/Chicago",
+	"US/Eastern":           "America/New_York",
+	"US/Hawaii":            "Pacific/Honolulu",
+	"US/Mountain":          "America/Denver",
+	"US/Pacific":           "America/Los_Angeles",
+	"Canada/Atlantic":      "America/Halifax",
+	"Canada/Central":       "America/Winnipeg",
+	"Canada/Eastern":       "America/Toronto",
+	"Canada/Mountain":      "America/Edmonton",
+	"Canada/Pacific":       "America/Vancouver",
+	"Brazil/East":          "America/Sao_Paulo",
+	"Mexico/General":       "America/Mexico_City",
+	"Australia/ACT":        "Australia/Sydney",
+	"Australia/NSW":        "Australia/Sydney",
+	"Australia/Canberra":   "Australia/Sydney",
+	"Australia/North":      "Australia/Darwin",
+	"Australia/Queensland": "Australia/Brisbane",
+	"Australia/South":      "Australia/Adelaide",
+	"Australia/Tasmania":   "Australia/Hobart",
+	"Australia/Victoria":   "Australia/Melbourne",
+	"Australia/West":       "Australia/Perth",
+}
+
+// NormalizeTimezone validates timezone by tz database and returns canonical name.
+// Deprecated aliases (such as `Japan` or `US/Eastern`) and case differences are canonicalized.
+func NormalizeTimezone(timezone string) (string, error) {
+	tz := strings.TrimSpace(timezone)
+
+	for alias, canonical := range timezoneAliases {
+		if strings.EqualFold(tz, alias) {
+			return canonical, nil
+		}
+	}
+
+	if strings.EqualFold(tz, "UTC") {
+		return "UTC", nil
+	}
+
+	// case insensitive match to known zone names
+	if names, err := TimezoneNames(); err == nil {
+		for _, name := range names {
+			if strings.EqualFold(tz, name) {
+				return name, nil
+			}
+		}
+	}
+
+	if tz == "" || strings.EqualFold(tz, "Local") {
+		return "", fmt.Errorf("unknown timezone `%s`", timezone)
+	}
+
+	if _, err := time.LoadLocation(tz); err != nil {
+		return "", fmt.Errorf("unknown timezone `%s`", timezone)
+	}
+
+	return tz, nil
+}
+
+var (
+	timezoneNamesOnce sync.Once
+	timezoneNames     []string
+	timezoneNamesErr  error
+)
+
+//go:generate go run gen_timezones.go
+
+// TimezoneNames returns zone names of tz database installed in the system.
+// Built-in zone names which time.LoadLocation can load are returned when it is not installed.
+func TimezoneNames() ([]string, error) {
+	timezoneNamesOnce.Do(func() {
+		timezoneNames, timezoneNamesErr = loadTimezoneNames(timezoneSources())
+	})
+
+	return timezoneNames, timezoneNamesErr
+}
+
+// tz database sources of the system searched in the same order as time.LoadLocation
+// (zoneinfo.zip of Go distribution is not searched. built-in zone names are used instead)
+func timezoneSources() []string {
+	var sources []string
+
+	if zoneinfo := os.Getenv("ZONEINFO"); zoneinfo != "" {
+		sources = append(sources, zoneinfo)
+	}
+
+	return append(sources,
+		"/usr/share/zoneinfo/",
+		"/usr/share/lib/zoneinfo/",
+		"/usr/lib/locale/TZ/",
+		"/etc/zoneinfo/",
+	)
+}
+
+func loadTimezoneNames(sources []string) ([]string, error) {
+	for _, source := range sources {
+		var names []string
+		var err error
+
+		if strings.HasSuffix(source, ".zip") {
+			names, err = zoneNamesFromZip(source)
+		} else {
+			names, err = zoneNamesFromDir(source)
+		}
+
+		if err == nil && len(names) != 0 {
+			sort.Strings(names)
+			return names, nil
+		}
+	}
+
+	// time.LoadLocation can load zones from embedded time/tzdata or Go distribution
+	var names []string
+
+	for _, name := range builtinTimezoneNames {
+		if _, err := time.LoadLocation(name); err == nil {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("tz database is not found")
+	}
+
+	return names, nil
+}
+
+func zoneNamesFromZip(path string) ([]string, error) {
+	r, err := zip.OpenReader(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.Close()
+
+	var names []string
+
+	for _, f := range r.File {
+		if isZoneName(f.Name) {
+			names = append(names, f.Name)
+		}
+	}
+
+	return names, nil
+}
+
+func zoneNamesFromDir(root string) ([]string, error) {
+	var names []string
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name, _ := filepath.Rel(root, path)
+		name = filepath.ToSlash(name)
+
+		if info.IsDir() {
+			if name == "posix" || name == "right" {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if isZoneName(name) && isTZif(path) {
+			names = append(names, name)
+		}
+
+		return nil
+	})
+
+	return names, err
+}
+
+func isZoneName(name string) bool {
+	switch name {
+	case "localtime", "posixrules", "Factory":
+		return false
+	}
+
+	return !strings.HasSuffix(name, "/") && !strings.Contains(name, ".")
+}
+
+// check TZif magic number
+func isTZif(path string) bool {
+	f, err := os.Open(path)
+
+	if err != nil {
+		return false
+	}
+
+	defer f.Close()
+
+	magic := make([]byte, 4)
+
+	if _, err := f.Read(magic); err != nil {
+		return false
+	}
+
+	return string(magic) == "TZif"
+}
+
+// SuggestTimezones returns at most n zone names similar to input (fuzzy matching)
+func SuggestTimezones(input string, n int) []string {
+	names, err := TimezoneNames()
+
+	if err != nil || n <= 0 {
+		return nil
+	}
+
+	query := strings.ToLower(strings.TrimSpace(input))
+	query = strings.ReplaceAll(query, " ", "_")
+
+	type candidate struct {
+		name  string
+		score int
+	}
+
+	candidates := make([]candidate, 0, len(names))
+
+	for _, name := range names {
+		if _, ok := timezoneAliases[name]; ok {
+			continue
+		}
+
+		candidates = append(candidates, candidate{name, timezoneDistance(query, strings.ToLower(name))})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score < candidates[j].score
+	})
+
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+
+	suggestions := make([]string, 0, len(candidates))
+
+	for _, c := range candidates {
+		suggestions = append(suggestions, c.name)
+	}
+
+	return suggestions
+}
+
+// distance between query and zone name. smaller is closer.
+// query matching city part (such as `tokyo` for `Asia/Tokyo`) is preferred.
+func timezoneDistance(query, name string) int {
+	if strings.Contains(name, query) {
+		return len(name) - len(query)
+	}
+
+	city := name[strings.LastIndex(name, "/")+1:]
+	d := levenshtein(query, name)
+
+	if cd := levenshtein(query, city); cd < d {
+		d = cd
+	}
+
+	return 100 + d
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			cur[j] = prev[j] + 1
+
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+
+			if prev[j-1]+cost < cur[j] {
+				cur[j] = prev[j-1] + cost
+			}
+		}
+
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
@@ -0,0 +1,104 @@
+package pixela
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeTimezone(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		want     string
+		wantErr  bool
+	}{
+		{"Canonical name", "Asia/Tokyo", "Asia/Tokyo", false},
+		{"UTC", "UTC", "UTC", false},
+		{"Alias", "Japan", "Asia/Tokyo", false},
+		{"US alias", "US/Eastern", "America/New_York", false},
+		{"Case insensitive", "asia/tokyo", "Asia/Tokyo", false},
+		{"Surrounding spaces", " Europe/London ", "Europe/London", false},
+		{"Typo", "Asia/Tokio", "", true},
+		{"Empty", "", "", true},
+		{"Local", "Local", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeTimezone(tt.timezone)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error %#v, but %#v", tt.wantErr, err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("want %#v, but %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLoadTimezoneNames(t *testing.T) {
+	// built-in zone names are used without tz database of the system
+	names, err := loadTimezoneNames([]string{filepath.Join(t.TempDir(), "zoneinfo")})
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	found := false
+
+	for _, name := range names {
+		if name == "Asia/Tokyo" {
+			found = true
+		}
+
+		if name == "Factory" || name == "localtime" {
+			t.Fatalf("unexpected zone name %s", name)
+		}
+	}
+
+	if !found {
+		t.Fatalf("want Asia/Tokyo, but %v", names)
+	}
+}
+
+func TestSuggestTimezones(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"Typo", "Asia/Tokio", "Asia/Tokyo"},
+		{"City only", "tokyo", "Asia/Tokyo"},
+		{"City with space", "new york", "America/New_York"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SuggestTimezones(tt.input, 3)
+
+			if len(got) == 0 || got[0] != tt.want {
+				t.Fatalf("want %#v first, but %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"tokyo", "tokyo", 0},
+		{"tokio", "tokyo", 1},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Fatalf("levenshtein(%#v, %#v): want %d, but %d", tt.a, tt.b, tt.want, got)
+		}
+	}
+}
@@ -2,8 +2,10 @@ package pixela
 
 import (
 	"bytes"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
+	"math/big"
 
 	"github.com/pkg/errors"
 )
@@ -16,6 +18,12 @@ type CreateUserPayload struct {
 	NotMinor            string `json:"notMinor"`
 }
 
+// CreateUserRequest is request for CreateUserWithRequest
+type CreateUserRequest struct {
+	AgreeTermsOfService bool
+	NotMinor            bool
+}
+
 // UpdateUserPayload is payload for `user update` subcommand
 type UpdateUserPayload struct {
 	NewToken string `json:"newToken"`
@@ -35,12 +43,20 @@ func (pixela *Pixela) CreateUser(agreeTermsOfService, notMinor string) (NoneGetR
 		return NoneGetResponseBody{}, errors.Wrap(err, "`user create`: wrong arguments")
 	}
 
+	return pixela.CreateUserWithRequest(CreateUserRequest{
+		AgreeTermsOfService: agreeTermsOfService == "yes",
+		NotMinor:            notMinor == "yes",
+	})
+}
+
+// CreateUserWithRequest is method for `user create` subcommand
+func (pixela *Pixela) CreateUserWithRequest(req CreateUserRequest) (NoneGetResponseBody, error) {
 	// create payload
 	pl := CreateUserPayload{
 		Username:            pixela.Username,
 		Token:               pixela.Token,
-		AgreeTermsOfService: agreeTermsOfService,
-		NotMinor:            notMinor,
+		AgreeTermsOfService: yesNo(req.AgreeTermsOfService),
+		NotMinor:            yesNo(req.NotMinor),
 	}
 
 	plJSON, err := json.Marshal(pl)
@@ -137,3 +153,37 @@ func (pixela *Pixela) DeleteUser() (NoneGetResponseBody, error) {
 
 	return deleteResponseBody, nil
 }
+
+// convert bool to `yes` or `no`
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
+
+// tokenCharacters are characters of generated token
+const tokenCharacters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// GenerateToken generates random token (alphanumeric characters) by crypto/rand
+func GenerateToken(length int) (string, error) {
+	if length < 8 || length > 128 {
+		return "", fmt.Errorf("token length must be 8 to 128: %d", length)
+	}
+
+	token := make([]byte, length)
+	max := big.NewInt(int64(len(tokenCharacters)))
+
+	for i := range token {
+		n, err := rand.Int(rand.Reader, max)
+
+		if err != nil {
+			return "", errors.Wrap(err, "token generation failed")
+		}
+
+		token[i] = tokenCharacters[n.Int64()]
+	}
+
+	return string(token), nil
+}
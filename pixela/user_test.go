@@ -50,3 +50,48 @@ func TestPixela_DeleteUser(t *testing.T) {
 
 	subCommandTestHelper(t, userDelete, tests, userDeleteURL)
 }
+
+func TestGenerateToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		length  int
+		wantErr bool
+	}{
+		{"Default", 32, false},
+		{"Min", 8, false},
+		{"Max", 128, false},
+		{"Too short", 7, true},
+		{"Too long", 129, true},
+	}
+
+	v := newValidator()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateToken(tt.length)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error %#v, but %#v", tt.wantErr, err)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if len(got) != tt.length {
+				t.Fatalf("want length %d, but %d", tt.length, len(got))
+			}
+
+			if err := v.Validate(validateField{NewToken: got}); err != nil {
+				t.Fatalf("generated token is invalid: %#v", err)
+			}
+		})
+	}
+
+	first, _ := GenerateToken(32)
+	second, _ := GenerateToken(32)
+
+	if first == second {
+		t.Fatalf("want different tokens, but both %#v", first)
+	}
+}
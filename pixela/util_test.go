@@ -41,7 +41,7 @@ var ivResp = []byte("hoge")
 var pixelRespWOp, _ = json.Marshal(GetPixelResponseBody{Quantity: quantityStr, OptionalData: `{"key": "value"}`})
 var pixelRespWoOp, _ = json.Marshal(GetPixelResponseBody{Quantity: quantityStr})
 var webhookResp, _ = json.Marshal(WebhookDefinitions{[]Webhook{{webhookHash, graphID, webhookType}}})
-var graphDefResp, _ = json.Marshal(GraphDefinitions{[]Graph{{graphID, graphName, graphUnit, numType, validColor, "Asia/Tokyo", []string{""}}}})
+var graphDefResp, _ = json.Marshal(GraphDefinitions{[]Graph{{graphID, graphName, graphUnit, numType, validColor, "Asia/Tokyo", []string{""}, ""}}})
 var graphSvgResp = `<sgv>test</svg>`
 var graphPixelsResp, _ = json.Marshal(PixelsDateList{[]string{"20190101", "20190102"}})
 var graphStatResp, _ = json.Marshal(GraphStat{10, 10, 0, 100, 20, 5})
@@ -199,8 +199,8 @@ func subCommandTestHelper(t *testing.T, cmd subCommand, tests testCases, urlStr
 				return resp
 			})
 
-			// skip checking instance creation error
-			pixela, err := New(username, token, debug, OptionHTTPClient(c))
+			// skip checking instance creation error (quantity type check is tested by TestPixela_PostPixelQuantityTypeCheck)
+			pixela, err := New(username, token, debug, OptionHTTPClient(c), OptionQuantityTypeCheck(false))
 			err = subCommandMethodCall(pixela, tt, cmd)
 
 			if err != nil {
@@ -246,11 +246,10 @@ func subCommandMethodCall(pixela *Pixela, tt testCase, cmd subCommand) error {
 		_, err = pixela.CreateGraph(tt.args[0], tt.args[1], tt.args[2], tt.args[3], tt.args[4], tt.args[5], tt.args[6])
 	case graphUpdate:
 		payload := UpdateGraphPayload{
-			tt.args[1],
-			tt.args[2],
-			tt.args[3],
-			tt.args[4],
-			[]string{tt.args[5]},
+			Name:     tt.args[1],
+			Unit:     tt.args[2],
+			Color:    tt.args[4],
+			Timezone: tt.args[5],
 		}
 		_, err = pixela.UpdateGraph(tt.args[0], payload)
 	case graphDelete:
@@ -2,12 +2,15 @@ package pixela
 
 import (
 	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
 )
 
 type newInstanceValidateField struct {
@@ -16,105 +19,317 @@ type newInstanceValidateField struct {
 }
 
 type validateField struct {
-	AgreeTermsOfService string `validate:"omitempty,oneof=yes no"`
-	NotMinor            string `validate:"omitempty,oneof=yes no"`
+	AgreeTermsOfService string `validate:"omitempty,yesno"`
+	NotMinor            string `validate:"omitempty,yesno"`
 	NewToken            string `validate:"omitempty,token"`
 	GraphID             string `validate:"omitempty,graphid"`
-	UnitType            string `validate:"omitempty,oneof=int float"`
-	Color               string `validate:"omitempty,oneof=shibafu momiji sora ichou ajisai kuro"`
+	UnitType            string `validate:"omitempty,numtype"`
+	Color               string `validate:"omitempty,color"`
 	Date                string `validate:"omitempty,date"`
 	From                string `validate:"omitempty,date"`
 	To                  string `validate:"omitempty,date"`
 	Quantity            string `validate:"omitempty,quantity"`
-	WebhookType         string `validate:"omitempty,oneof=increment decrement"`
+	WebhookType         string `validate:"omitempty,webhooktype"`
 	OptionalData        string `validate:"omitempty,optionaldata"`
-	SelfSufficient      string `validate:"omitempty,oneof=none increment decrement"`
+	SelfSufficient      string `validate:"omitempty,selfsufficient"`
+	Timezone            string `validate:"omitempty,timezone"`
+}
+
+// ValidationRulesVersion is version of validation rules format this client understands
+const ValidationRulesVersion = 1
+
+// ValidationRules is data driven rule set for argument validation
+type ValidationRules struct {
+	Version              int      `json:"version" yaml:"version"`
+	UsernamePattern      string   `json:"usernamePattern" yaml:"usernamePattern"`
+	TokenPattern         string   `json:"tokenPattern" yaml:"tokenPattern"`
+	GraphIDPattern       string   `json:"graphIDPattern" yaml:"graphIDPattern"`
+	YesNo                []string `json:"yesNo" yaml:"yesNo"`
+	NumTypes             []string `json:"numTypes" yaml:"numTypes"`
+	Colors               []string `json:"colors" yaml:"colors"`
+	WebhookTypes         []string `json:"webhookTypes" yaml:"webhookTypes"`
+	SelfSufficients      []string `json:"selfSufficients" yaml:"selfSufficients"`
+	OptionalDataMaxBytes int      `json:"optionalDataMaxBytes" yaml:"optionalDataMaxBytes"`
+}
+
+// DefaultValidationRules returns built-in rule set according to official document
+func DefaultValidationRules() ValidationRules {
+	rules := ValidationRules{
+		Version:              ValidationRulesVersion,
+		UsernamePattern:      `^[a-z][a-z0-9-]{1,32}$`,
+		TokenPattern:         `^[ -~]{8,128}$`,
+		GraphIDPattern:       `^[a-z][a-z0-9-]{1,16}$`,
+		YesNo:                []string{"yes", "no"},
+		OptionalDataMaxBytes: 10240,
+	}
+
+	for _, n := range NumTypes() {
+		rules.NumTypes = append(rules.NumTypes, n.String())
+	}
+
+	for _, c := range Colors() {
+		rules.Colors = append(rules.Colors, c.String())
+	}
+
+	for _, w := range WebhookTypes() {
+		rules.WebhookTypes = append(rules.WebhookTypes, w.String())
+	}
+
+	for _, s := range SelfSufficients() {
+		rules.SelfSufficients = append(rules.SelfSufficients, s.String())
+	}
+
+	return rules
+}
+
+// LoadValidationRules reads rule set override file (YAML or JSON).
+// Omitted keys keep built-in default value.
+func LoadValidationRules(path string) (ValidationRules, error) {
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return ValidationRules{}, errors.Wrap(err, "can not read validation rules file")
+	}
+
+	rules := DefaultValidationRules()
+	err = yaml.Unmarshal(data, &rules)
+
+	if err != nil {
+		return ValidationRules{}, errors.Wrap(err, "can not parse validation rules file")
+	}
+
+	err = rules.check()
+
+	if err != nil {
+		return ValidationRules{}, errors.Wrapf(err, "invalid validation rules file %s", path)
+	}
+
+	return rules, nil
+}
+
+// check rule set consistency
+func (rules ValidationRules) check() error {
+	if rules.Version != ValidationRulesVersion {
+		return fmt.Errorf("unsupported version %d (supported version is %d)", rules.Version, ValidationRulesVersion)
+	}
+
+	for name, pattern := range map[string]string{
+		"usernamePattern": rules.UsernamePattern,
+		"tokenPattern":    rules.TokenPattern,
+		"graphIDPattern":  rules.GraphIDPattern,
+	} {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return errors.Wrapf(err, "`%s` is not valid regular expression", name)
+		}
+	}
+
+	if rules.OptionalDataMaxBytes <= 0 {
+		return errors.New("`optionalDataMaxBytes` must be positive")
+	}
+
+	return nil
+}
+
+// ValidationMode is strictness of argument validation
+type ValidationMode int
+
+const (
+	// ValidationStrict checks every rule
+	ValidationStrict ValidationMode = iota
+	// ValidationLenient checks only formats which request URL and payload depend on.
+	// Allowed values (color, type and so on) and size limits are left to server.
+	ValidationLenient
+	// ValidationOff disables validation
+	ValidationOff
+)
+
+var validationModeStrings = map[ValidationMode]string{
+	ValidationStrict:  "strict",
+	ValidationLenient: "lenient",
+	ValidationOff:     "off",
+}
+
+func (m ValidationMode) String() string {
+	return validationModeStrings[m]
+}
+
+// ParseValidationMode converts string to ValidationMode
+func ParseValidationMode(s string) (ValidationMode, error) {
+	for mode, str := range validationModeStrings {
+		if str == s {
+			return mode, nil
+		}
+	}
+
+	return ValidationStrict, fmt.Errorf("invalid validation mode `%s`: allows `strict`, `lenient` or `off`", s)
 }
 
 // Validator is struct for argument validation
 type Validator struct {
 	validator *validator.Validate
+	rules     ValidationRules
+	mode      ValidationMode
+	locale    string
+	messages  map[string]string
 }
 
 func newValidator() Validator {
+	// built-in rules are always consistent
+	validate, _ := newValidatorWithRules(DefaultValidationRules(), ValidationStrict, DefaultLocale)
+
+	return validate
+}
+
+func newValidatorWithRules(rules ValidationRules, mode ValidationMode, locale string) (Validator, error) {
+	err := rules.check()
+
+	if err != nil {
+		return Validator{}, errors.Wrap(err, "invalid validation rules")
+	}
+
+	lenient := mode != ValidationStrict
 	validate := validator.New()
 
-	validate.RegisterValidation("username", usernameValidation)
-	validate.RegisterValidation("token", tokenValidation)
-	validate.RegisterValidation("graphid", graphIDValidator)
+	validate.RegisterValidation("username", patternValidator(rules.UsernamePattern))
+	validate.RegisterValidation("token", patternValidator(rules.TokenPattern))
+	validate.RegisterValidation("graphid", patternValidator(rules.GraphIDPattern))
 	validate.RegisterValidation("date", dateValidator)
 	validate.RegisterValidation("quantity", quantityValidator)
-	validate.RegisterValidation("optionaldata", optionalDataValidator)
+	validate.RegisterValidation("optionaldata", optionalDataValidator(rules.OptionalDataMaxBytes, lenient))
+	validate.RegisterValidation("yesno", oneOfValidator(rules.YesNo, lenient))
+	validate.RegisterValidation("numtype", oneOfValidator(rules.NumTypes, lenient))
+	validate.RegisterValidation("color", oneOfValidator(rules.Colors, lenient))
+	validate.RegisterValidation("webhooktype", oneOfValidator(rules.WebhookTypes, lenient))
+	validate.RegisterValidation("selfsufficient", oneOfValidator(rules.SelfSufficients, lenient))
+	validate.RegisterValidation("timezone", timezoneValidator(lenient))
+
+	return Validator{
+		validator: validate,
+		rules:     rules,
+		mode:      mode,
+		locale:    locale,
+		messages:  localizedMessages(locale, rules),
+	}, nil
+}
 
-	return Validator{validator: validate}
+// Rules returns rule set of the validator
+func (pv *Validator) Rules() ValidationRules {
+	return pv.rules
 }
 
-var validationErrorMessages = map[string]string{
-	"Username":            "`username` allows lowercase alphabet, number and hyphen (NOTE: first letter only allows alphabet.) and 1 to 32 length.",
-	"Token":               "`token` allows 8 to 128 length.",
-	"AgreeTermsOfService": "`agreeTermsOfService` allows `yes` or `no`.",
-	"NotMinor":            "`notMinor` allows `yes` or `no`.",
-	"NewToken":            "`newToken` allows 8 to 128 length.",
-	"GraphID":             "`graphID` allows lowercase alphabet, number and hyphen (NOTE: first letter only allows alphabet.) and 1 to 16 length.",
-	"UnitType":            "`unit` allows `int` or `float`.",
-	"Color":               "`color` allows `shibafu`, `momiji`, `sora`, `ichou`, `ajisai` or `kuro`.",
-	"Date":                "`date` format is `yyyyMMdd`.",
-	"From":                "`from` format is `yyyyMMdd`.",
-	"To":                  "`to` format is `yyyyMMdd`.",
-	"Quantity":            "`quantity` allows value of int or float.",
-	"WebhookType":         "`type` allows `increment` or `decrement`.",
-	"OptionalData":        "`optionalData` is under 10k JSON string.",
-	"SelfSufficient":      "`selfSufficient` allows `increment` or `decrement`.",
+// Mode returns validation mode of the validator
+func (pv *Validator) Mode() ValidationMode {
+	return pv.mode
+}
+
+// Locale returns locale of error messages
+func (pv *Validator) Locale() string {
+	return pv.locale
+}
+
+// API parameter name of validated fields
+var validationFieldNames = map[string]string{
+	"Username":            "username",
+	"Token":               "token",
+	"AgreeTermsOfService": "agreeTermsOfService",
+	"NotMinor":            "notMinor",
+	"NewToken":            "newToken",
+	"GraphID":             "graphID",
+	"UnitType":            "type",
+	"Color":               "color",
+	"Date":                "date",
+	"From":                "from",
+	"To":                  "to",
+	"Quantity":            "quantity",
+	"WebhookType":         "type",
+	"OptionalData":        "optionalData",
+	"SelfSufficient":      "selfSufficient",
+	"Timezone":            "timezone",
+}
+
+// FieldError is validation error of one field
+type FieldError struct {
+	// Field is API parameter name such as `graphID`
+	Field string
+	// Rule is failed rule name such as `graphid` or `color`
+	Rule string
+	// Value is rejected value
+	Value string
+	// Path is JSON path in the value (only for optionalData schema validation)
+	Path string
+	// Message is localized error message
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Message
+}
+
+// ValidationError is error of argument validation. It can be extracted by errors.As.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+
+	for _, fe := range e.Errors {
+		messages = append(messages, fe.Message)
+	}
+
+	return strings.Join(messages, " and ")
 }
 
 // Validate is method for argument validation
 func (pv *Validator) Validate(i interface{}) error {
-	err := pv.validator.Struct(i)
+	if pv.mode == ValidationOff {
+		return nil
+	}
 
-	var errorMessages []string
+	err := pv.validator.Struct(i)
 
 	if err != nil {
+		validationError := &ValidationError{}
+
 		for _, err := range err.(validator.ValidationErrors) {
-			errorMessages = append(errorMessages, validationErrorMessages[err.Field()])
+			validationError.Errors = append(validationError.Errors, FieldError{
+				Field:   validationFieldNames[err.Field()],
+				Rule:    err.Tag(),
+				Value:   fmt.Sprint(err.Value()),
+				Message: pv.messages[err.Field()],
+			})
 		}
 
-		return errors.New(strings.Join(errorMessages, " and "))
+		return validationError
 	}
 
 	return nil
 }
 
-// username validator
-func usernameValidation(fl validator.FieldLevel) bool {
-	tf, err := regexp.Match(`^[a-z][a-z0-9-]{1,32}$`, []byte(fl.Field().String()))
+// pattern validator (username, token and graphID)
+func patternValidator(pattern string) validator.Func {
+	re := regexp.MustCompile(pattern)
 
-	if tf && err == nil {
-		return true
+	return func(fl validator.FieldLevel) bool {
+		return re.MatchString(fl.Field().String())
 	}
-
-	return false
 }
 
-// token validator
-func tokenValidation(fl validator.FieldLevel) bool {
-	tf, err := regexp.Match(`^[ -~]{8,128}$`, []byte(fl.Field().String()))
-
-	if tf && err == nil {
-		return true
-	}
-
-	return false
-}
+// enumerated values validator (color, type and so on)
+func oneOfValidator(values []string, lenient bool) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		if lenient {
+			return true
+		}
 
-// graphID validator
-func graphIDValidator(fl validator.FieldLevel) bool {
-	tf, err := regexp.Match(`^[a-z][a-z0-9-]{1,16}$`, []byte(fl.Field().String()))
+		for _, v := range values {
+			if fl.Field().String() == v {
+				return true
+			}
+		}
 
-	if tf && err == nil {
-		return true
+		return false
 	}
-
-	return false
 }
 
 // date validator
@@ -128,24 +343,85 @@ func dateValidator(fl validator.FieldLevel) bool {
 	return true
 }
 
+var (
+	quantityPattern    = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?$`)
+	intQuantityPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+)
+
 // quantity validator
 func quantityValidator(fl validator.FieldLevel) bool {
-	tf, err := regexp.Match(`^(0|[1-9][0-9]*)(\.[0-9])*$`, []byte(fl.Field().String()))
+	return quantityPattern.MatchString(fl.Field().String())
+}
 
-	if tf && err == nil {
-		return true
+// ValidateQuantityType is method for quantity validation against graph type
+func (pv *Validator) ValidateQuantityType(numType, quantity string) error {
+	if pv.mode == ValidationOff {
+		return nil
 	}
 
-	return false
+	if numType == NumTypeInt.String() && !intQuantityPattern.MatchString(quantity) {
+		return &ValidationError{
+			Errors: []FieldError{{
+				Field:   "quantity",
+				Rule:    "inttype",
+				Value:   quantity,
+				Message: pv.messages["QuantityInt"],
+			}},
+		}
+	}
+
+	return nil
 }
 
-// optionalData validator
-func optionalDataValidator(fl validator.FieldLevel) bool {
-	optionalData := fl.Field().String()
+// ValidateOptionalDataSchema is method for optionalData validation against JSON Schema
+func (pv *Validator) ValidateOptionalDataSchema(schema *OptionalDataSchema, optionalData string) error {
+	if pv.mode == ValidationOff || schema == nil || len(optionalData) == 0 {
+		return nil
+	}
 
-	if len(optionalData) > 10240 || !json.Valid([]byte(optionalData)) {
-		return false
+	violations := schema.Validate(optionalData)
+
+	if len(violations) == 0 {
+		return nil
 	}
 
-	return true
+	validationError := &ValidationError{}
+
+	for _, v := range violations {
+		validationError.Errors = append(validationError.Errors, FieldError{
+			Field:   "optionalData",
+			Rule:    "schema",
+			Value:   optionalData,
+			Path:    v.Path,
+			Message: fmt.Sprintf(pv.messages["OptionalDataSchema"], v.Path, v.Message),
+		})
+	}
+
+	return validationError
+}
+
+// timezone validator (tz database name)
+func timezoneValidator(lenient bool) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		if lenient {
+			return true
+		}
+
+		_, err := NormalizeTimezone(fl.Field().String())
+
+		return err == nil
+	}
+}
+
+// optionalData validator
+func optionalDataValidator(maxBytes int, lenient bool) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		optionalData := fl.Field().String()
+
+		if !json.Valid([]byte(optionalData)) {
+			return false
+		}
+
+		return lenient || len(optionalData) <= maxBytes
+	}
 }
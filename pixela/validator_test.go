@@ -1,12 +1,17 @@
 package pixela
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/pkg/errors"
 )
 
+// expected error messages of default locale and rules
+var validationErrorMessages = localizedMessages(DefaultLocale, DefaultValidationRules())
+
 type validateTestCase struct {
 	name    string
 	target  string
@@ -145,6 +150,10 @@ func TestValidator_quantityValidation(t *testing.T) {
 		{"Float (succession with zero)", "0.0", nil},
 		{"Float (start with zero)", "0.1", nil},
 		{"Float (start with none zero)", "1.1", nil},
+		{"Negative int", "-1", nil},
+		{"Negative float", "-0.5", nil},
+		{"Multiple decimal points", "1.2.3", wantError},
+		{"Trailing decimal point", "1.", wantError},
 		{"Int (succession with zero)", "00", wantError},
 		{"Include none digit char", "A", wantError},
 		{"mix none digit char", "0A", wantError},
@@ -166,3 +175,151 @@ func TestValidator_optionalDataValidation(t *testing.T) {
 
 	validateTestHelper(t, "OptionalData", tests)
 }
+
+// test for validation rules override file
+func TestLoadValidationRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"Override colors", "version: 1\ncolors: [shibafu, red]\n", false},
+		{"JSON format", `{"version": 1, "optionalDataMaxBytes": 20480}`, false},
+		{"Unsupported version", "version: 2\n", true},
+		{"Invalid pattern", "version: 1\ngraphIDPattern: '['\n", true},
+		{"Invalid size limit", "version: 1\noptionalDataMaxBytes: 0\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rules.yaml")
+
+			if err := ioutil.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatalf("can not write rules file: %#v", err)
+			}
+
+			_, err := LoadValidationRules(path)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error %#v, but %#v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// test for validation with custom rules and modes
+func TestValidator_rulesAndMode(t *testing.T) {
+	rules := DefaultValidationRules()
+	rules.Colors = append(rules.Colors, "red")
+
+	tests := []struct {
+		name    string
+		rules   ValidationRules
+		mode    ValidationMode
+		target  validateField
+		wantErr bool
+	}{
+		{"Strict default rejects unknown color", DefaultValidationRules(), ValidationStrict, validateField{Color: "red"}, true},
+		{"Strict custom rules accepts added color", rules, ValidationStrict, validateField{Color: "red"}, false},
+		{"Lenient accepts unknown color", DefaultValidationRules(), ValidationLenient, validateField{Color: "red"}, false},
+		{"Lenient accepts large optionalData", DefaultValidationRules(), ValidationLenient, validateField{OptionalData: `"` + strings.Repeat("a", 10241) + `"`}, false},
+		{"Lenient rejects invalid graphID", DefaultValidationRules(), ValidationLenient, validateField{GraphID: "0000"}, true},
+		{"Off accepts everything", DefaultValidationRules(), ValidationOff, validateField{GraphID: "0000", Color: "red"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validate, err := newValidatorWithRules(tt.rules, tt.mode, DefaultLocale)
+
+			if err != nil {
+				t.Fatalf("got error when validator created %#v", err)
+			}
+
+			err = validate.Validate(tt.target)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error %#v, but %#v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// test for validation mode parsing
+func TestParseValidationMode(t *testing.T) {
+	for _, mode := range []ValidationMode{ValidationStrict, ValidationLenient, ValidationOff} {
+		got, err := ParseValidationMode(mode.String())
+
+		if err != nil || got != mode {
+			t.Fatalf("want %#v, but %#v (%#v)", mode, got, err)
+		}
+	}
+
+	if _, err := ParseValidationMode("loose"); err == nil {
+		t.Fatal("want error, but nil")
+	}
+}
+
+// test for quantity validation against graph type
+func TestValidator_ValidateQuantityType(t *testing.T) {
+	wantError := errors.New(validationErrorMessages["QuantityInt"])
+
+	tests := []struct {
+		name     string
+		numType  string
+		quantity string
+		wantErr  error
+	}{
+		{"Int graph with int", "int", "10", nil},
+		{"Int graph with negative int", "int", "-10", nil},
+		{"Int graph with float", "int", "1.5", wantError},
+		{"Float graph with float", "float", "1.5", nil},
+		{"Float graph with int", "float", "1", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validate := newValidator()
+			err := validate.ValidateQuantityType(tt.numType, tt.quantity)
+
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("want nil, but %#v", err)
+			}
+
+			if tt.wantErr != nil && (err == nil || err.Error() != tt.wantErr.Error()) {
+				t.Fatalf("want %#v, but %#v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// test for structured validation error
+func TestValidator_ValidationError(t *testing.T) {
+	pixela, err := New(username, token, debug, OptionLocale("ja"))
+
+	if err != nil {
+		t.Fatalf("got error when http client created %#v", err)
+	}
+
+	_, err = pixela.CreateGraph("0000", graphName, graphUnit, numType, "red", "", "")
+
+	var validationError *ValidationError
+
+	if !errors.As(err, &validationError) {
+		t.Fatalf("want ValidationError, but %#v", err)
+	}
+
+	want := []FieldError{
+		{Field: "graphID", Rule: "graphid", Value: "0000", Message: localizedMessages("ja", DefaultValidationRules())["GraphID"]},
+		{Field: "color", Rule: "color", Value: "red", Message: localizedMessages("ja", DefaultValidationRules())["Color"]},
+	}
+
+	if len(validationError.Errors) != len(want) {
+		t.Fatalf("want %#v, but %#v", want, validationError.Errors)
+	}
+
+	for i := range want {
+		if validationError.Errors[i] != want[i] {
+			t.Fatalf("want %#v, but %#v", want[i], validationError.Errors[i])
+		}
+	}
+}
@@ -14,6 +14,12 @@ type CreateWebhookPayload struct {
 	Type    string `json:"type"`
 }
 
+// CreateWebhookRequest is request for CreateWebhookWithRequest
+type CreateWebhookRequest struct {
+	GraphID string
+	Type    WebhookType
+}
+
 // WebhookDefinitions is `webhook get` response
 type WebhookDefinitions struct {
 	Webhooks []Webhook `json:"webhooks"`
@@ -28,10 +34,18 @@ type Webhook struct {
 
 // CreateWebhook is method for `webhook create` subcommand
 func (pixela *Pixela) CreateWebhook(graphID, webhookType string) (NoneGetResponseBody, error) {
+	return pixela.CreateWebhookWithRequest(CreateWebhookRequest{
+		GraphID: graphID,
+		Type:    WebhookType(webhookType),
+	})
+}
+
+// CreateWebhookWithRequest is method for `webhook create` subcommand
+func (pixela *Pixela) CreateWebhookWithRequest(req CreateWebhookRequest) (NoneGetResponseBody, error) {
 	// argument validation
 	vf := validateField{
-		GraphID:     graphID,
-		WebhookType: webhookType,
+		GraphID:     req.GraphID,
+		WebhookType: req.Type.String(),
 	}
 
 	err := pixela.Validator.Validate(vf)
@@ -42,8 +56,8 @@ func (pixela *Pixela) CreateWebhook(graphID, webhookType string) (NoneGetRespons
 
 	// create payload
 	pl := CreateWebhookPayload{
-		GraphID: graphID,
-		Type:    webhookType,
+		GraphID: req.GraphID,
+		Type:    req.Type.String(),
 	}
 
 	plJSON, err := json.Marshal(pl)
@@ -0,0 +1,148 @@
+package pixela
+
+import (
+	"encoding/json"
+	"net/url"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// max days of one pixels request (pixe.la returns pixels of 365 days at most)
+const pixelsWindowDays = 365
+
+// oldest date fetched by GetAllGraphPixels
+const oldestPixelDate = "19700101"
+
+// GetAllGraphPixels gets every pixel of graph (pe.la returns only pixels of last 365 days without range).
+// 365 days windows are fetched backward from tomorrow (UTC) until pixels as many as totalPixelsCount
+// of graph stats are fetched.
+func (pixela *Pixela) GetAllGraphPixels(graphID string) ([]PixelRecord, error) {
+	total, err := pixela.graphPixelsCount(graphID)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "can not get number of pixels")
+	}
+
+	oldest, _ := time.Parse(DateFormat, oldestPixelDate)
+	now := time.Now().UTC()
+	end := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	pixels := []PixelRecord{}
+
+	for len(pixels) < total && !end.Before(oldest) {
+		start := end.AddDate(0, 0, 1-pixelsWindowDays)
+
+		window, err := pixela.GetGraphPixels(GraphPixelsRequest{
+			GraphID: graphID,
+			From:    start.Format(DateFormat),
+			To:      end.Format(DateFormat),
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		pixels = append(window, pixels...)
+		end = start.AddDate(0, 0, -1)
+	}
+
+	return pixels, nil
+}
+
+// totalPixelsCount of graph stats (GraphStat can not parse stats of float graph)
+func (pixela *Pixela) graphPixelsCount(graphID string) (int, error) {
+	u, _ := url.Parse(baseURL)
+	u.Path = path.Join(u.Path, "v1", "users", pixela.Username, "graphs", graphID, "stats")
+
+	responseBody, err := pixela.get(u.String())
+
+	if err != nil {
+		return 0, err
+	}
+
+	var stats struct {
+		TotalPixelsCount int `json:"totalPixelsCount"`
+	}
+
+	if err := json.Unmarshal(responseBody, &stats); err != nil {
+		return 0, err
+	}
+
+	return stats.TotalPixelsCount, nil
+}
+
+// GetGraphPixelsInRange gets pixels from `from` to `to` (yyyyMMdd) by requests of 365 days at most
+func (pixela *Pixela) GetGraphPixelsInRange(graphID, from, to string) ([]PixelRecord, error) {
+	start, err := time.Parse(DateFormat, from)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid date `%s`", from)
+	}
+
+	end, err := time.Parse(DateFormat, to)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid date `%s`", to)
+	}
+
+	pixels := []PixelRecord{}
+
+	for !start.After(end) {
+		windowEnd := start.AddDate(0, 0, pixelsWindowDays-1)
+
+		if windowEnd.After(end) {
+			windowEnd = end
+		}
+
+		window, err := pixela.GetGraphPixels(GraphPixelsRequest{
+			GraphID: graphID,
+			From:    start.Format(DateFormat),
+			To:      windowEnd.Format(DateFormat),
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		pixels = append(pixels, window...)
+		start = windowEnd.AddDate(0, 0, 1)
+	}
+
+	return pixels, nil
+}
+
+// GetGraphPixelsOfDates gets pixels of the dates (such as existing pixels of imported pixels).
+// only 365 days windows which include the dates are requested, so sparse dates over years need few requests.
+func (pixela *Pixela) GetGraphPixelsOfDates(graphID string, dates []string) ([]PixelRecord, error) {
+	sorted := append([]string(nil), dates...)
+	sort.Strings(sorted)
+
+	pixels := []PixelRecord{}
+
+	for i := 0; i < len(sorted); {
+		start, err := time.Parse(DateFormat, sorted[i])
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid date `%s`", sorted[i])
+		}
+
+		windowEnd := start.AddDate(0, 0, pixelsWindowDays-1).Format(DateFormat)
+		last := sorted[i]
+
+		for ; i < len(sorted) && sorted[i] <= windowEnd; i++ {
+			last = sorted[i]
+		}
+
+		window, err := pixela.GetGraphPixels(GraphPixelsRequest{GraphID: graphID, From: start.Format(DateFormat), To: last})
+
+		if err != nil {
+			return nil, err
+		}
+
+		pixels = append(pixels, window...)
+	}
+
+	return pixels, nil
+}
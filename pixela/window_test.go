@@ -0,0 +1,159 @@
+package pixela
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pixelsResponder serves pixels in `from` and `to` query like pe.la (last 365 days without them), stats
+// and graph definitions (int graph of graphID). range longer than 365 days fails the test.
+func pixelsResponder(t *testing.T, pixels []PixelRecord, windows *[]string) func(req *http.Request) *http.Response {
+	return func(req *http.Request) *http.Response {
+		if strings.HasSuffix(req.URL.Path, "/graphs") {
+			return &http.Response{
+				StatusCode: sucStatus,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(fmt.Sprintf(`{"graphs":[{"id":%q,"type":"int"}]}`, graphID))),
+				Header:     make(http.Header),
+			}
+		}
+
+		if strings.HasSuffix(req.URL.Path, "/stats") {
+			return &http.Response{
+				StatusCode: sucStatus,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(fmt.Sprintf(`{"totalPixelsCount":%d}`, len(pixels)))),
+				Header:     make(http.Header),
+			}
+		}
+
+		if !strings.HasSuffix(req.URL.Path, "/pixels") {
+			t.Fatalf("unexpected request: %s", req.URL)
+		}
+
+		from, to := req.URL.Query().Get("from"), req.URL.Query().Get("to")
+
+		if to == "" {
+			to = time.Now().UTC().Format(DateFormat)
+		}
+
+		end, _ := time.Parse(DateFormat, to)
+
+		if from == "" {
+			from = end.AddDate(0, 0, 1-pixelsWindowDays).Format(DateFormat)
+		}
+
+		if start, _ := time.Parse(DateFormat, from); end.Sub(start) >= pixelsWindowDays*24*time.Hour {
+			t.Fatalf("range %s-%s is longer than 365 days", from, to)
+		}
+
+		if windows != nil {
+			*windows = append(*windows, from+"-"+to)
+		}
+
+		body := struct {
+			Pixels []PixelRecord `json:"pixels"`
+		}{[]PixelRecord{}}
+
+		for _, p := range pixels {
+			if from <= p.Date && p.Date <= to {
+				body.Pixels = append(body.Pixels, p)
+			}
+		}
+
+		data, _ := json.Marshal(body)
+
+		return &http.Response{
+			StatusCode: sucStatus,
+			Body:       ioutil.NopCloser(bytes.NewBuffer(data)),
+			Header:     make(http.Header),
+		}
+	}
+}
+
+func TestPixela_GetGraphPixelsInRange(t *testing.T) {
+	pixels := []PixelRecord{{Date: "20170105", Quantity: "1"}, {Date: "20180601", Quantity: "2"}, {Date: "20190101", Quantity: "3"}}
+	var windows []string
+
+	pixela, err := New(username, token, debug, OptionHTTPClient(NewTestClient(pixelsResponder(t, pixels, &windows))))
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	got, err := pixela.GetGraphPixelsInRange(graphID, "20170101", "20190101")
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	if !reflect.DeepEqual(got, pixels) {
+		t.Fatalf("want %v, but %v", pixels, got)
+	}
+
+	if want := []string{"20170101-20171231", "20180101-20181231", "20190101-20190101"}; !reflect.DeepEqual(windows, want) {
+		t.Fatalf("want windows %v, but %v", want, windows)
+	}
+
+	if _, err := pixela.GetGraphPixelsInRange(graphID, "2017-01-01", "20190101"); err == nil {
+		t.Fatalf("want error for invalid date")
+	}
+}
+
+func TestPixela_GetGraphPixelsOfDates(t *testing.T) {
+	pixels := []PixelRecord{{Date: "20100101", Quantity: "1"}, {Date: "20100601", Quantity: "2"}, {Date: "20190101", Quantity: "3"}}
+	var windows []string
+
+	pixela, err := New(username, token, debug, OptionHTTPClient(NewTestClient(pixelsResponder(t, pixels, &windows))))
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	got, err := pixela.GetGraphPixelsOfDates(graphID, []string{"20190101", "20100101", "20100301", "20100601"})
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	if !reflect.DeepEqual(got, pixels) {
+		t.Fatalf("want %v, but %v", pixels, got)
+	}
+
+	if want := []string{"20100101-20100601", "20190101-20190101"}; !reflect.DeepEqual(windows, want) {
+		t.Fatalf("want windows %v, but %v", want, windows)
+	}
+}
+
+func TestPixela_GetAllGraphPixels(t *testing.T) {
+	// pixels older than one year and tomorrow
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format(DateFormat)
+	pixels := []PixelRecord{{Date: "20150101", Quantity: "1"}, {Date: "20190101", Quantity: "2"}, {Date: tomorrow, Quantity: "3"}}
+	var windows []string
+
+	pixela, err := New(username, token, debug, OptionHTTPClient(NewTestClient(pixelsResponder(t, pixels, &windows))))
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	got, err := pixela.GetAllGraphPixels(graphID)
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	if !reflect.DeepEqual(got, pixels) {
+		t.Fatalf("want %v, but %v", pixels, got)
+	}
+
+	// windows are fetched until all pixels are fetched
+	if last := windows[len(windows)-1]; last[:8] > "20150101" || last[9:] < "20150101" {
+		t.Fatalf("unexpected last window %s of %v", last, windows)
+	}
+}
@@ -0,0 +1,174 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"unicode"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+// glyphs of 3x5 pixel font drawn at glyphScale (6x10 pixels in charWidth)
+const glyphScale = 2
+
+var glyphs = map[rune][5]string{
+	'A': {".#.", "#.#", "###", "#.#", "#.#"},
+	'B': {"##.", "#.#", "##.", "#.#", "##."},
+	'C': {".##", "#..", "#..", "#..", ".##"},
+	'D': {"##.", "#.#", "#.#", "#.#", "##."},
+	'E': {"###", "#..", "##.", "#..", "###"},
+	'F': {"###", "#..", "##.", "#..", "#.."},
+	'G': {".##", "#..", "#.#", "#.#", ".##"},
+	'H': {"#.#", "#.#", "###", "#.#", "#.#"},
+	'I': {"###", ".#.", ".#.", ".#.", "###"},
+	'J': {"..#", "..#", "..#", "#.#", ".#."},
+	'K': {"#.#", "#.#", "##.", "#.#", "#.#"},
+	'L': {"#..", "#..", "#..", "#..", "###"},
+	'M': {"#.#", "###", "###", "#.#", "#.#"},
+	'N': {"##.", "#.#", "#.#", "#.#", "#.#"},
+	'O': {".#.", "#.#", "#.#", "#.#", ".#."},
+	'P': {"##.", "#.#", "##.", "#..", "#.."},
+	'Q': {".#.", "#.#", "#.#", "##.", ".##"},
+	'R': {"##.", "#.#", "##.", "#.#", "#.#"},
+	'S': {".##", "#..", ".#.", "..#", "##."},
+	'T': {"###", ".#.", ".#.", ".#.", ".#."},
+	'U': {"#.#", "#.#", "#.#", "#.#", "###"},
+	'V': {"#.#", "#.#", "#.#", "#.#", ".#."},
+	'W': {"#.#", "#.#", "###", "###", "#.#"},
+	'X': {"#.#", "#.#", ".#.", "#.#", "#.#"},
+	'Y': {"#.#", "#.#", ".#.", ".#.", ".#."},
+	'Z': {"###", "..#", ".#.", "#..", "###"},
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"##.", "..#", ".#.", "#..", "###"},
+	'3': {"##.", "..#", ".#.", "..#", "##."},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "##.", "..#", "##."},
+	'6': {".##", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", ".#.", ".#.", ".#."},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "##."},
+	' ': {"...", "...", "...", "...", "..."},
+	'.': {"...", "...", "...", "...", ".#."},
+	',': {"...", "...", "...", ".#.", "#.."},
+	'-': {"...", "...", "###", "...", "..."},
+	'+': {"...", ".#.", "###", ".#.", "..."},
+	':': {"...", ".#.", "...", ".#.", "..."},
+	'/': {"..#", "..#", ".#.", "#..", "#.."},
+	'(': {".#.", "#..", "#..", "#..", ".#."},
+	')': {".#.", "..#", "..#", "..#", ".#."},
+	'%': {"#.#", "..#", ".#.", "#..", "#.#"},
+	'_': {"...", "...", "...", "...", "###"},
+	'?': {"##.", "..#", ".#.", "...", ".#."},
+}
+
+func writePNG(w io.Writer, d *drawing) error {
+	img := image.NewRGBA(image.Rect(0, 0, d.width, d.height))
+	fillRect(img, 0, 0, d.width, d.height, d.background)
+
+	for _, e := range d.elements {
+		switch e.kind {
+		case rectElement:
+			fillRect(img, e.x, e.y, e.w, e.h, e.color)
+		case textElement:
+			drawText(img, e)
+		case lineElement:
+			for i := 1; i < len(e.points); i++ {
+				strokeLine(img, e.points[i-1], e.points[i], e.color)
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+func rgba(c pixela.RGB) color.RGBA {
+	return color.RGBA{R: c.R, G: c.G, B: c.B, A: 0xff}
+}
+
+func fillRect(img *image.RGBA, x, y, w, h int, c pixela.RGB) {
+	for py := y; py < y+h; py++ {
+		for px := x; px < x+w; px++ {
+			img.SetRGBA(px, py, rgba(c))
+		}
+	}
+}
+
+// text by 3x5 pixel font. lower case is drawn as upper case and unknown character as `?`.
+func drawText(img *image.RGBA, e element) {
+	x := e.x
+
+	switch e.anchor {
+	case anchorMiddle:
+		x -= textWidth(e.text) / 2
+	case anchorEnd:
+		x -= textWidth(e.text)
+	}
+
+	for _, r := range e.text {
+		glyph, ok := glyphs[unicode.ToUpper(r)]
+
+		if !ok {
+			glyph = glyphs['?']
+		}
+
+		for gy, row := range glyph {
+			for gx, dot := range row {
+				if dot == '#' {
+					fillRect(img, x+gx*glyphScale+1, e.y+gy*glyphScale, glyphScale, glyphScale, e.color)
+				}
+			}
+		}
+
+		x += charWidth
+	}
+}
+
+// line of 2 pixels width (Bresenham)
+func strokeLine(img *image.RGBA, from, to point, c pixela.RGB) {
+	dx, dy := abs(to.x-from.x), -abs(to.y-from.y)
+	sx, sy := sign(to.x-from.x), sign(to.y-from.y)
+	err := dx + dy
+	x, y := from.x, from.y
+
+	for {
+		fillRect(img, x, y-1, 2, 2, c)
+
+		if x == to.x && y == to.y {
+			return
+		}
+
+		e2 := 2 * err
+
+		if e2 >= dy {
+			err += dy
+			x += sx
+		}
+
+		if e2 <= dx {
+			err += dx
+			y += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
+
+func sign(v int) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,461 @@
+// Package render renders Pixela style graph images (SVG and PNG) from pixels without pixe.la.
+package render
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+// Mode is display mode of graph image (same as `mode` of pixe.la graph SVG)
+type Mode string
+
+// display modes
+const (
+	ModeFull  Mode = "full"
+	ModeShort Mode = "short"
+	ModeBadge Mode = "badge"
+	ModeLine  Mode = "line"
+)
+
+// Modes returns all display modes
+func Modes() []Mode {
+	return []Mode{ModeFull, ModeShort, ModeBadge, ModeLine}
+}
+
+func (m Mode) String() string {
+	return string(m)
+}
+
+// ParseMode converts string into Mode (empty string is full)
+func ParseMode(s string) (Mode, error) {
+	if s == "" {
+		return ModeFull, nil
+	}
+
+	for _, m := range Modes() {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+
+	return "", pixela.EnumParseError("mode", s, Modes())
+}
+
+// Appearance is light or dark appearance (same as `appearance` of pixe.la graph SVG)
+type Appearance string
+
+// appearances
+const (
+	AppearanceLight Appearance = "light"
+	AppearanceDark  Appearance = "dark"
+)
+
+// Appearances returns all appearances
+func Appearances() []Appearance {
+	return []Appearance{AppearanceLight, AppearanceDark}
+}
+
+func (a Appearance) String() string {
+	return string(a)
+}
+
+// ParseAppearance converts string into Appearance (empty string is light)
+func ParseAppearance(s string) (Appearance, error) {
+	if s == "" {
+		return AppearanceLight, nil
+	}
+
+	for _, a := range Appearances() {
+		if string(a) == s {
+			return a, nil
+		}
+	}
+
+	return "", pixela.EnumParseError("appearance", s, Appearances())
+}
+
+// Options is options of rendering
+type Options struct {
+	Mode       Mode
+	Appearance Appearance
+	// Theme overrides graph color
+	Theme pixela.Color
+	// Date is last day of graph (today in graph timezone when it is zero)
+	Date time.Time
+}
+
+// weeks and days of each mode
+const (
+	fullWeeks  = 53
+	shortWeeks = 13
+	lineDays   = 90
+)
+
+// Theme is colors of graph image
+type Theme struct {
+	Background pixela.RGB
+	Text       pixela.RGB
+	// Levels are colors of heatmap levels (index 0 is empty pixel)
+	Levels []pixela.RGB
+	// Label is background of badge label
+	Label pixela.RGB
+}
+
+// NewTheme returns theme of graph color and appearance.
+// kuro is inverted (white) in dark appearance.
+func NewTheme(color pixela.Color, appearance Appearance) Theme {
+	if appearance == AppearanceDark {
+		base := pixela.BaseColor(color)
+
+		if color == pixela.ColorKuro {
+			base = pixela.RGB{R: 0xf0, G: 0xf0, B: 0xf0}
+		}
+
+		return Theme{
+			Background: pixela.RGB{R: 0x0d, G: 0x11, B: 0x17},
+			Text:       pixela.RGB{R: 0x8b, G: 0x94, B: 0x9e},
+			Levels:     pixela.PaletteFrom(base, pixela.RGB{R: 0x21, G: 0x26, B: 0x2d}),
+			Label:      pixela.RGB{R: 0x30, G: 0x36, B: 0x3d},
+		}
+	}
+
+	return Theme{
+		Background: pixela.RGB{R: 0xff, G: 0xff, B: 0xff},
+		Text:       pixela.RGB{R: 0x76, G: 0x76, B: 0x76},
+		Levels:     pixela.Palette(color),
+		Label:      pixela.RGB{R: 0x55, G: 0x55, B: 0x55},
+	}
+}
+
+// SVG writes graph image as SVG
+func SVG(w io.Writer, graph pixela.Graph, pixels []pixela.PixelRecord, opts Options) error {
+	d, err := draw(graph, pixels, opts)
+
+	if err != nil {
+		return err
+	}
+
+	return writeSVG(w, d)
+}
+
+// PNG writes graph image as PNG
+func PNG(w io.Writer, graph pixela.Graph, pixels []pixela.PixelRecord, opts Options) error {
+	d, err := draw(graph, pixels, opts)
+
+	if err != nil {
+		return err
+	}
+
+	return writePNG(w, d)
+}
+
+// drawing is list of elements which is written as SVG or PNG
+type drawing struct {
+	width, height int
+	background    pixela.RGB
+	elements      []element
+}
+
+type elementKind int
+
+const (
+	rectElement elementKind = iota
+	textElement
+	lineElement
+)
+
+type textAnchor string
+
+const (
+	anchorStart  textAnchor = "start"
+	anchorMiddle textAnchor = "middle"
+	anchorEnd    textAnchor = "end"
+)
+
+// element is rectangle, text (x, y is top of text) or polyline
+type element struct {
+	kind       elementKind
+	x, y, w, h int
+	color      pixela.RGB
+	text       string
+	anchor     textAnchor
+	points     []point
+	// title is tooltip of rectangle (SVG only)
+	title string
+}
+
+type point struct {
+	x, y int
+}
+
+// size of text (height and advance of one character)
+const (
+	textHeight = 10
+	charWidth  = 8
+)
+
+func textWidth(s string) int {
+	return charWidth * len([]rune(s))
+}
+
+func (d *drawing) rect(x, y, w, h int, c pixela.RGB, title string) {
+	d.elements = append(d.elements, element{kind: rectElement, x: x, y: y, w: w, h: h, color: c, title: title})
+}
+
+func (d *drawing) text(x, y int, s string, c pixela.RGB, anchor textAnchor) {
+	d.elements = append(d.elements, element{kind: textElement, x: x, y: y, text: s, color: c, anchor: anchor})
+}
+
+func (d *drawing) polyline(points []point, c pixela.RGB) {
+	d.elements = append(d.elements, element{kind: lineElement, points: points, color: c})
+}
+
+func draw(graph pixela.Graph, pixels []pixela.PixelRecord, opts Options) (*drawing, error) {
+	mode, err := ParseMode(string(opts.Mode))
+
+	if err != nil {
+		return nil, err
+	}
+
+	color := opts.Theme
+
+	if color == "" {
+		color = pixela.Color(graph.Color)
+	}
+
+	theme := NewTheme(color, opts.Appearance)
+	end := opts.Date
+
+	if end.IsZero() {
+		end = today(graph.Timezone)
+	}
+
+	switch mode {
+	case ModeShort:
+		return drawHeatmap(graph, pixels, end, shortWeeks, theme)
+	case ModeBadge:
+		return drawBadge(graph, pixels, end, theme)
+	case ModeLine:
+		return drawLine(graph, pixels, end, theme)
+	}
+
+	return drawHeatmap(graph, pixels, end, fullWeeks, theme)
+}
+
+func today(timezone string) time.Time {
+	now := time.Now()
+
+	if canonical, err := pixela.NormalizeTimezone(timezone); err == nil && timezone != "" {
+		if loc, err := time.LoadLocation(canonical); err == nil {
+			now = now.In(loc)
+		}
+	}
+
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// heatmap geometry (cell size and distance of cells)
+const (
+	cellSize   = 10
+	cellStep   = 12
+	gridLeft   = 32
+	gridTop    = 16
+	gridMargin = 8
+)
+
+// contribution grid with month and weekday labels, total and legend
+func drawHeatmap(graph pixela.Graph, pixels []pixela.PixelRecord, end time.Time, weeks int, theme Theme) (*drawing, error) {
+	heatmap, err := pixela.NewHeatmap(pixels, end, weeks)
+
+	if err != nil {
+		return nil, err
+	}
+
+	legendTop := gridTop + 7*cellStep + 4
+	d := &drawing{
+		width:      gridLeft + cellStep*len(heatmap.Weeks) + gridMargin,
+		height:     legendTop + textHeight + gridMargin,
+		background: theme.Background,
+	}
+
+	// month labels at week which contains first day of month (and first week when it has room)
+	next := 0
+
+	for w, week := range heatmap.Weeks {
+		label := ""
+
+		for _, day := range week {
+			if day.InRange && day.Date.Day() == 1 {
+				label = day.Date.Format("Jan")
+			}
+		}
+
+		if w == 0 && label == "" && week[0].Date.AddDate(0, 0, 14).Month() == week[0].Date.Month() {
+			label = week[0].Date.Format("Jan")
+		}
+
+		if x := gridLeft + cellStep*w; label != "" && x >= next {
+			d.text(x, 2, label, theme.Text, anchorStart)
+			next = x + textWidth(label) + charWidth
+		}
+	}
+
+	for _, weekday := range []time.Weekday{time.Monday, time.Wednesday, time.Friday} {
+		d.text(0, gridTop+cellStep*int(weekday), weekday.String()[:3], theme.Text, anchorStart)
+	}
+
+	for w, week := range heatmap.Weeks {
+		for i, day := range week {
+			if !day.InRange {
+				continue
+			}
+
+			title := day.Date.Format("2006-01-02")
+
+			if day.HasPixel {
+				title += " " + strings.TrimSpace(formatQuantity(day.Quantity)+" "+graph.Unit)
+			}
+
+			d.rect(gridLeft+cellStep*w, gridTop+cellStep*i, cellSize, cellSize, theme.Levels[day.Level], title)
+		}
+	}
+
+	// total at left and legend at right
+	d.text(gridLeft, legendTop, strings.TrimSpace("total "+formatQuantity(heatmap.Total)+" "+graph.Unit), theme.Text, anchorStart)
+
+	right := d.width - gridMargin
+	d.text(right, legendTop, "More", theme.Text, anchorEnd)
+	right -= textWidth("More") + 4
+
+	for level := pixela.HeatmapLevels; level >= 0; level-- {
+		right -= cellStep
+		d.rect(right, legendTop, cellSize, cellSize, theme.Levels[level], "")
+	}
+
+	d.text(right-4, legendTop, "Less", theme.Text, anchorEnd)
+
+	return d, nil
+}
+
+// badge height and padding of label and value
+const (
+	badgeHeight  = 20
+	badgePadding = 6
+)
+
+// badge of graph name and quantity of the date
+func drawBadge(graph pixela.Graph, pixels []pixela.PixelRecord, end time.Time, theme Theme) (*drawing, error) {
+	label := graph.Name
+
+	if label == "" {
+		label = graph.ID
+	}
+
+	value := "-"
+	date := end.Format(pixela.DateFormat)
+
+	for _, p := range pixels {
+		if p.Date == date {
+			if _, err := strconv.ParseFloat(p.Quantity, 64); err != nil {
+				return nil, fmt.Errorf("quantity `%s` of %s is not a number", p.Quantity, p.Date)
+			}
+
+			value = strings.TrimSpace(p.Quantity + " " + graph.Unit)
+		}
+	}
+
+	labelWidth := textWidth(label) + 2*badgePadding
+	valueWidth := textWidth(value) + 2*badgePadding
+	white := pixela.RGB{R: 0xff, G: 0xff, B: 0xff}
+	textTop := (badgeHeight - textHeight) / 2
+
+	d := &drawing{width: labelWidth + valueWidth, height: badgeHeight, background: theme.Background}
+	d.rect(0, 0, labelWidth, badgeHeight, theme.Label, "")
+	d.rect(labelWidth, 0, valueWidth, badgeHeight, theme.Levels[pixela.HeatmapLevels], end.Format("2006-01-02"))
+	d.text(labelWidth/2, textTop, label, white, anchorMiddle)
+	d.text(labelWidth+valueWidth/2, textTop, value, white, anchorMiddle)
+
+	return d, nil
+}
+
+// line chart geometry (1 day is dayStep pixels)
+const (
+	lineLeft   = 48
+	lineTop    = 20
+	lineHeight = 80
+	dayStep    = 3
+)
+
+// line chart of quantities of last days (day without pixel is 0)
+func drawLine(graph pixela.Graph, pixels []pixela.PixelRecord, end time.Time, theme Theme) (*drawing, error) {
+	quantities := map[string]float64{}
+
+	for _, p := range pixels {
+		q, err := strconv.ParseFloat(p.Quantity, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("quantity `%s` of %s is not a number", p.Quantity, p.Date)
+		}
+
+		quantities[p.Date] = q
+	}
+
+	start := end.AddDate(0, 0, 1-lineDays)
+	values := make([]float64, lineDays)
+	min, max := 0.0, 0.0
+
+	for i := range values {
+		values[i] = quantities[start.AddDate(0, 0, i).Format(pixela.DateFormat)]
+		min = math.Min(min, values[i])
+		max = math.Max(max, values[i])
+	}
+
+	if max == min {
+		max = min + 1
+	}
+
+	plotWidth := dayStep * (lineDays - 1)
+	bottom := lineTop + lineHeight
+	d := &drawing{
+		width:      lineLeft + plotWidth + gridMargin,
+		height:     bottom + 4 + textHeight + gridMargin,
+		background: theme.Background,
+	}
+
+	title := graph.Name
+
+	if title == "" {
+		title = graph.ID
+	}
+
+	d.text(lineLeft, 2, title, theme.Text, anchorStart)
+
+	// axes and labels
+	d.rect(lineLeft-1, lineTop, 1, lineHeight+1, theme.Levels[0], "")
+	d.rect(lineLeft-1, bottom, plotWidth+1, 1, theme.Levels[0], "")
+	d.text(lineLeft-4, lineTop, formatQuantity(max), theme.Text, anchorEnd)
+	d.text(lineLeft-4, bottom-textHeight, formatQuantity(min), theme.Text, anchorEnd)
+	d.text(lineLeft, bottom+4, start.Format("01-02"), theme.Text, anchorStart)
+	d.text(lineLeft+plotWidth, bottom+4, end.Format("01-02"), theme.Text, anchorEnd)
+
+	points := make([]point, 0, len(values))
+
+	for i, v := range values {
+		y := bottom - int(math.Round((v-min)/(max-min)*lineHeight))
+		points = append(points, point{lineLeft + dayStep*i, y})
+	}
+
+	d.polyline(points, theme.Levels[pixela.HeatmapLevels])
+
+	return d, nil
+}
+
+func formatQuantity(q float64) string {
+	return strconv.FormatFloat(q, 'f', -1, 64)
+}
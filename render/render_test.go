@@ -0,0 +1,153 @@
+package render
+
+import (
+	"bytes"
+	"image/png"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noissefnoc/pixela-client-go/pixela"
+)
+
+var (
+	testGraph  = pixela.Graph{ID: "reading", Name: "reading", Unit: "pages", Type: "int", Color: "sora"}
+	testPixels = []pixela.PixelRecord{
+		{Date: "20190101", Quantity: "4"},
+		{Date: "20190104", Quantity: "1"},
+	}
+	testDate = time.Date(2019, 1, 4, 0, 0, 0, 0, time.UTC)
+)
+
+func TestSVG(t *testing.T) {
+	sora := pixela.Palette(pixela.ColorSora)
+	dark := NewTheme(pixela.ColorSora, AppearanceDark)
+
+	tests := []struct {
+		name  string
+		opts  Options
+		want  []string
+		width int
+	}{
+		{
+			"full",
+			Options{Date: testDate},
+			[]string{
+				`<rect x="656" y="40" width="10" height="10" fill="` + sora[4].Hex() + `"><title>2019-01-01 4 pages</title></rect>`,
+				`<rect x="656" y="76" width="10" height="10" fill="` + sora[1].Hex() + `"><title>2019-01-04 1 pages</title></rect>`,
+				`<rect x="656" y="28" width="10" height="10" fill="` + sora[0].Hex() + `"><title>2018-12-31</title></rect>`,
+				`>Jan</text>`,
+				`>total 5 pages</text>`,
+			},
+			32 + 12*53 + 8,
+		},
+		{
+			"short",
+			Options{Mode: ModeShort, Date: testDate},
+			[]string{`>Jan</text>`, `<title>2019-01-01 4 pages</title>`},
+			32 + 12*13 + 8,
+		},
+		{
+			"badge",
+			Options{Mode: ModeBadge, Date: testDate},
+			[]string{`>reading</text>`, `>1 pages</text>`, `fill="` + sora[4].Hex() + `"`},
+			0,
+		},
+		{
+			"line",
+			Options{Mode: ModeLine, Date: testDate},
+			[]string{`<polyline points="48,100 `, ` 306,20 309,100 312,100 315,80"`, `>4</text>`, `>10-07</text>`, `>01-04</text>`},
+			48 + 3*89 + 8,
+		},
+		{
+			"dark theme",
+			Options{Appearance: AppearanceDark, Theme: pixela.ColorMomiji, Date: testDate},
+			[]string{`fill="` + dark.Background.Hex() + `"`, `fill="` + NewTheme(pixela.ColorMomiji, AppearanceDark).Levels[4].Hex() + `"><title>2019-01-01`},
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+
+			if err := SVG(buf, testGraph, testPixels, tt.opts); err != nil {
+				t.Fatalf("want nil, but %#v", err)
+			}
+
+			got := buf.String()
+
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Fatalf("want %s in\n%s", want, got)
+				}
+			}
+
+			if tt.width != 0 && !strings.HasPrefix(got, `<svg xmlns="http://www.w3.org/2000/svg" width="`+strconv.Itoa(tt.width)+`"`) {
+				t.Fatalf("want width %d, but %s", tt.width, strings.SplitN(got, "\n", 2)[0])
+			}
+		})
+	}
+}
+
+func TestSVGError(t *testing.T) {
+	if err := SVG(&bytes.Buffer{}, testGraph, testPixels, Options{Mode: "large"}); err == nil {
+		t.Fatalf("want error, but nil")
+	}
+
+	if err := SVG(&bytes.Buffer{}, testGraph, []pixela.PixelRecord{{Date: "20190101", Quantity: "x"}}, Options{Date: testDate}); err == nil {
+		t.Fatalf("want error, but nil")
+	}
+}
+
+func TestPNG(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	if err := PNG(buf, testGraph, testPixels, Options{Date: testDate}); err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	img, err := png.Decode(buf)
+
+	if err != nil {
+		t.Fatalf("want nil, but %#v", err)
+	}
+
+	if size := img.Bounds().Size(); size.X != 32+12*53+8 || size.Y != 16+7*12+4+10+8 {
+		t.Fatalf("unexpected size: %v", size)
+	}
+
+	want := pixela.Palette(pixela.ColorSora)
+
+	for _, tt := range []struct {
+		x, y  int
+		level int
+	}{
+		{660, 44, 4},
+		{660, 80, 1},
+		{660, 32, 0},
+	} {
+		r, g, b, _ := img.At(tt.x, tt.y).RGBA()
+
+		if got := (pixela.RGB{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8)}); got != want[tt.level] {
+			t.Fatalf("(%d, %d): want %s, but %s", tt.x, tt.y, want[tt.level].Hex(), got.Hex())
+		}
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for _, s := range []string{"", "full", "short", "badge", "line"} {
+		if _, err := ParseMode(s); err != nil {
+			t.Fatalf("want nil, but %#v", err)
+		}
+	}
+
+	if _, err := ParseMode("large"); err == nil || err.Error() != "invalid mode `large`: allows `full`, `short`, `badge`, `line`" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := ParseAppearance("sepia"); err == nil {
+		t.Fatalf("want error, but nil")
+	}
+}
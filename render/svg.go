@@ -0,0 +1,51 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// font size of SVG text (text baseline is below top of text)
+const (
+	svgFontSize = 10
+	svgBaseline = 9
+)
+
+func writeSVG(w io.Writer, d *drawing) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", d.width, d.height, d.width, d.height)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="%s"/>`+"\n", d.width, d.height, d.background.Hex())
+	fmt.Fprintf(bw, `<g font-family="sans-serif" font-size="%d">`+"\n", svgFontSize)
+
+	for _, e := range d.elements {
+		switch e.kind {
+		case rectElement:
+			if e.title == "" {
+				fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n", e.x, e.y, e.w, e.h, e.color.Hex())
+			} else {
+				fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"><title>%s</title></rect>`+"\n",
+					e.x, e.y, e.w, e.h, e.color.Hex(), html.EscapeString(e.title))
+			}
+		case textElement:
+			fmt.Fprintf(bw, `<text x="%d" y="%d" fill="%s" text-anchor="%s">%s</text>`+"\n",
+				e.x, e.y+svgBaseline, e.color.Hex(), e.anchor, html.EscapeString(e.text))
+		case lineElement:
+			points := make([]string, 0, len(e.points))
+
+			for _, p := range e.points {
+				points = append(points, fmt.Sprintf("%d,%d", p.x, p.y))
+			}
+
+			fmt.Fprintf(bw, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`+"\n", strings.Join(points, " "), e.color.Hex())
+		}
+	}
+
+	fmt.Fprintln(bw, "</g>")
+	fmt.Fprintln(bw, "</svg>")
+
+	return bw.Flush()
+}